@@ -15,6 +15,176 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/export/{kind}": {
+            "get": {
+                "description": "Stream readings, fish observations, sensors metadata or anomaly labels as a file. Records can be filtered by group, sensor, region and time range.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.apache.parquet"
+                ],
+                "summary": "Export records",
+                "parameters": [
+                    {
+                        "enum": [
+                            "readings",
+                            "fish",
+                            "sensors",
+                            "labels"
+                        ],
+                        "type": "string",
+                        "description": "Records kind",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "parquet"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "File format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Group name",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sensor code name or UUID",
+                        "name": "sensor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "From (RFC3339, like 2023-11-01T00:00:00Z)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Till (RFC3339, like 2023-11-01T00:00:00Z)",
+                        "name": "till",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "xMin",
+                        "name": "xMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "xMax",
+                        "name": "xMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "yMin",
+                        "name": "yMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "yMax",
+                        "name": "yMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "zMin",
+                        "name": "zMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "zMax",
+                        "name": "zMax",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/generator/lag": {
+            "get": {
+                "description": "Get how late the updates of the sensors generated by this instance start compared to their schedule. Lag is the delay of the latest update, skipped is the number of updates dropped while the generator was behind.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get update lag of sensors",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.GeneratorLag"
+                        }
+                    },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/generator/status": {
+            "get": {
+                "description": "Get the alive generator workers and the groups each of them generates data for. Groups are assigned to workers by consistent hashing of their names, groups are unassigned while no worker is alive.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get generator workers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.GeneratorStatus"
+                        }
+                    },
+                    "500": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/group": {
             "get": {
                 "description": "Get groups list",
@@ -44,6 +214,134 @@ const docTemplate = `{
                 }
             }
         },
+        "/group/{groupName}/sensor": {
+            "get": {
+                "description": "Get the sensors of the group with their positions and current values, optionally in a region. The sensors are sorted by the given field and paged.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get sensors list of the group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "xMin",
+                        "name": "xMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "xMax",
+                        "name": "xMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "yMin",
+                        "name": "yMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "yMax",
+                        "name": "yMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "zMin",
+                        "name": "zMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "zMax",
+                        "name": "zMax",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "code_name",
+                            "x",
+                            "y",
+                            "z",
+                            "data_output_rate",
+                            "created_at",
+                            "last_reading",
+                            "battery"
+                        ],
+                        "type": "string",
+                        "default": "code_name",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size, at most 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of skipped sensors",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.SensorList"
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/group/{groupName}/species": {
             "get": {
                 "description": "Get full list of species (with counts) currently detected inside the group.",
@@ -106,13 +404,13 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "From (UNIX timestamps)",
+                        "description": "From (RFC3339, like 2023-11-01T00:00:00Z)",
                         "name": "from",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Till (UNIX timestamps)",
+                        "description": "Till (RFC3339, like 2023-11-01T00:00:00Z)",
                         "name": "till",
                         "in": "query"
                     }
@@ -215,70 +513,214 @@ const docTemplate = `{
                 }
             }
         },
-        "/region/temperature/max": {
+        "/health/sensors": {
             "get": {
-                "description": "Get current maximum temperature inside the region. Region here and below is an area represented by the range of coordinates",
+                "description": "Get the number of online, late and offline sensors in total and per group. A sensor is late once it misses a report expected by its data output rate and offline after several missed reports.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get current maximum temperature inside the region",
-                "parameters": [
-                    {
-                        "type": "number",
-                        "format": "float",
-                        "description": "minX",
-                        "name": "minX",
-                        "in": "path"
-                    },
-                    {
-                        "type": "number",
-                        "format": "float",
-                        "description": "maxX",
-                        "name": "maxX",
-                        "in": "path"
-                    },
-                    {
-                        "type": "number",
-                        "format": "float",
-                        "description": "minY",
-                        "name": "minY",
-                        "in": "path"
-                    },
-                    {
-                        "type": "number",
-                        "format": "float",
-                        "description": "maxY",
-                        "name": "maxY",
-                        "in": "path"
-                    },
-                    {
-                        "type": "number",
-                        "format": "float",
-                        "description": "minZ",
-                        "name": "minZ",
-                        "in": "path"
-                    },
-                    {
-                        "type": "number",
-                        "format": "float",
-                        "description": "maxZ",
-                        "name": "maxZ",
-                        "in": "path"
-                    }
-                ],
+                "summary": "Get sensors health",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/routes.Value"
+                            "$ref": "#/definitions/routes.SensorsHealth"
                         }
                     },
-                    "400": {
+                    "500": {
                         "description": "error message",
                         "schema": {
                             "$ref": "#/definitions/routes.ErrorResponse"
                         }
-                    },
+                    }
+                }
+            }
+        },
+        "/ingest": {
+            "post": {
+                "description": "Save a batch of readings measured by existing sensors. Sensors are identified by id or code name. The batch is accepted as JSON or as InfluxDB line protocol (measurements \"sensor\" with temperature and transparency fields and \"fish\" with species tag and count field). Readings repeating a saved (sensor, timestamp) pair are skipped.",
+                "consumes": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Ingest external readings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.IngestResult"
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "batch is larger than 10 MiB",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/labels": {
+            "get": {
+                "description": "Get the ground truth of the anomalies the generator produced (spike, drift, dropout, scenario_event, population_shock, hardware_failure, battery_depleted) which overlap the specified date/time pairs (RFC3339), optionally of a sensor and of a kind. An empty metric means every metric of the sensor is affected.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get anomaly labels",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "From (RFC3339, like 2023-11-01T00:00:00Z)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Till (RFC3339, like 2023-11-01T00:00:00Z)",
+                        "name": "till",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sensor code name or UUID",
+                        "name": "sensor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "spike",
+                            "drift",
+                            "dropout",
+                            "scenario_event",
+                            "population_shock",
+                            "hardware_failure",
+                            "battery_depleted"
+                        ],
+                        "type": "string",
+                        "description": "Anomaly kind",
+                        "name": "kind",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.AnomalyLabels"
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/region/temperature/max": {
+            "get": {
+                "description": "Get current maximum temperature inside the region. Region here and below is an area represented by the range of coordinates",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get current maximum temperature inside the region",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "minX",
+                        "name": "minX",
+                        "in": "path"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "maxX",
+                        "name": "maxX",
+                        "in": "path"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "minY",
+                        "name": "minY",
+                        "in": "path"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "maxY",
+                        "name": "maxY",
+                        "in": "path"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "minZ",
+                        "name": "minZ",
+                        "in": "path"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "maxZ",
+                        "name": "maxZ",
+                        "in": "path"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.Value"
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "error message",
                         "schema": {
@@ -325,25 +767,240 @@ const docTemplate = `{
                         "in": "path"
                     },
                     {
-                        "type": "number",
-                        "format": "float",
-                        "description": "minZ",
-                        "name": "minZ",
-                        "in": "path"
+                        "type": "number",
+                        "format": "float",
+                        "description": "minZ",
+                        "name": "minZ",
+                        "in": "path"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "maxZ",
+                        "name": "maxZ",
+                        "in": "path"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.Value"
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sensor": {
+            "get": {
+                "description": "Get the sensors with their positions, health statuses, batteries and current values, optionally of a group and in a region. The sensors are sorted by the given field and paged.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get sensors list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "xMin",
+                        "name": "xMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "xMax",
+                        "name": "xMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "yMin",
+                        "name": "yMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "yMax",
+                        "name": "yMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "zMin",
+                        "name": "zMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float",
+                        "description": "zMax",
+                        "name": "zMax",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "code_name",
+                            "x",
+                            "y",
+                            "z",
+                            "data_output_rate",
+                            "created_at",
+                            "last_reading",
+                            "battery"
+                        ],
+                        "type": "string",
+                        "default": "code_name",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size, at most 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of skipped sensors",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.SensorList"
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sensor/{codeName}": {
+            "get": {
+                "description": "Get the identity, position, data output rate, calibration, hardware and health status and current values of a sensor given by its code name (like alpha3) or UUID",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get sensor metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sensor code name or UUID",
+                        "name": "codeName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/routes.Sensor"
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sensor/{codeName}/events": {
+            "get": {
+                "description": "Get the health status transitions (online, late, offline) of a sensor between the specified date/time pairs (RFC3339)",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get sensor status changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sensor code name or UUID",
+                        "name": "codeName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "From (RFC3339, like 2023-11-01T00:00:00Z)",
+                        "name": "from",
+                        "in": "query"
                     },
                     {
-                        "type": "number",
-                        "format": "float",
-                        "description": "maxZ",
-                        "name": "maxZ",
-                        "in": "path"
+                        "type": "string",
+                        "description": "Till (RFC3339, like 2023-11-01T00:00:00Z)",
+                        "name": "till",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/routes.Value"
+                            "$ref": "#/definitions/routes.SensorEvents"
                         }
                     },
                     "400": {
@@ -352,6 +1009,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/routes.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "error message",
                         "schema": {
@@ -363,7 +1026,7 @@ const docTemplate = `{
         },
         "/sensor/{codeName}/temperature/average": {
             "get": {
-                "description": "Get average temperature detected by a particular sensor between the specified date/time pairs (UNIX timestamps)",
+                "description": "Get average temperature detected by a particular sensor between the specified date/time pairs (RFC3339)",
                 "produces": [
                     "application/json"
                 ],
@@ -371,20 +1034,20 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "sensor code name",
+                        "description": "sensor code name or UUID",
                         "name": "codeName",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "From (UNIX timestamps)",
+                        "description": "From (RFC3339, like 2023-11-01T00:00:00Z)",
                         "name": "from",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Till (UNIX timestamps)",
+                        "description": "Till (RFC3339, like 2023-11-01T00:00:00Z)",
                         "name": "till",
                         "in": "query"
                     }
@@ -402,6 +1065,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/routes.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "$ref": "#/definitions/routes.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "error message",
                         "schema": {
@@ -413,6 +1082,46 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "routes.AnomalyLabel": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "ended_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "magnitude": {
+                    "type": "string"
+                },
+                "metric": {
+                    "type": "string"
+                },
+                "sensor": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "routes.AnomalyLabels": {
+            "type": "object",
+            "properties": {
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.AnomalyLabel"
+                    }
+                }
+            }
+        },
         "routes.Average": {
             "type": "object",
             "properties": {
@@ -429,6 +1138,60 @@ const docTemplate = `{
                 }
             }
         },
+        "routes.GeneratorLag": {
+            "type": "object",
+            "properties": {
+                "average_lag": {
+                    "type": "string"
+                },
+                "max_lag": {
+                    "type": "string"
+                },
+                "sensors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.SensorLag"
+                    }
+                },
+                "skipped": {
+                    "type": "string"
+                }
+            }
+        },
+        "routes.GeneratorStatus": {
+            "type": "object",
+            "properties": {
+                "unassigned": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "workers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.WorkerStatus"
+                    }
+                }
+            }
+        },
+        "routes.GroupHealth": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "late": {
+                    "type": "string"
+                },
+                "offline": {
+                    "type": "string"
+                },
+                "online": {
+                    "type": "string"
+                }
+            }
+        },
         "routes.Groups": {
             "type": "object",
             "properties": {
@@ -440,6 +1203,213 @@ const docTemplate = `{
                 }
             }
         },
+        "routes.IngestResult": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "integer"
+                },
+                "duplicates": {
+                    "type": "integer"
+                },
+                "rejected": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.RejectedReading"
+                    }
+                }
+            }
+        },
+        "routes.RejectedReading": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                }
+            }
+        },
+        "routes.Sensor": {
+            "type": "object",
+            "properties": {
+                "battery": {
+                    "type": "string"
+                },
+                "calibration": {
+                    "$ref": "#/definitions/routes.SensorCalibration"
+                },
+                "code_name": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "data_output_rate": {
+                    "type": "string"
+                },
+                "failed_at": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "hardware_status": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "string"
+                },
+                "last_reading": {
+                    "type": "string"
+                },
+                "maintained_at": {
+                    "type": "string"
+                },
+                "missed_reports": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "temperature": {
+                    "type": "string"
+                },
+                "transparency": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                },
+                "z": {
+                    "type": "string"
+                }
+            }
+        },
+        "routes.SensorCalibration": {
+            "type": "object",
+            "properties": {
+                "gain": {
+                    "type": "string"
+                },
+                "noise_std": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "string"
+                },
+                "resolution": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "routes.SensorEvent": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "last_seen": {
+                    "type": "string"
+                },
+                "missed_reports": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "routes.SensorEvents": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.SensorEvent"
+                    }
+                }
+            }
+        },
+        "routes.SensorLag": {
+            "type": "object",
+            "properties": {
+                "lag": {
+                    "type": "string"
+                },
+                "max_lag": {
+                    "type": "string"
+                },
+                "next_update": {
+                    "type": "string"
+                },
+                "sensor_id": {
+                    "type": "string"
+                },
+                "skipped": {
+                    "type": "string"
+                },
+                "updates": {
+                    "type": "string"
+                }
+            }
+        },
+        "routes.SensorList": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "string"
+                },
+                "sensors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.Sensor"
+                    }
+                },
+                "total": {
+                    "type": "string"
+                }
+            }
+        },
+        "routes.SensorsHealth": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/routes.GroupHealth"
+                    }
+                },
+                "late": {
+                    "type": "string"
+                },
+                "offline": {
+                    "type": "string"
+                },
+                "online": {
+                    "type": "string"
+                }
+            }
+        },
         "routes.Species": {
             "type": "object",
             "properties": {
@@ -469,6 +1439,23 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "routes.WorkerStatus": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "heartbeat": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
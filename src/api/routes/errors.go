@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+// errorStatus is the HTTP status of a storage error, unknown records and constraint violations
+// are caused by the request while other errors are failures of the service.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrUnknownSensor), errors.Is(err, storage.ErrSensorNotFound), errors.Is(err, storage.ErrUnknownGroup):
+		return http.StatusNotFound
+	case errors.Is(err, storage.ErrBadCodeName):
+		return http.StatusBadRequest
+	case errors.Is(err, storage.ErrDuplicate):
+		return http.StatusConflict
+	case errors.Is(err, storage.ErrMissingReference):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, storage.ErrInvalidData):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,164 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/export"
+	"github.com/jenyasd209/fake-sensors/src/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	exportKindParam = "kind"
+
+	exportRoute = "/export/:" + exportKindParam
+)
+
+var ErrBadDate = errors.New("Invalid date format")
+
+func RegisterExportRoutes(router *Router) {
+	router.routes.GET(exportRoute, router.Export)
+}
+
+// @Summary Export records
+// @Description Stream readings, fish observations, sensors metadata or anomaly labels as a file. Records can be filtered by group, sensor, region and time range.
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.apache.parquet
+// @Param kind path string true "Records kind" Enums(readings, fish, sensors, labels)
+// @Param format query string false "File format" Enums(csv, ndjson, parquet) default(csv)
+// @Param group query string false "Group name"
+// @Param sensor query string false "Sensor code name or UUID"
+// @Param from query string false "From (RFC3339, like 2023-11-01T00:00:00Z)"
+// @Param till query string false "Till (RFC3339, like 2023-11-01T00:00:00Z)"
+// @Param xMin query number false "xMin" format(float)
+// @Param xMax query number false "xMax" format(float)
+// @Param yMin query number false "yMin" format(float)
+// @Param yMax query number false "yMax" format(float)
+// @Param zMin query number false "zMin" format(float)
+// @Param zMax query number false "zMax" format(float)
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse "error message"
+// @Failure 404 {object} ErrorResponse "error message"
+// @Router /export/{kind} [get]
+func (r *Router) Export(context *gin.Context) {
+	kind, err := export.ParseKind(context.Param(exportKindParam))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	format, err := export.ParseFormat(context.DefaultQuery("format", string(export.CSV)))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	filter := &storage.ExportFilter{Group: context.Query("group")}
+	if ref := context.Query("sensor"); ref != "" {
+		sensor, err := r.storage.GetSensor(ref)
+		if err != nil {
+			context.JSON(errorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		filter.SensorId = sensor.ID
+	}
+
+	filter.Region, err = parseCoordinates(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	filter.Conditions, err = parseTimeRange(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	fileName := string(kind) + "." + string(format)
+	context.Header("Content-Disposition", "attachment; filename="+strconv.Quote(fileName))
+	context.Header("Content-Type", format.ContentType())
+
+	err = export.Export(context, r.storage, context.Writer, kind, format, filter)
+	if err == nil {
+		return
+	}
+	log.Printf("cannot export %s: %s\n", kind, err)
+
+	// The output is buffered, so the errors of the query are reported before the status is sent.
+	if !context.Writer.Written() {
+		context.Writer.Header().Del("Content-Disposition")
+		context.JSON(errorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	abortResponse(context)
+}
+
+// abortResponse closes the connection before the end of the response which is sent already,
+// so the client sees the response is incomplete rather than takes a truncated file.
+func abortResponse(context *gin.Context) {
+	conn, _, err := context.Writer.Hijack()
+	if err != nil {
+		log.Printf("cannot abort the response: %s\n", err)
+		return
+	}
+
+	conn.Close()
+}
+
+func parseTimeRange(context *gin.Context) ([]storage.ConditionOption, error) {
+	from, till, err := parseTimes(context)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := make([]storage.ConditionOption, 0, 2)
+	if !from.IsZero() {
+		opts = append(opts, storage.WithCreatedFrom(from))
+	}
+	if !till.IsZero() {
+		opts = append(opts, storage.WithCreatedTill(till))
+	}
+
+	return opts, nil
+}
+
+// parseTimes returns the from and till query params, zero if they are not set.
+func parseTimes(context *gin.Context) (from, till time.Time, err error) {
+	if fromQ := context.Query("from"); fromQ != "" {
+		if from, err = parseTime(fromQ); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	if tillQ := context.Query("till"); tillQ != "" {
+		if till, err = parseTime(tillQ); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	return from, till, nil
+}
+
+// parseTime parses the RFC3339 time the CLI takes as well, the UNIX date format
+// (Mon Jan 2 15:04:05 MST 2006) is still accepted for the existing clients.
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.UnixDate, value)
+	if err != nil {
+		return time.Time{}, ErrBadDate
+	}
+
+	return t, nil
+}
@@ -0,0 +1,127 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/generator"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	generatorStatusRoute = "/generator/status"
+	generatorLagRoute    = "/generator/lag"
+)
+
+var ErrNoGenerator = errors.New("generator is not running in this instance")
+
+func RegisterGeneratorRoutes(router *Router) {
+	router.routes.GET(generatorStatusRoute, router.GetGeneratorStatus)
+	router.routes.GET(generatorLagRoute, router.GetGeneratorLag)
+}
+
+// @Summary Get generator workers
+// @Description Get the alive generator workers and the groups each of them generates data for. Groups are assigned to workers by consistent hashing of their names, groups are unassigned while no worker is alive.
+// @Produce json
+// @Success 200 {object} GeneratorStatus
+// @Failure 500 {object} ErrorResponse "error message"
+// @Router /generator/status [get]
+func (r *Router) GetGeneratorStatus(context *gin.Context) {
+	workers, err := r.storage.GetWorkers(context, generator.WorkerTTL)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	groups, err := r.storage.GetAllGroups()
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	statuses := make([]*WorkerStatus, 0, len(workers))
+	byId := make(map[string]*WorkerStatus, len(workers))
+	for _, worker := range workers {
+		status := &WorkerStatus{
+			Id:        worker.Id,
+			Heartbeat: worker.Heartbeat.Format(time.RFC3339),
+			Groups:    []string{},
+		}
+		statuses = append(statuses, status)
+		byId[worker.Id] = status
+	}
+
+	owners := generator.GroupOwners(workers, groups)
+	unassigned := make([]string, 0)
+	for _, group := range groups {
+		if status, ok := byId[owners[group.ID]]; ok {
+			status.Groups = append(status.Groups, group.Name)
+		} else {
+			unassigned = append(unassigned, group.Name)
+		}
+	}
+
+	for _, status := range statuses {
+		sort.Strings(status.Groups)
+	}
+	sort.Strings(unassigned)
+
+	context.JSON(http.StatusOK, GeneratorStatus{
+		Workers:    statuses,
+		Unassigned: unassigned,
+	})
+}
+
+// @Summary Get update lag of sensors
+// @Description Get how late the updates of the sensors generated by this instance start compared to their schedule. Lag is the delay of the latest update, skipped is the number of updates dropped while the generator was behind.
+// @Produce json
+// @Success 200 {object} GeneratorLag
+// @Failure 404 {object} ErrorResponse "error message"
+// @Router /generator/lag [get]
+func (r *Router) GetGeneratorLag(context *gin.Context) {
+	if r.generator == nil {
+		context.JSON(http.StatusNotFound, ErrorResponse{Error: ErrNoGenerator.Error()})
+		return
+	}
+
+	lags := r.generator.Lag()
+	sort.Slice(lags, func(i, j int) bool {
+		return lags[i].SensorId < lags[j].SensorId
+	})
+
+	sensors := make([]*SensorLag, 0, len(lags))
+	var total, maxLag time.Duration
+	var skipped uint64
+	for _, lag := range lags {
+		total += lag.Lag
+		skipped += lag.Skipped
+		if lag.MaxLag > maxLag {
+			maxLag = lag.MaxLag
+		}
+
+		sensors = append(sensors, &SensorLag{
+			SensorId:   strconv.FormatUint(uint64(lag.SensorId), 10),
+			Lag:        lag.Lag.String(),
+			MaxLag:     lag.MaxLag.String(),
+			Updates:    strconv.FormatUint(lag.Updates, 10),
+			Skipped:    strconv.FormatUint(lag.Skipped, 10),
+			NextUpdate: lag.NextUpdate.Format(time.RFC3339Nano),
+		})
+	}
+
+	average := time.Duration(0)
+	if len(lags) > 0 {
+		average = total / time.Duration(len(lags))
+	}
+
+	context.JSON(http.StatusOK, GeneratorLag{
+		AverageLag: average.String(),
+		MaxLag:     maxLag.String(),
+		Skipped:    strconv.FormatUint(skipped, 10),
+		Sensors:    sensors,
+	})
+}
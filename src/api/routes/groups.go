@@ -3,7 +3,6 @@ package routes
 import (
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/jenyasd209/fake-sensors/src/storage"
 
@@ -18,6 +17,7 @@ const (
 	groupAvgTemperature  = "/temperature/average"
 	groupSpecies         = "/species"
 	groupTopSpecies      = "/top/:n"
+	groupSensors         = "/sensor"
 )
 
 func RegisterGroupRoutes(router *Router) {
@@ -27,6 +27,7 @@ func RegisterGroupRoutes(router *Router) {
 
 	groups.GET(groupAvgTransparency, router.GetGroupAvgTransparency)
 	groups.GET(groupAvgTemperature, router.GetGroupAvgTemperature)
+	groups.GET(groupSensors, router.GetGroupSensors)
 
 	groups.GET(groupSpecies, router.GetGroupSpecies)
 	species := groups.Group(groupSpecies)
@@ -106,7 +107,7 @@ func (r *Router) GetGroupAvgTemperature(context *gin.Context) {
 // @Failure 500 {object} ErrorResponse "error message"
 // @Router /group/{groupName}/species [get]
 func (r *Router) GetGroupSpecies(context *gin.Context) {
-	species, err := getSpecies(r.storage, context.Param(groupNameParam), 0)
+	species, err := getSpecies(context, r.storage, context.Param(groupNameParam), 0)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
@@ -122,8 +123,8 @@ func (r *Router) GetGroupSpecies(context *gin.Context) {
 // @Produce json
 // @Param groupName path string true "Group name"
 // @Param n path int true "Count of species"
-// @Param from query string false "From (UNIX timestamps)"
-// @Param till query string false "Till (UNIX timestamps)"
+// @Param from query string false "From (RFC3339, like 2023-11-01T00:00:00Z)"
+// @Param till query string false "Till (RFC3339, like 2023-11-01T00:00:00Z)"
 // @Success 200 {object} SpeciesList
 // @Failure 400 {object} ErrorResponse "error message"
 // @Failure 500 {object} ErrorResponse "error message"
@@ -135,28 +136,13 @@ func (r *Router) GetGroupTopSpecies(context *gin.Context) {
 		return
 	}
 
-	opts := make([]storage.ConditionOption, 0, 2)
-	fromQ := context.Query("from")
-	if fromQ != "" {
-		from, err := time.Parse(time.UnixDate, fromQ)
-		if err != nil {
-			context.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
-			return
-		}
-		opts = append(opts, storage.WithCreatedFrom(from))
-	}
-
-	tillQ := context.Query("till")
-	if tillQ != "" {
-		till, err := time.Parse(time.UnixDate, tillQ)
-		if err != nil {
-			context.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid date format"})
-			return
-		}
-		opts = append(opts, storage.WithCreatedTill(till))
+	opts, err := parseTimeRange(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
 	}
 
-	species, err := getSpecies(r.storage, context.Param(groupNameParam), count, opts...)
+	species, err := getSpecies(context, r.storage, context.Param(groupNameParam), count, opts...)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
@@ -167,8 +153,8 @@ func (r *Router) GetGroupTopSpecies(context *gin.Context) {
 	})
 }
 
-func getSpecies(storage *storage.Storage, groupName string, top int, opts ...storage.ConditionOption) ([]*Species, error) {
-	fishesRecords, err := storage.GetCurrentSpecies(groupName, top, opts...)
+func getSpecies(ctx *gin.Context, storage *storage.Storage, groupName string, top int, opts ...storage.ConditionOption) ([]*Species, error) {
+	fishesRecords, err := storage.GetCurrentSpecies(ctx, groupName, top, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -183,3 +169,32 @@ func getSpecies(storage *storage.Storage, groupName string, top int, opts ...sto
 
 	return species, nil
 }
+
+// @Summary Get sensors list of the group
+// @Description Get the sensors of the group with their positions and current values, optionally in a region. The sensors are sorted by the given field and paged.
+// @Produce json
+// @Param groupName path string true "Group name"
+// @Param xMin query number false "xMin" format(float)
+// @Param xMax query number false "xMax" format(float)
+// @Param yMin query number false "yMin" format(float)
+// @Param yMax query number false "yMax" format(float)
+// @Param zMin query number false "zMin" format(float)
+// @Param zMax query number false "zMax" format(float)
+// @Param sort query string false "Sort field" Enums(code_name, x, y, z, data_output_rate, created_at, last_reading, battery) default(code_name)
+// @Param order query string false "Sort order" Enums(asc, desc) default(asc)
+// @Param limit query int false "Page size, at most 500" default(50)
+// @Param offset query int false "Number of skipped sensors" default(0)
+// @Success 200 {object} SensorList
+// @Failure 400 {object} ErrorResponse "error message"
+// @Failure 404 {object} ErrorResponse "error message"
+// @Failure 500 {object} ErrorResponse "error message"
+// @Router /group/{groupName}/sensor [get]
+func (r *Router) GetGroupSensors(context *gin.Context) {
+	group, err := r.storage.GetGroupByName(context.Param(groupNameParam))
+	if err != nil {
+		context.JSON(errorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	r.listSensors(context, storage.WithSensorGroup(group.Name))
+}
@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const healthSensorsRoute = "/health/sensors"
+
+func RegisterHealthRoutes(router *Router) {
+	router.routes.GET(healthSensorsRoute, router.GetSensorsHealth)
+}
+
+// @Summary Get sensors health
+// @Description Get the number of online, late and offline sensors in total and per group. A sensor is late once it misses a report expected by its data output rate and offline after several missed reports.
+// @Produce json
+// @Success 200 {object} SensorsHealth
+// @Failure 500 {object} ErrorResponse "error message"
+// @Router /health/sensors [get]
+func (r *Router) GetSensorsHealth(context *gin.Context) {
+	groups, err := r.storage.GetGroupsHealth(time.Now())
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var online, late, offline int
+	res := SensorsHealth{Groups: make([]*GroupHealth, len(groups))}
+	for i, g := range groups {
+		online, late, offline = online+g.Online, late+g.Late, offline+g.Offline
+		res.Groups[i] = &GroupHealth{
+			Group:   g.Group,
+			Online:  strconv.Itoa(g.Online),
+			Late:    strconv.Itoa(g.Late),
+			Offline: strconv.Itoa(g.Offline),
+		}
+	}
+	res.Online, res.Late, res.Offline = strconv.Itoa(online), strconv.Itoa(late), strconv.Itoa(offline)
+
+	context.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,73 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jenyasd209/fake-sensors/src/ingest"
+	"github.com/jenyasd209/fake-sensors/src/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ingestRoute = "/ingest"
+
+	// maxIngestBodySize limits the batch a client may send at once, the whole batch is held in memory.
+	maxIngestBodySize = 10 << 20
+)
+
+func RegisterIngestRoutes(router *Router) {
+	router.routes.POST(ingestRoute, router.Ingest)
+}
+
+// @Summary Ingest external readings
+// @Description Save a batch of readings measured by existing sensors. Sensors are identified by id or code name. The batch is accepted as JSON or as InfluxDB line protocol (measurements "sensor" with temperature and transparency fields and "fish" with species tag and count field). Readings repeating a saved (sensor, timestamp) pair are skipped.
+// @Accept json
+// @Accept plain
+// @Produce json
+// @Success 200 {object} IngestResult
+// @Failure 400 {object} ErrorResponse "error message"
+// @Failure 409 {object} ErrorResponse "error message"
+// @Failure 413 {object} ErrorResponse "batch is larger than 10 MiB"
+// @Failure 422 {object} ErrorResponse "error message"
+// @Failure 500 {object} ErrorResponse "error message"
+// @Router /ingest [post]
+func (r *Router) Ingest(context *gin.Context) {
+	context.Request.Body = http.MaxBytesReader(context.Writer, context.Request.Body, maxIngestBodySize)
+
+	var readings []*storage.Reading
+	var err error
+	if strings.HasPrefix(context.ContentType(), "application/json") {
+		readings, err = ingest.ParseJSON(context.Request.Body)
+	} else {
+		readings, err = ingest.ParseLineProtocol(context.Request.Body)
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		context.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	res, err := r.storage.IngestReadings(readings)
+	if err != nil {
+		context.JSON(errorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	rejected := make([]*RejectedReading, 0, len(res.Rejected))
+	for _, e := range res.Rejected {
+		rejected = append(rejected, &RejectedReading{Index: e.Index, Error: e.Err.Error()})
+	}
+
+	context.JSON(http.StatusOK, IngestResult{
+		Accepted:   res.Accepted,
+		Duplicates: res.Duplicates,
+		Rejected:   rejected,
+	})
+}
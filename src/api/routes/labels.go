@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+const labelsRoute = "/labels"
+
+func RegisterLabelRoutes(router *Router) {
+	router.routes.GET(labelsRoute, router.GetLabels)
+}
+
+// @Summary Get anomaly labels
+// @Description Get the ground truth of the anomalies the generator produced (spike, drift, dropout, scenario_event, population_shock, hardware_failure, battery_depleted) which overlap the specified date/time pairs (RFC3339), optionally of a sensor and of a kind. An empty metric means every metric of the sensor is affected.
+// @Produce json
+// @Param from query string false "From (RFC3339, like 2023-11-01T00:00:00Z)"
+// @Param till query string false "Till (RFC3339, like 2023-11-01T00:00:00Z)"
+// @Param sensor query string false "Sensor code name or UUID"
+// @Param kind query string false "Anomaly kind" Enums(spike, drift, dropout, scenario_event, population_shock, hardware_failure, battery_depleted)
+// @Success 200 {object} AnomalyLabels
+// @Failure 400 {object} ErrorResponse "error message"
+// @Failure 404 {object} ErrorResponse "error message"
+// @Failure 500 {object} ErrorResponse "error message"
+// @Router /labels [get]
+func (r *Router) GetLabels(context *gin.Context) {
+	from, till, err := parseTimes(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	opts := []storage.LabelQueryOption{storage.WithLabelsBetween(from, till)}
+	if kind := context.Query("kind"); kind != "" {
+		k, err := storage.ParseAnomalyKind(kind)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		opts = append(opts, storage.WithLabelKind(k))
+	}
+
+	if ref := context.Query("sensor"); ref != "" {
+		sensor, err := r.storage.GetSensor(ref)
+		if err != nil {
+			context.JSON(errorStatus(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		opts = append(opts, storage.WithLabelSensor(sensor.ID))
+	}
+
+	labels, err := r.storage.GetAnomalyLabels(opts...)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	res := AnomalyLabels{Labels: make([]*AnomalyLabel, len(labels))}
+	for i, label := range labels {
+		res.Labels[i] = &AnomalyLabel{
+			Id:        strconv.FormatUint(uint64(label.ID), 10),
+			Sensor:    label.SensorCodeName,
+			Kind:      string(label.Kind),
+			Metric:    label.Metric,
+			StartedAt: label.CreatedAt.Format(time.RFC3339),
+			EndedAt:   label.EndedAt.Format(time.RFC3339),
+			Magnitude: strconv.FormatFloat(label.Magnitude, 'f', 2, 64),
+			Detail:    label.Detail,
+		}
+	}
+
+	context.JSON(http.StatusOK, res)
+}
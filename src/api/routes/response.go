@@ -21,6 +21,47 @@ type SpeciesList struct {
 	Species []*Species `json:"species"`
 }
 
+// swagger:model
+type Sensor struct {
+	Id             string             `json:"id"`
+	UUID           string             `json:"uuid"`
+	CodeName       string             `json:"code_name"`
+	Group          string             `json:"group"`
+	Index          string             `json:"index"`
+	X              string             `json:"x"`
+	Y              string             `json:"y"`
+	Z              string             `json:"z"`
+	DataOutputRate string             `json:"data_output_rate"`
+	CreatedAt      string             `json:"created_at"`
+	Status         string             `json:"status"`
+	MissedReports  string             `json:"missed_reports"`
+	LastReading    string             `json:"last_reading"`
+	Temperature    string             `json:"temperature"`
+	Transparency   string             `json:"transparency"`
+	Battery        string             `json:"battery"`
+	HardwareStatus string             `json:"hardware_status"`
+	FailedAt       string             `json:"failed_at"`
+	MaintainedAt   string             `json:"maintained_at"`
+	Calibration    *SensorCalibration `json:"calibration"`
+}
+
+// swagger:model
+type SensorCalibration struct {
+	Offset     string `json:"offset"`
+	Gain       string `json:"gain"`
+	NoiseStd   string `json:"noise_std"`
+	Resolution string `json:"resolution"`
+	Unit       string `json:"unit"`
+}
+
+// swagger:model
+type SensorList struct {
+	Sensors []*Sensor `json:"sensors"`
+	Total   string    `json:"total"`
+	Limit   string    `json:"limit"`
+	Offset  string    `json:"offset"`
+}
+
 // swagger:model
 type Value struct {
 	Value string `json:"value"`
@@ -30,3 +71,94 @@ type Value struct {
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
+
+// swagger:model
+type RejectedReading struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// swagger:model
+type IngestResult struct {
+	Accepted   int                `json:"accepted"`
+	Duplicates int                `json:"duplicates"`
+	Rejected   []*RejectedReading `json:"rejected"`
+}
+
+// swagger:model
+type WorkerStatus struct {
+	Id        string   `json:"id"`
+	Heartbeat string   `json:"heartbeat"`
+	Groups    []string `json:"groups"`
+}
+
+// swagger:model
+type GeneratorStatus struct {
+	Workers    []*WorkerStatus `json:"workers"`
+	Unassigned []string        `json:"unassigned"`
+}
+
+// swagger:model
+type SensorLag struct {
+	SensorId   string `json:"sensor_id"`
+	Lag        string `json:"lag"`
+	MaxLag     string `json:"max_lag"`
+	Updates    string `json:"updates"`
+	Skipped    string `json:"skipped"`
+	NextUpdate string `json:"next_update"`
+}
+
+// swagger:model
+type GeneratorLag struct {
+	AverageLag string       `json:"average_lag"`
+	MaxLag     string       `json:"max_lag"`
+	Skipped    string       `json:"skipped"`
+	Sensors    []*SensorLag `json:"sensors"`
+}
+
+// swagger:model
+type GroupHealth struct {
+	Group   string `json:"group"`
+	Online  string `json:"online"`
+	Late    string `json:"late"`
+	Offline string `json:"offline"`
+}
+
+// swagger:model
+type SensorsHealth struct {
+	Online  string         `json:"online"`
+	Late    string         `json:"late"`
+	Offline string         `json:"offline"`
+	Groups  []*GroupHealth `json:"groups"`
+}
+
+// swagger:model
+type SensorEvent struct {
+	Time          string `json:"time"`
+	From          string `json:"from"`
+	To            string `json:"to"`
+	MissedReports string `json:"missed_reports"`
+	LastSeen      string `json:"last_seen"`
+}
+
+// swagger:model
+type AnomalyLabel struct {
+	Id        string `json:"id"`
+	Sensor    string `json:"sensor"`
+	Kind      string `json:"kind"`
+	Metric    string `json:"metric"`
+	StartedAt string `json:"started_at"`
+	EndedAt   string `json:"ended_at"`
+	Magnitude string `json:"magnitude"`
+	Detail    string `json:"detail"`
+}
+
+// swagger:model
+type AnomalyLabels struct {
+	Labels []*AnomalyLabel `json:"labels"`
+}
+
+// swagger:model
+type SensorEvents struct {
+	Events []*SensorEvent `json:"events"`
+}
@@ -1,7 +1,10 @@
 package routes
 
 import (
+	"net/http"
+
 	_ "github.com/jenyasd209/fake-sensors/src/api/doc"
+	"github.com/jenyasd209/fake-sensors/src/generator"
 	"github.com/jenyasd209/fake-sensors/src/storage"
 
 	"github.com/gin-gonic/gin"
@@ -10,19 +13,37 @@ import (
 )
 
 type Router struct {
-	routes  *gin.Engine
-	storage *storage.Storage
+	routes    *gin.Engine
+	storage   *storage.Storage
+	generator *generator.Generator
+}
+
+type RouterOption func(r *Router)
+
+// WithGenerator exposes the state of the generator running in this instance.
+func WithGenerator(g *generator.Generator) RouterOption {
+	return func(r *Router) {
+		r.generator = g
+	}
 }
 
-func NewRouter(storage *storage.Storage) *Router {
+func NewRouter(storage *storage.Storage, opts ...RouterOption) *Router {
 	r := &Router{
 		routes:  gin.Default(),
 		storage: storage,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
 
 	RegisterGroupRoutes(r)
 	RegisterSensorRoutes(r)
 	RegisterTemperatureRoutes(r)
+	RegisterExportRoutes(r)
+	RegisterIngestRoutes(r)
+	RegisterGeneratorRoutes(r)
+	RegisterHealthRoutes(r)
+	RegisterLabelRoutes(r)
 
 	r.routes.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
@@ -32,3 +53,7 @@ func NewRouter(storage *storage.Storage) *Router {
 func (r *Router) Run(addr string) error {
 	return r.routes.Run(addr)
 }
+
+func (r *Router) Handler() http.Handler {
+	return r.routes.Handler()
+}
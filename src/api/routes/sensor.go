@@ -1,8 +1,8 @@
 package routes
 
 import (
+	"errors"
 	"net/http"
-	"regexp"
 	"strconv"
 	"time"
 
@@ -14,66 +14,292 @@ import (
 const (
 	codeNameParam = "codeName"
 
-	sensorAvgTemperature = "/sensor/:" + codeNameParam + "/temperature/average"
+	sensorsRoute         = "/sensor"
+	sensorRoute          = "/sensor/:" + codeNameParam
+	sensorAvgTemperature = "/temperature/average"
+	sensorEvents         = "/events"
+
+	defaultSensorsLimit = 50
+	maxSensorsLimit     = 500
 )
 
-var pattern = regexp.MustCompile("([a-zA-Z]+)([0-9]+)")
+var (
+	ErrBadPage  = errors.New("Invalid limit or offset")
+	ErrBadOrder = errors.New("Invalid order, expected asc or desc")
+)
 
 func RegisterSensorRoutes(router *Router) {
-	router.routes.GET(sensorAvgTemperature, router.GetSensorAvgTemperature)
+	router.routes.GET(sensorsRoute, router.GetSensors)
+	router.routes.GET(sensorRoute, router.GetSensor)
+
+	sensors := router.routes.Group(sensorRoute)
+	sensors.GET(sensorAvgTemperature, router.GetSensorAvgTemperature)
+	sensors.GET(sensorEvents, router.GetSensorEvents)
+}
+
+// @Summary Get sensors list
+// @Description Get the sensors with their positions, health statuses, batteries and current values, optionally of a group and in a region. The sensors are sorted by the given field and paged.
+// @Produce json
+// @Param group query string false "Group name"
+// @Param xMin query number false "xMin" format(float)
+// @Param xMax query number false "xMax" format(float)
+// @Param yMin query number false "yMin" format(float)
+// @Param yMax query number false "yMax" format(float)
+// @Param zMin query number false "zMin" format(float)
+// @Param zMax query number false "zMax" format(float)
+// @Param sort query string false "Sort field" Enums(code_name, x, y, z, data_output_rate, created_at, last_reading, battery) default(code_name)
+// @Param order query string false "Sort order" Enums(asc, desc) default(asc)
+// @Param limit query int false "Page size, at most 500" default(50)
+// @Param offset query int false "Number of skipped sensors" default(0)
+// @Success 200 {object} SensorList
+// @Failure 400 {object} ErrorResponse "error message"
+// @Failure 500 {object} ErrorResponse "error message"
+// @Router /sensor [get]
+func (r *Router) GetSensors(context *gin.Context) {
+	r.listSensors(context, storage.WithSensorGroup(context.Query("group")))
+}
+
+// @Summary Get sensor metadata
+// @Description Get the identity, position, data output rate, calibration, hardware and health status and current values of a sensor given by its code name (like alpha3) or UUID
+// @Produce json
+// @Param codeName path string true "sensor code name or UUID"
+// @Success 200 {object} Sensor
+// @Failure 400 {object} ErrorResponse "error message"
+// @Failure 404 {object} ErrorResponse "error message"
+// @Failure 500 {object} ErrorResponse "error message"
+// @Router /sensor/{codeName} [get]
+func (r *Router) GetSensor(context *gin.Context) {
+	sensor, ok := r.sensor(context)
+	if !ok {
+		return
+	}
+
+	latest, err := r.storage.GetLatestReadings([]uint{sensor.ID})
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	health := r.storage.SensorHealth(sensor, latest[sensor.ID], time.Now())
+	context.JSON(http.StatusOK, sensorResponse(sensor, latest[sensor.ID], health))
 }
 
 // @Summary Get average temperature detected by a particular sensor
-// @Description Get average temperature detected by a particular sensor between the specified date/time pairs (UNIX timestamps)
+// @Description Get average temperature detected by a particular sensor between the specified date/time pairs (RFC3339)
 // @Produce json
-// @Param codeName path string true "sensor code name"
-// @Param from query string false "From (UNIX timestamps)"
-// @Param till query string false "Till (UNIX timestamps)"
+// @Param codeName path string true "sensor code name or UUID"
+// @Param from query string false "From (RFC3339, like 2023-11-01T00:00:00Z)"
+// @Param till query string false "Till (RFC3339, like 2023-11-01T00:00:00Z)"
 // @Success 200 {object} Average
 // @Failure 400 {object} ErrorResponse "error message"
+// @Failure 404 {object} ErrorResponse "error message"
 // @Failure 500 {object} ErrorResponse "error message"
 // @Router /sensor/{codeName}/temperature/average [get]
 func (r *Router) GetSensorAvgTemperature(context *gin.Context) {
-	matches := pattern.FindStringSubmatch(context.Param(codeNameParam))
-	if len(matches) < 3 {
-		context.JSON(http.StatusBadRequest, gin.H{"error": "Invalid codeName"})
+	opts, err := parseTimeRange(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	sensor, ok := r.sensor(context)
+	if !ok {
+		return
 	}
 
-	letter := matches[1]
-	index, err := strconv.Atoi(matches[2])
+	avg, err := r.storage.GetSensorAvgTemperature(sensor.ID, opts...)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	opts := make([]storage.ConditionOption, 0, 2)
-	fromQ := context.Query("from")
-	if fromQ != "" {
-		from, err := time.Parse(time.UnixDate, fromQ)
-		if err != nil {
-			context.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
-			return
-		}
-		opts = append(opts, storage.WithCreatedFrom(from))
+	context.JSON(http.StatusOK, Average{
+		Average: strconv.FormatFloat(avg, 'f', 2, 64),
+	})
+}
+
+// @Summary Get sensor status changes
+// @Description Get the health status transitions (online, late, offline) of a sensor between the specified date/time pairs (RFC3339)
+// @Produce json
+// @Param codeName path string true "sensor code name or UUID"
+// @Param from query string false "From (RFC3339, like 2023-11-01T00:00:00Z)"
+// @Param till query string false "Till (RFC3339, like 2023-11-01T00:00:00Z)"
+// @Success 200 {object} SensorEvents
+// @Failure 400 {object} ErrorResponse "error message"
+// @Failure 404 {object} ErrorResponse "error message"
+// @Failure 500 {object} ErrorResponse "error message"
+// @Router /sensor/{codeName}/events [get]
+func (r *Router) GetSensorEvents(context *gin.Context) {
+	opts, err := parseTimeRange(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	sensor, ok := r.sensor(context)
+	if !ok {
+		return
 	}
 
-	tillQ := context.Query("till")
-	if tillQ != "" {
-		till, err := time.Parse(time.UnixDate, tillQ)
-		if err != nil {
-			context.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid date format"})
-			return
+	events, err := r.storage.GetSensorEvents(sensor.ID, opts...)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	res := SensorEvents{Events: make([]*SensorEvent, len(events))}
+	for i, event := range events {
+		res.Events[i] = &SensorEvent{
+			Time:          event.CreatedAt.Format(time.RFC3339),
+			From:          string(event.FromStatus),
+			To:            string(event.ToStatus),
+			MissedReports: strconv.Itoa(event.Missed),
+		}
+		if event.LastSeenAt != nil {
+			res.Events[i].LastSeen = event.LastSeenAt.Format(time.RFC3339)
 		}
-		opts = append(opts, storage.WithCreatedTill(till))
 	}
 
-	avg, err := r.storage.GetSensorAvgTemperature(letter, index, opts...)
+	context.JSON(http.StatusOK, res)
+}
+
+// sensor resolves the sensor given by the code name or UUID path parameter, it writes the error
+// response and returns false if there is no such sensor.
+func (r *Router) sensor(context *gin.Context) (*storage.Sensor, bool) {
+	sensor, err := r.storage.GetSensor(context.Param(codeNameParam))
+	if err != nil {
+		context.JSON(errorStatus(err), ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
+
+	return sensor, true
+}
+
+// listSensors writes the page of the sensors matching the options and the region, sort and page
+// query parameters.
+func (r *Router) listSensors(context *gin.Context, opts ...storage.SensorQueryOption) {
+	region, err := parseCoordinates(context)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	opts = append(opts, storage.WithSensorRegion(region...))
+
+	sort, err := storage.ParseSensorSort(context.DefaultQuery("sort", string(storage.SortByCodeName)))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var desc bool
+	switch context.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		desc = true
+	default:
+		context.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrBadOrder.Error()})
+		return
+	}
+
+	limit, err := strconv.Atoi(context.DefaultQuery("limit", strconv.Itoa(defaultSensorsLimit)))
+	if err != nil || limit <= 0 || limit > maxSensorsLimit {
+		context.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrBadPage.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(context.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		context.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrBadPage.Error()})
+		return
+	}
+
+	total, err := r.storage.CountSensors(opts...)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	context.JSON(http.StatusOK, Average{
-		Average: strconv.FormatFloat(avg, 'f', 2, 64),
-	})
+	sensors, err := r.storage.GetAllSensors(append(opts, storage.WithSensorSort(sort, desc), storage.WithSensorPage(limit, offset))...)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ids := make([]uint, len(sensors))
+	for i, sensor := range sensors {
+		ids[i] = sensor.ID
+	}
+
+	latest, err := r.storage.GetLatestReadings(ids)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	list := SensorList{
+		Sensors: make([]*Sensor, len(sensors)),
+		Total:   strconv.FormatInt(total, 10),
+		Limit:   strconv.Itoa(limit),
+		Offset:  strconv.Itoa(offset),
+	}
+	now := time.Now()
+	for i, sensor := range sensors {
+		list.Sensors[i] = sensorResponse(sensor, latest[sensor.ID], r.storage.SensorHealth(sensor, latest[sensor.ID], now))
+	}
+
+	context.JSON(http.StatusOK, list)
+}
+
+// sensorResponse describes the sensor, latest is nil if the sensor never reported and then
+// the current values are empty.
+func sensorResponse(sensor *storage.Sensor, latest *storage.LatestReading, health *storage.SensorHealth) *Sensor {
+	group, _, _ := storage.ParseCodeName(sensor.CodeName)
+
+	res := &Sensor{
+		Id:             strconv.FormatUint(uint64(sensor.ID), 10),
+		UUID:           sensor.UUID.String(),
+		CodeName:       sensor.CodeName,
+		Group:          group,
+		Index:          strconv.FormatUint(sensor.IndexInGroup, 10),
+		X:              strconv.FormatFloat(sensor.X, 'f', 2, 64),
+		Y:              strconv.FormatFloat(sensor.Y, 'f', 2, 64),
+		Z:              strconv.FormatFloat(sensor.Z, 'f', 2, 64),
+		DataOutputRate: sensor.DataOutputRate.String(),
+		CreatedAt:      sensor.CreatedAt.Format(time.RFC3339),
+		Status:         string(health.Status),
+		MissedReports:  strconv.Itoa(health.Missed),
+		Battery:        strconv.FormatFloat(sensor.Battery, 'f', 2, 64),
+		HardwareStatus: string(sensor.HardwareStatus()),
+		Calibration: &SensorCalibration{
+			Offset:     strconv.FormatFloat(sensor.Calibration.Offset, 'f', -1, 64),
+			Gain:       strconv.FormatFloat(sensor.Calibration.Gain, 'f', -1, 64),
+			NoiseStd:   strconv.FormatFloat(sensor.Calibration.NoiseStd, 'f', -1, 64),
+			Resolution: strconv.FormatFloat(sensor.Calibration.Resolution, 'f', -1, 64),
+			Unit:       string(sensor.Calibration.Unit),
+		},
+	}
+
+	if sensor.FailedAt != nil {
+		res.FailedAt = sensor.FailedAt.Format(time.RFC3339)
+	}
+	if sensor.MaintainedAt != nil {
+		res.MaintainedAt = sensor.MaintainedAt.Format(time.RFC3339)
+	}
+
+	if health.LastSeen != nil {
+		res.LastReading = health.LastSeen.Format(time.RFC3339)
+	}
+
+	if latest == nil {
+		return res
+	}
+
+	if latest.Temperature != nil {
+		res.Temperature = strconv.FormatFloat(*latest.Temperature, 'f', 2, 64)
+	}
+	if latest.Transparency != nil {
+		res.Transparency = strconv.FormatUint(uint64(*latest.Transparency), 10)
+	}
+
+	return res
 }
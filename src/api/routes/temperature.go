@@ -41,7 +41,7 @@ func (r *Router) GetMinTemperature(context *gin.Context) {
 		return
 	}
 
-	minT, err := r.storage.GetMinTemperatureByRegion(opts...)
+	minT, err := r.storage.GetMinTemperatureByRegion(context, opts...)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
@@ -72,7 +72,7 @@ func (r *Router) GetMaxTemperature(context *gin.Context) {
 		return
 	}
 
-	maxT, err := r.storage.GetMaxTemperatureByRegion(opts...)
+	maxT, err := r.storage.GetMaxTemperatureByRegion(context, opts...)
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
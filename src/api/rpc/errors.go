@@ -0,0 +1,37 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError converts the storage error into the gRPC status with the code matching the HTTP
+// status the REST API responds with.
+func statusError(err error) error {
+	return status.Error(errorCode(err), err.Error())
+}
+
+func errorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, storage.ErrUnknownSensor), errors.Is(err, storage.ErrSensorNotFound), errors.Is(err, storage.ErrUnknownGroup):
+		return codes.NotFound
+	case errors.Is(err, storage.ErrBadCodeName):
+		return codes.InvalidArgument
+	case errors.Is(err, storage.ErrDuplicate):
+		return codes.AlreadyExists
+	case errors.Is(err, storage.ErrMissingReference):
+		return codes.FailedPrecondition
+	case errors.Is(err, storage.ErrInvalidData):
+		return codes.InvalidArgument
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,206 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+const (
+	// feedBatchSize is the number of readings of every metric fetched by a query.
+	feedBatchSize = 500
+	// subscriptionBuffer is the number of readings a subscriber may lag behind before it is dropped.
+	subscriptionBuffer = 1024
+)
+
+var (
+	ErrFeedClosed  = errors.New("reading feed is closed")
+	ErrSlowReceive = errors.New("readings are received slower than they are saved")
+)
+
+// readingFilter selects the readings of a subscription, zero fields are not applied.
+type readingFilter struct {
+	sensorId uint
+	group    string
+	metrics  map[string]bool
+}
+
+func (f *readingFilter) match(reading *storage.FeedReading) bool {
+	if f.sensorId != 0 && uint(reading.SensorId) != f.sensorId {
+		return false
+	}
+
+	if f.group != "" && reading.GroupName != f.group {
+		return false
+	}
+
+	return len(f.metrics) == 0 || f.metrics[reading.Metric]
+}
+
+// subscription receives the readings matching its filter until readings is closed, err tells
+// why it was closed.
+type subscription struct {
+	filter   readingFilter
+	readings chan *storage.FeedReading
+	err      error
+}
+
+// readingFeed looks up the new readings while anyone is subscribed and passes them to the
+// subscribers, so every subscription of the instance shares the same queries. The readings are
+// saved by any instance, so they are polled rather than taken from the writers.
+type readingFeed struct {
+	storage  *storage.Storage
+	interval time.Duration
+
+	lock          sync.Mutex
+	subscriptions map[*subscription]struct{}
+	// stop ends the polling started for the current subscribers.
+	stop   context.CancelFunc
+	closed bool
+}
+
+func newReadingFeed(storage *storage.Storage, interval time.Duration) *readingFeed {
+	return &readingFeed{
+		storage:       storage,
+		interval:      interval,
+		subscriptions: make(map[*subscription]struct{}),
+	}
+}
+
+// subscribe starts passing the readings matching the filter, the polling starts with the first
+// subscription.
+func (f *readingFeed) subscribe(filter readingFilter) (*subscription, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.closed {
+		return nil, ErrFeedClosed
+	}
+
+	sub := &subscription{filter: filter, readings: make(chan *storage.FeedReading, subscriptionBuffer)}
+	f.subscriptions[sub] = struct{}{}
+
+	if f.stop == nil {
+		ctx, stop := context.WithCancel(context.Background())
+		f.stop = stop
+		go f.run(ctx)
+	}
+
+	return sub, nil
+}
+
+// unsubscribe stops passing the readings to the subscription, the polling stops with the last one.
+func (f *readingFeed) unsubscribe(sub *subscription) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.remove(sub, nil)
+	f.stopIdle()
+}
+
+// close ends all subscriptions, no new ones are accepted.
+func (f *readingFeed) close() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.closed = true
+	for sub := range f.subscriptions {
+		f.remove(sub, ErrFeedClosed)
+	}
+	f.stopIdle()
+}
+
+// publish passes the readings to the matching subscriptions unless the polling was stopped,
+// a subscription with the full buffer is dropped.
+func (f *readingFeed) publish(ctx context.Context, readings []*storage.FeedReading) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	for _, reading := range readings {
+		for sub := range f.subscriptions {
+			if !sub.filter.match(reading) {
+				continue
+			}
+
+			select {
+			case sub.readings <- reading:
+			default:
+				f.remove(sub, ErrSlowReceive)
+			}
+		}
+	}
+	f.stopIdle()
+}
+
+func (f *readingFeed) remove(sub *subscription, err error) {
+	if _, ok := f.subscriptions[sub]; !ok {
+		return
+	}
+
+	delete(f.subscriptions, sub)
+	sub.err = err
+	close(sub.readings)
+}
+
+func (f *readingFeed) stopIdle() {
+	if len(f.subscriptions) == 0 && f.stop != nil {
+		f.stop()
+		f.stop = nil
+	}
+}
+
+// run polls the readings saved after it started until ctx is done.
+func (f *readingFeed) run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	cursor, err := f.storage.GetReadingCursor(ctx)
+	started := err == nil
+	if err != nil && ctx.Err() == nil {
+		log.Printf("cannot find the latest readings: %s\n", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !started {
+				if cursor, err = f.storage.GetReadingCursor(ctx); err != nil {
+					if ctx.Err() == nil {
+						log.Printf("cannot find the latest readings: %s\n", err)
+					}
+					continue
+				}
+				started = true
+			}
+
+			if err = f.poll(ctx, &cursor); err != nil && ctx.Err() == nil {
+				log.Printf("cannot look up new readings: %s\n", err)
+			}
+		}
+	}
+}
+
+// poll publishes the readings saved after the cursor and moves the cursor after them.
+func (f *readingFeed) poll(ctx context.Context, cursor *storage.ReadingCursor) error {
+	for {
+		readings, err := f.storage.GetReadingsAfter(ctx, cursor, feedBatchSize)
+		f.publish(ctx, readings)
+		if err != nil {
+			return err
+		}
+
+		if len(readings) < feedBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestFeed returns a feed which does not poll the storage, the readings are published by the test.
+func newTestFeed() *readingFeed {
+	f := newReadingFeed(nil, time.Second)
+	f.stop = func() {}
+	return f
+}
+
+func feedReading(sensorId uint64, group, metric string) *storage.FeedReading {
+	return &storage.FeedReading{ReadingRecord: storage.ReadingRecord{
+		SensorId:  sensorId,
+		GroupName: group,
+		Metric:    metric,
+		CreatedAt: time.Now(),
+	}}
+}
+
+func TestReadingFilter(t *testing.T) {
+	reading := feedReading(3, "alpha", storage.TemperatureMetric)
+
+	assert.True(t, (&readingFilter{}).match(reading))
+	assert.True(t, (&readingFilter{sensorId: 3, group: "alpha"}).match(reading))
+	assert.False(t, (&readingFilter{sensorId: 4}).match(reading))
+	assert.False(t, (&readingFilter{group: "beta"}).match(reading))
+	assert.True(t, (&readingFilter{metrics: map[string]bool{storage.TemperatureMetric: true}}).match(reading))
+	assert.False(t, (&readingFilter{metrics: map[string]bool{storage.TransparencyMetric: true}}).match(reading))
+}
+
+func TestReadingFeed(t *testing.T) {
+	t.Run("Publish", func(t *testing.T) {
+		f := newTestFeed()
+		alpha, err := f.subscribe(readingFilter{group: "alpha"})
+		require.NoError(t, err)
+		all, err := f.subscribe(readingFilter{})
+		require.NoError(t, err)
+
+		f.publish(context.Background(), []*storage.FeedReading{
+			feedReading(1, "alpha", storage.TemperatureMetric),
+			feedReading(2, "beta", storage.TransparencyMetric),
+		})
+
+		assert.Len(t, alpha.readings, 1)
+		assert.Len(t, all.readings, 2)
+
+		f.unsubscribe(alpha)
+		assert.Equal(t, "alpha", (<-alpha.readings).GroupName, "the published readings are kept")
+		_, open := <-alpha.readings
+		assert.False(t, open)
+		assert.NoError(t, alpha.err)
+	})
+
+	t.Run("SlowSubscriber", func(t *testing.T) {
+		f := newTestFeed()
+		sub, err := f.subscribe(readingFilter{})
+		require.NoError(t, err)
+
+		readings := make([]*storage.FeedReading, subscriptionBuffer+1)
+		for i := range readings {
+			readings[i] = feedReading(1, "alpha", storage.TemperatureMetric)
+		}
+		f.publish(context.Background(), readings)
+
+		assert.Len(t, sub.readings, subscriptionBuffer)
+		assert.ErrorIs(t, sub.err, ErrSlowReceive)
+		assert.Empty(t, f.subscriptions)
+	})
+
+	t.Run("StoppedPolling", func(t *testing.T) {
+		f := newTestFeed()
+		sub, err := f.subscribe(readingFilter{})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		f.publish(ctx, []*storage.FeedReading{feedReading(1, "alpha", storage.TemperatureMetric)})
+
+		assert.Empty(t, sub.readings)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		f := newTestFeed()
+		sub, err := f.subscribe(readingFilter{})
+		require.NoError(t, err)
+
+		f.close()
+		_, open := <-sub.readings
+		assert.False(t, open)
+		assert.ErrorIs(t, sub.err, ErrFeedClosed)
+
+		_, err = f.subscribe(readingFilter{})
+		assert.ErrorIs(t, err, ErrFeedClosed)
+	})
+}
@@ -0,0 +1,84 @@
+// Package rpc serves the gRPC API, the same queries as the REST API and the subscriptions to the
+// new readings, from the same storage.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/api/sensorpb"
+	"github.com/jenyasd209/fake-sensors/src/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+const defaultPollInterval = time.Second
+
+type Server struct {
+	storage *storage.Storage
+	feed    *readingFeed
+	server  *grpc.Server
+}
+
+type ServerOption func(s *Server)
+
+// WithPollInterval sets how often the new readings are looked up while anyone is subscribed to them.
+func WithPollInterval(interval time.Duration) ServerOption {
+	return func(s *Server) {
+		if interval > 0 {
+			s.feed.interval = interval
+		}
+	}
+}
+
+func NewServer(storage *storage.Storage, opts ...ServerOption) *Server {
+	s := &Server{
+		storage: storage,
+		feed:    newReadingFeed(storage, defaultPollInterval),
+		server:  grpc.NewServer(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	sensorpb.RegisterSensorServiceServer(s.server, &sensorService{storage: storage, feed: s.feed})
+	reflection.Register(s.server)
+	return s
+}
+
+// Run serves the API until Shutdown is called, it returns nil after a shutdown.
+func (s *Server) Run(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	err = s.server.Serve(listener)
+	if errors.Is(err, grpc.ErrServerStopped) {
+		return nil
+	}
+
+	return err
+}
+
+// Shutdown ends the subscriptions, stops accepting connections and waits for the calls in
+// progress until ctx is done, then closes the remaining connections.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.feed.close()
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		s.server.GracefulStop()
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
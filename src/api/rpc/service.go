@@ -0,0 +1,249 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jenyasd209/fake-sensors/src/api/sensorpb"
+	"github.com/jenyasd209/fake-sensors/src/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// sensorService answers the calls of the gRPC API the way the routes answer the requests.
+type sensorService struct {
+	sensorpb.UnimplementedSensorServiceServer
+
+	storage *storage.Storage
+	feed    *readingFeed
+}
+
+func (s *sensorService) ListGroups(context.Context, *sensorpb.ListGroupsRequest) (*sensorpb.ListGroupsResponse, error) {
+	groupRecords, err := s.storage.GetAllGroups()
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	groups := make([]string, len(groupRecords))
+	for i, record := range groupRecords {
+		groups[i] = record.Name
+	}
+
+	return &sensorpb.ListGroupsResponse{Groups: groups}, nil
+}
+
+func (s *sensorService) GetGroupAverageTemperature(ctx context.Context, req *sensorpb.GroupRequest) (*sensorpb.AverageResponse, error) {
+	if req.GetGroup() == "" {
+		return nil, status.Error(codes.InvalidArgument, "group must not be empty")
+	}
+
+	avg, err := s.storage.GetAvgTemperature(ctx, req.GetGroup())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &sensorpb.AverageResponse{Average: avg}, nil
+}
+
+func (s *sensorService) GetGroupAverageTransparency(ctx context.Context, req *sensorpb.GroupRequest) (*sensorpb.AverageResponse, error) {
+	if req.GetGroup() == "" {
+		return nil, status.Error(codes.InvalidArgument, "group must not be empty")
+	}
+
+	avg, err := s.storage.GetAvgTransparency(ctx, req.GetGroup())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &sensorpb.AverageResponse{Average: float64(avg)}, nil
+}
+
+func (s *sensorService) GetGroupSpecies(ctx context.Context, req *sensorpb.GroupSpeciesRequest) (*sensorpb.SpeciesResponse, error) {
+	if req.GetGroup() == "" {
+		return nil, status.Error(codes.InvalidArgument, "group must not be empty")
+	}
+
+	opts, err := timeRange(req.GetFrom(), req.GetTill())
+	if err != nil {
+		return nil, err
+	}
+
+	fishes, err := s.storage.GetCurrentSpecies(ctx, req.GetGroup(), int(req.GetTop()), opts...)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	species := make([]*sensorpb.Species, len(fishes))
+	for i, fish := range fishes {
+		species[i] = &sensorpb.Species{Name: fish.Name, Count: fish.Count}
+	}
+
+	return &sensorpb.SpeciesResponse{Species: species}, nil
+}
+
+func (s *sensorService) GetRegionMinTemperature(ctx context.Context, req *sensorpb.RegionRequest) (*sensorpb.TemperatureResponse, error) {
+	minT, err := s.storage.GetMinTemperatureByRegion(ctx, region(req)...)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &sensorpb.TemperatureResponse{Temperature: minT}, nil
+}
+
+func (s *sensorService) GetRegionMaxTemperature(ctx context.Context, req *sensorpb.RegionRequest) (*sensorpb.TemperatureResponse, error) {
+	maxT, err := s.storage.GetMaxTemperatureByRegion(ctx, region(req)...)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &sensorpb.TemperatureResponse{Temperature: maxT}, nil
+}
+
+func (s *sensorService) GetSensorAverageTemperature(_ context.Context, req *sensorpb.SensorAverageRequest) (*sensorpb.AverageResponse, error) {
+	opts, err := timeRange(req.GetFrom(), req.GetTill())
+	if err != nil {
+		return nil, err
+	}
+
+	sensor, err := s.storage.GetSensor(req.GetSensor())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	avg, err := s.storage.GetSensorAvgTemperature(sensor.ID, opts...)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &sensorpb.AverageResponse{Average: avg}, nil
+}
+
+// SubscribeReadings streams the readings matching the request until the client cancels the call,
+// the server shuts down or the client receives slower than the readings are saved.
+func (s *sensorService) SubscribeReadings(req *sensorpb.SubscribeReadingsRequest, stream sensorpb.SensorService_SubscribeReadingsServer) error {
+	filter, err := s.readingFilter(req)
+	if err != nil {
+		return err
+	}
+
+	sub, err := s.feed.subscribe(filter)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer s.feed.unsubscribe(sub)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case reading, ok := <-sub.readings:
+			if !ok {
+				if sub.err == ErrSlowReceive {
+					return status.Error(codes.ResourceExhausted, sub.err.Error())
+				}
+				return status.Error(codes.Unavailable, sub.err.Error())
+			}
+
+			if err := stream.Send(readingMessage(reading)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *sensorService) readingFilter(req *sensorpb.SubscribeReadingsRequest) (readingFilter, error) {
+	var filter readingFilter
+
+	if req.GetSensor() != "" {
+		sensor, err := s.storage.GetSensor(req.GetSensor())
+		if err != nil {
+			return filter, statusError(err)
+		}
+		filter.sensorId = sensor.ID
+	}
+
+	if req.GetGroup() != "" {
+		group, err := s.storage.GetGroupByName(req.GetGroup())
+		if err != nil {
+			return filter, statusError(err)
+		}
+		filter.group = group.Name
+	}
+
+	if len(req.GetMetrics()) > 0 {
+		filter.metrics = make(map[string]bool, len(req.GetMetrics()))
+		for _, metric := range req.GetMetrics() {
+			if metric != storage.TemperatureMetric && metric != storage.TransparencyMetric {
+				return filter, status.Errorf(codes.InvalidArgument, "unknown metric %q", metric)
+			}
+			filter.metrics[metric] = true
+		}
+	}
+
+	return filter, nil
+}
+
+func readingMessage(reading *storage.FeedReading) *sensorpb.Reading {
+	var labels []string
+	if reading.Labels != "" {
+		labels = strings.Split(reading.Labels, ",")
+	}
+
+	return &sensorpb.Reading{
+		Sensor:    storage.CodeName(reading.GroupName, reading.IndexInGroup),
+		Group:     reading.GroupName,
+		Metric:    reading.Metric,
+		Value:     reading.Value,
+		TrueValue: reading.TrueValue,
+		Labels:    labels,
+		Time:      timestamppb.New(reading.CreatedAt),
+	}
+}
+
+// timeRange converts the time range of the request into the conditions, unset bounds are not applied.
+func timeRange(from, till *timestamppb.Timestamp) ([]storage.ConditionOption, error) {
+	opts := make([]storage.ConditionOption, 0, 2)
+
+	if from != nil {
+		if err := from.CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "from: "+err.Error())
+		}
+		opts = append(opts, storage.WithCreatedFrom(from.AsTime()))
+	}
+
+	if till != nil {
+		if err := till.CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "till: "+err.Error())
+		}
+		opts = append(opts, storage.WithCreatedTill(till.AsTime()))
+	}
+
+	return opts, nil
+}
+
+// region converts the bounds of the request into the coordinate options, unset bounds are not applied.
+func region(req *sensorpb.RegionRequest) []storage.CoordinateOption {
+	opts := make([]storage.CoordinateOption, 0, 6)
+
+	if req.XMin != nil {
+		opts = append(opts, storage.WithXMin(req.GetXMin()))
+	}
+	if req.XMax != nil {
+		opts = append(opts, storage.WithXMax(req.GetXMax()))
+	}
+	if req.YMin != nil {
+		opts = append(opts, storage.WithYMin(req.GetYMin()))
+	}
+	if req.YMax != nil {
+		opts = append(opts, storage.WithYMax(req.GetYMax()))
+	}
+	if req.ZMin != nil {
+		opts = append(opts, storage.WithZMin(req.GetZMin()))
+	}
+	if req.ZMax != nil {
+		opts = append(opts, storage.WithZMax(req.GetZMax()))
+	}
+
+	return opts
+}
@@ -0,0 +1,853 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: sensor.proto
+
+package sensorpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListGroupsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupsRequest) Reset() {
+	*x = ListGroupsRequest{}
+	mi := &file_sensor_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupsRequest) ProtoMessage() {}
+
+func (x *ListGroupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensor_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupsRequest.ProtoReflect.Descriptor instead.
+func (*ListGroupsRequest) Descriptor() ([]byte, []int) {
+	return file_sensor_proto_rawDescGZIP(), []int{0}
+}
+
+type ListGroupsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []string               `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupsResponse) Reset() {
+	*x = ListGroupsResponse{}
+	mi := &file_sensor_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupsResponse) ProtoMessage() {}
+
+func (x *ListGroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sensor_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupsResponse.ProtoReflect.Descriptor instead.
+func (*ListGroupsResponse) Descriptor() ([]byte, []int) {
+	return file_sensor_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListGroupsResponse) GetGroups() []string {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type GroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupRequest) Reset() {
+	*x = GroupRequest{}
+	mi := &file_sensor_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupRequest) ProtoMessage() {}
+
+func (x *GroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensor_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupRequest.ProtoReflect.Descriptor instead.
+func (*GroupRequest) Descriptor() ([]byte, []int) {
+	return file_sensor_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GroupRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+type GroupSpeciesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Group string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	// top limits the species to the most numerous ones, all species are returned if zero.
+	Top           uint32                 `protobuf:"varint,2,opt,name=top,proto3" json:"top,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	Till          *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=till,proto3" json:"till,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupSpeciesRequest) Reset() {
+	*x = GroupSpeciesRequest{}
+	mi := &file_sensor_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupSpeciesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupSpeciesRequest) ProtoMessage() {}
+
+func (x *GroupSpeciesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensor_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupSpeciesRequest.ProtoReflect.Descriptor instead.
+func (*GroupSpeciesRequest) Descriptor() ([]byte, []int) {
+	return file_sensor_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GroupSpeciesRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *GroupSpeciesRequest) GetTop() uint32 {
+	if x != nil {
+		return x.Top
+	}
+	return 0
+}
+
+func (x *GroupSpeciesRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GroupSpeciesRequest) GetTill() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Till
+	}
+	return nil
+}
+
+type Species struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Count         uint64                 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Species) Reset() {
+	*x = Species{}
+	mi := &file_sensor_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Species) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Species) ProtoMessage() {}
+
+func (x *Species) ProtoReflect() protoreflect.Message {
+	mi := &file_sensor_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Species.ProtoReflect.Descriptor instead.
+func (*Species) Descriptor() ([]byte, []int) {
+	return file_sensor_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Species) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Species) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type SpeciesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Species       []*Species             `protobuf:"bytes,1,rep,name=species,proto3" json:"species,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpeciesResponse) Reset() {
+	*x = SpeciesResponse{}
+	mi := &file_sensor_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpeciesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpeciesResponse) ProtoMessage() {}
+
+func (x *SpeciesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sensor_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpeciesResponse.ProtoReflect.Descriptor instead.
+func (*SpeciesResponse) Descriptor() ([]byte, []int) {
+	return file_sensor_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SpeciesResponse) GetSpecies() []*Species {
+	if x != nil {
+		return x.Species
+	}
+	return nil
+}
+
+// RegionRequest bounds the region by coordinates, unset bounds are not applied.
+type RegionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	XMin          *float64               `protobuf:"fixed64,1,opt,name=x_min,json=xMin,proto3,oneof" json:"x_min,omitempty"`
+	XMax          *float64               `protobuf:"fixed64,2,opt,name=x_max,json=xMax,proto3,oneof" json:"x_max,omitempty"`
+	YMin          *float64               `protobuf:"fixed64,3,opt,name=y_min,json=yMin,proto3,oneof" json:"y_min,omitempty"`
+	YMax          *float64               `protobuf:"fixed64,4,opt,name=y_max,json=yMax,proto3,oneof" json:"y_max,omitempty"`
+	ZMin          *float64               `protobuf:"fixed64,5,opt,name=z_min,json=zMin,proto3,oneof" json:"z_min,omitempty"`
+	ZMax          *float64               `protobuf:"fixed64,6,opt,name=z_max,json=zMax,proto3,oneof" json:"z_max,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegionRequest) Reset() {
+	*x = RegionRequest{}
+	mi := &file_sensor_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegionRequest) ProtoMessage() {}
+
+func (x *RegionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensor_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegionRequest.ProtoReflect.Descriptor instead.
+func (*RegionRequest) Descriptor() ([]byte, []int) {
+	return file_sensor_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RegionRequest) GetXMin() float64 {
+	if x != nil && x.XMin != nil {
+		return *x.XMin
+	}
+	return 0
+}
+
+func (x *RegionRequest) GetXMax() float64 {
+	if x != nil && x.XMax != nil {
+		return *x.XMax
+	}
+	return 0
+}
+
+func (x *RegionRequest) GetYMin() float64 {
+	if x != nil && x.YMin != nil {
+		return *x.YMin
+	}
+	return 0
+}
+
+func (x *RegionRequest) GetYMax() float64 {
+	if x != nil && x.YMax != nil {
+		return *x.YMax
+	}
+	return 0
+}
+
+func (x *RegionRequest) GetZMin() float64 {
+	if x != nil && x.ZMin != nil {
+		return *x.ZMin
+	}
+	return 0
+}
+
+func (x *RegionRequest) GetZMax() float64 {
+	if x != nil && x.ZMax != nil {
+		return *x.ZMax
+	}
+	return 0
+}
+
+type TemperatureResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Temperature   float64                `protobuf:"fixed64,1,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TemperatureResponse) Reset() {
+	*x = TemperatureResponse{}
+	mi := &file_sensor_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TemperatureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TemperatureResponse) ProtoMessage() {}
+
+func (x *TemperatureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sensor_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TemperatureResponse.ProtoReflect.Descriptor instead.
+func (*TemperatureResponse) Descriptor() ([]byte, []int) {
+	return file_sensor_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TemperatureResponse) GetTemperature() float64 {
+	if x != nil {
+		return x.Temperature
+	}
+	return 0
+}
+
+type SensorAverageRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// sensor is the code name (like alpha3) or the UUID of the sensor.
+	Sensor        string                 `protobuf:"bytes,1,opt,name=sensor,proto3" json:"sensor,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	Till          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=till,proto3" json:"till,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SensorAverageRequest) Reset() {
+	*x = SensorAverageRequest{}
+	mi := &file_sensor_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SensorAverageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SensorAverageRequest) ProtoMessage() {}
+
+func (x *SensorAverageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensor_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SensorAverageRequest.ProtoReflect.Descriptor instead.
+func (*SensorAverageRequest) Descriptor() ([]byte, []int) {
+	return file_sensor_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SensorAverageRequest) GetSensor() string {
+	if x != nil {
+		return x.Sensor
+	}
+	return ""
+}
+
+func (x *SensorAverageRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *SensorAverageRequest) GetTill() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Till
+	}
+	return nil
+}
+
+type AverageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Average       float64                `protobuf:"fixed64,1,opt,name=average,proto3" json:"average,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AverageResponse) Reset() {
+	*x = AverageResponse{}
+	mi := &file_sensor_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AverageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AverageResponse) ProtoMessage() {}
+
+func (x *AverageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sensor_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AverageResponse.ProtoReflect.Descriptor instead.
+func (*AverageResponse) Descriptor() ([]byte, []int) {
+	return file_sensor_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AverageResponse) GetAverage() float64 {
+	if x != nil {
+		return x.Average
+	}
+	return 0
+}
+
+// SubscribeReadingsRequest narrows down the streamed readings, empty fields are not applied.
+type SubscribeReadingsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Group string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	// sensor is the code name (like alpha3) or the UUID of the sensor.
+	Sensor string `protobuf:"bytes,2,opt,name=sensor,proto3" json:"sensor,omitempty"`
+	// metrics are temperature and transparency.
+	Metrics       []string `protobuf:"bytes,3,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeReadingsRequest) Reset() {
+	*x = SubscribeReadingsRequest{}
+	mi := &file_sensor_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeReadingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeReadingsRequest) ProtoMessage() {}
+
+func (x *SubscribeReadingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sensor_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeReadingsRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeReadingsRequest) Descriptor() ([]byte, []int) {
+	return file_sensor_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SubscribeReadingsRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *SubscribeReadingsRequest) GetSensor() string {
+	if x != nil {
+		return x.Sensor
+	}
+	return ""
+}
+
+func (x *SubscribeReadingsRequest) GetMetrics() []string {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+type Reading struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Sensor string                 `protobuf:"bytes,1,opt,name=sensor,proto3" json:"sensor,omitempty"`
+	Group  string                 `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	Metric string                 `protobuf:"bytes,3,opt,name=metric,proto3" json:"metric,omitempty"`
+	Value  float64                `protobuf:"fixed64,4,opt,name=value,proto3" json:"value,omitempty"`
+	// true_value is the value before the calibration errors, unset unless the reading was generated.
+	TrueValue *float64 `protobuf:"fixed64,5,opt,name=true_value,json=trueValue,proto3,oneof" json:"true_value,omitempty"`
+	// labels are the kinds of the anomalies the reading is affected by.
+	Labels        []string               `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reading) Reset() {
+	*x = Reading{}
+	mi := &file_sensor_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reading) ProtoMessage() {}
+
+func (x *Reading) ProtoReflect() protoreflect.Message {
+	mi := &file_sensor_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reading.ProtoReflect.Descriptor instead.
+func (*Reading) Descriptor() ([]byte, []int) {
+	return file_sensor_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Reading) GetSensor() string {
+	if x != nil {
+		return x.Sensor
+	}
+	return ""
+}
+
+func (x *Reading) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *Reading) GetMetric() string {
+	if x != nil {
+		return x.Metric
+	}
+	return ""
+}
+
+func (x *Reading) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Reading) GetTrueValue() float64 {
+	if x != nil && x.TrueValue != nil {
+		return *x.TrueValue
+	}
+	return 0
+}
+
+func (x *Reading) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Reading) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+var File_sensor_proto protoreflect.FileDescriptor
+
+const file_sensor_proto_rawDesc = "" +
+	"\n" +
+	"\fsensor.proto\x12\x0efakesensors.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x13\n" +
+	"\x11ListGroupsRequest\",\n" +
+	"\x12ListGroupsResponse\x12\x16\n" +
+	"\x06groups\x18\x01 \x03(\tR\x06groups\"$\n" +
+	"\fGroupRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\"\x9d\x01\n" +
+	"\x13GroupSpeciesRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x10\n" +
+	"\x03top\x18\x02 \x01(\rR\x03top\x12.\n" +
+	"\x04from\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12.\n" +
+	"\x04till\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04till\"3\n" +
+	"\aSpecies\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x04R\x05count\"D\n" +
+	"\x0fSpeciesResponse\x121\n" +
+	"\aspecies\x18\x01 \x03(\v2\x17.fakesensors.v1.SpeciesR\aspecies\"\xe7\x01\n" +
+	"\rRegionRequest\x12\x18\n" +
+	"\x05x_min\x18\x01 \x01(\x01H\x00R\x04xMin\x88\x01\x01\x12\x18\n" +
+	"\x05x_max\x18\x02 \x01(\x01H\x01R\x04xMax\x88\x01\x01\x12\x18\n" +
+	"\x05y_min\x18\x03 \x01(\x01H\x02R\x04yMin\x88\x01\x01\x12\x18\n" +
+	"\x05y_max\x18\x04 \x01(\x01H\x03R\x04yMax\x88\x01\x01\x12\x18\n" +
+	"\x05z_min\x18\x05 \x01(\x01H\x04R\x04zMin\x88\x01\x01\x12\x18\n" +
+	"\x05z_max\x18\x06 \x01(\x01H\x05R\x04zMax\x88\x01\x01B\b\n" +
+	"\x06_x_minB\b\n" +
+	"\x06_x_maxB\b\n" +
+	"\x06_y_minB\b\n" +
+	"\x06_y_maxB\b\n" +
+	"\x06_z_minB\b\n" +
+	"\x06_z_max\"7\n" +
+	"\x13TemperatureResponse\x12 \n" +
+	"\vtemperature\x18\x01 \x01(\x01R\vtemperature\"\x8e\x01\n" +
+	"\x14SensorAverageRequest\x12\x16\n" +
+	"\x06sensor\x18\x01 \x01(\tR\x06sensor\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12.\n" +
+	"\x04till\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04till\"+\n" +
+	"\x0fAverageResponse\x12\x18\n" +
+	"\aaverage\x18\x01 \x01(\x01R\aaverage\"b\n" +
+	"\x18SubscribeReadingsRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x16\n" +
+	"\x06sensor\x18\x02 \x01(\tR\x06sensor\x12\x18\n" +
+	"\ametrics\x18\x03 \x03(\tR\ametrics\"\xe0\x01\n" +
+	"\aReading\x12\x16\n" +
+	"\x06sensor\x18\x01 \x01(\tR\x06sensor\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\x12\x16\n" +
+	"\x06metric\x18\x03 \x01(\tR\x06metric\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\x01R\x05value\x12\"\n" +
+	"\n" +
+	"true_value\x18\x05 \x01(\x01H\x00R\ttrueValue\x88\x01\x01\x12\x16\n" +
+	"\x06labels\x18\x06 \x03(\tR\x06labels\x12.\n" +
+	"\x04time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x04timeB\r\n" +
+	"\v_true_value2\xf6\x05\n" +
+	"\rSensorService\x12S\n" +
+	"\n" +
+	"ListGroups\x12!.fakesensors.v1.ListGroupsRequest\x1a\".fakesensors.v1.ListGroupsResponse\x12[\n" +
+	"\x1aGetGroupAverageTemperature\x12\x1c.fakesensors.v1.GroupRequest\x1a\x1f.fakesensors.v1.AverageResponse\x12\\\n" +
+	"\x1bGetGroupAverageTransparency\x12\x1c.fakesensors.v1.GroupRequest\x1a\x1f.fakesensors.v1.AverageResponse\x12W\n" +
+	"\x0fGetGroupSpecies\x12#.fakesensors.v1.GroupSpeciesRequest\x1a\x1f.fakesensors.v1.SpeciesResponse\x12]\n" +
+	"\x17GetRegionMinTemperature\x12\x1d.fakesensors.v1.RegionRequest\x1a#.fakesensors.v1.TemperatureResponse\x12]\n" +
+	"\x17GetRegionMaxTemperature\x12\x1d.fakesensors.v1.RegionRequest\x1a#.fakesensors.v1.TemperatureResponse\x12d\n" +
+	"\x1bGetSensorAverageTemperature\x12$.fakesensors.v1.SensorAverageRequest\x1a\x1f.fakesensors.v1.AverageResponse\x12X\n" +
+	"\x11SubscribeReadings\x12(.fakesensors.v1.SubscribeReadingsRequest\x1a\x17.fakesensors.v1.Reading0\x01B5Z3github.com/jenyasd209/fake-sensors/src/api/sensorpbb\x06proto3"
+
+var (
+	file_sensor_proto_rawDescOnce sync.Once
+	file_sensor_proto_rawDescData []byte
+)
+
+func file_sensor_proto_rawDescGZIP() []byte {
+	file_sensor_proto_rawDescOnce.Do(func() {
+		file_sensor_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sensor_proto_rawDesc), len(file_sensor_proto_rawDesc)))
+	})
+	return file_sensor_proto_rawDescData
+}
+
+var file_sensor_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_sensor_proto_goTypes = []any{
+	(*ListGroupsRequest)(nil),        // 0: fakesensors.v1.ListGroupsRequest
+	(*ListGroupsResponse)(nil),       // 1: fakesensors.v1.ListGroupsResponse
+	(*GroupRequest)(nil),             // 2: fakesensors.v1.GroupRequest
+	(*GroupSpeciesRequest)(nil),      // 3: fakesensors.v1.GroupSpeciesRequest
+	(*Species)(nil),                  // 4: fakesensors.v1.Species
+	(*SpeciesResponse)(nil),          // 5: fakesensors.v1.SpeciesResponse
+	(*RegionRequest)(nil),            // 6: fakesensors.v1.RegionRequest
+	(*TemperatureResponse)(nil),      // 7: fakesensors.v1.TemperatureResponse
+	(*SensorAverageRequest)(nil),     // 8: fakesensors.v1.SensorAverageRequest
+	(*AverageResponse)(nil),          // 9: fakesensors.v1.AverageResponse
+	(*SubscribeReadingsRequest)(nil), // 10: fakesensors.v1.SubscribeReadingsRequest
+	(*Reading)(nil),                  // 11: fakesensors.v1.Reading
+	(*timestamppb.Timestamp)(nil),    // 12: google.protobuf.Timestamp
+}
+var file_sensor_proto_depIdxs = []int32{
+	12, // 0: fakesensors.v1.GroupSpeciesRequest.from:type_name -> google.protobuf.Timestamp
+	12, // 1: fakesensors.v1.GroupSpeciesRequest.till:type_name -> google.protobuf.Timestamp
+	4,  // 2: fakesensors.v1.SpeciesResponse.species:type_name -> fakesensors.v1.Species
+	12, // 3: fakesensors.v1.SensorAverageRequest.from:type_name -> google.protobuf.Timestamp
+	12, // 4: fakesensors.v1.SensorAverageRequest.till:type_name -> google.protobuf.Timestamp
+	12, // 5: fakesensors.v1.Reading.time:type_name -> google.protobuf.Timestamp
+	0,  // 6: fakesensors.v1.SensorService.ListGroups:input_type -> fakesensors.v1.ListGroupsRequest
+	2,  // 7: fakesensors.v1.SensorService.GetGroupAverageTemperature:input_type -> fakesensors.v1.GroupRequest
+	2,  // 8: fakesensors.v1.SensorService.GetGroupAverageTransparency:input_type -> fakesensors.v1.GroupRequest
+	3,  // 9: fakesensors.v1.SensorService.GetGroupSpecies:input_type -> fakesensors.v1.GroupSpeciesRequest
+	6,  // 10: fakesensors.v1.SensorService.GetRegionMinTemperature:input_type -> fakesensors.v1.RegionRequest
+	6,  // 11: fakesensors.v1.SensorService.GetRegionMaxTemperature:input_type -> fakesensors.v1.RegionRequest
+	8,  // 12: fakesensors.v1.SensorService.GetSensorAverageTemperature:input_type -> fakesensors.v1.SensorAverageRequest
+	10, // 13: fakesensors.v1.SensorService.SubscribeReadings:input_type -> fakesensors.v1.SubscribeReadingsRequest
+	1,  // 14: fakesensors.v1.SensorService.ListGroups:output_type -> fakesensors.v1.ListGroupsResponse
+	9,  // 15: fakesensors.v1.SensorService.GetGroupAverageTemperature:output_type -> fakesensors.v1.AverageResponse
+	9,  // 16: fakesensors.v1.SensorService.GetGroupAverageTransparency:output_type -> fakesensors.v1.AverageResponse
+	5,  // 17: fakesensors.v1.SensorService.GetGroupSpecies:output_type -> fakesensors.v1.SpeciesResponse
+	7,  // 18: fakesensors.v1.SensorService.GetRegionMinTemperature:output_type -> fakesensors.v1.TemperatureResponse
+	7,  // 19: fakesensors.v1.SensorService.GetRegionMaxTemperature:output_type -> fakesensors.v1.TemperatureResponse
+	9,  // 20: fakesensors.v1.SensorService.GetSensorAverageTemperature:output_type -> fakesensors.v1.AverageResponse
+	11, // 21: fakesensors.v1.SensorService.SubscribeReadings:output_type -> fakesensors.v1.Reading
+	14, // [14:22] is the sub-list for method output_type
+	6,  // [6:14] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_sensor_proto_init() }
+func file_sensor_proto_init() {
+	if File_sensor_proto != nil {
+		return
+	}
+	file_sensor_proto_msgTypes[6].OneofWrappers = []any{}
+	file_sensor_proto_msgTypes[11].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sensor_proto_rawDesc), len(file_sensor_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_sensor_proto_goTypes,
+		DependencyIndexes: file_sensor_proto_depIdxs,
+		MessageInfos:      file_sensor_proto_msgTypes,
+	}.Build()
+	File_sensor_proto = out.File
+	file_sensor_proto_goTypes = nil
+	file_sensor_proto_depIdxs = nil
+}
@@ -0,0 +1,417 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: sensor.proto
+
+package sensorpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SensorService_ListGroups_FullMethodName                  = "/fakesensors.v1.SensorService/ListGroups"
+	SensorService_GetGroupAverageTemperature_FullMethodName  = "/fakesensors.v1.SensorService/GetGroupAverageTemperature"
+	SensorService_GetGroupAverageTransparency_FullMethodName = "/fakesensors.v1.SensorService/GetGroupAverageTransparency"
+	SensorService_GetGroupSpecies_FullMethodName             = "/fakesensors.v1.SensorService/GetGroupSpecies"
+	SensorService_GetRegionMinTemperature_FullMethodName     = "/fakesensors.v1.SensorService/GetRegionMinTemperature"
+	SensorService_GetRegionMaxTemperature_FullMethodName     = "/fakesensors.v1.SensorService/GetRegionMaxTemperature"
+	SensorService_GetSensorAverageTemperature_FullMethodName = "/fakesensors.v1.SensorService/GetSensorAverageTemperature"
+	SensorService_SubscribeReadings_FullMethodName           = "/fakesensors.v1.SensorService/SubscribeReadings"
+)
+
+// SensorServiceClient is the client API for SensorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SensorService serves the same queries as the REST API and streams the new readings.
+type SensorServiceClient interface {
+	// ListGroups returns the names of all groups.
+	ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (*ListGroupsResponse, error)
+	// GetGroupAverageTemperature returns the current average temperature of the group.
+	GetGroupAverageTemperature(ctx context.Context, in *GroupRequest, opts ...grpc.CallOption) (*AverageResponse, error)
+	// GetGroupAverageTransparency returns the current average transparency of the group.
+	GetGroupAverageTransparency(ctx context.Context, in *GroupRequest, opts ...grpc.CallOption) (*AverageResponse, error)
+	// GetGroupSpecies returns the species currently detected by the group, the most numerous first.
+	GetGroupSpecies(ctx context.Context, in *GroupSpeciesRequest, opts ...grpc.CallOption) (*SpeciesResponse, error)
+	// GetRegionMinTemperature returns the minimal current temperature in the region.
+	GetRegionMinTemperature(ctx context.Context, in *RegionRequest, opts ...grpc.CallOption) (*TemperatureResponse, error)
+	// GetRegionMaxTemperature returns the maximal current temperature in the region.
+	GetRegionMaxTemperature(ctx context.Context, in *RegionRequest, opts ...grpc.CallOption) (*TemperatureResponse, error)
+	// GetSensorAverageTemperature returns the average temperature detected by the sensor.
+	GetSensorAverageTemperature(ctx context.Context, in *SensorAverageRequest, opts ...grpc.CallOption) (*AverageResponse, error)
+	// SubscribeReadings streams the readings saved after the subscription until it is cancelled.
+	// The readings of every metric come in the order they are saved, so a reading committed late
+	// comes after the ones committed before it. The stream is not ordered by the reading time,
+	// neither within a metric nor across the metrics.
+	SubscribeReadings(ctx context.Context, in *SubscribeReadingsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Reading], error)
+}
+
+type sensorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSensorServiceClient(cc grpc.ClientConnInterface) SensorServiceClient {
+	return &sensorServiceClient{cc}
+}
+
+func (c *sensorServiceClient) ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (*ListGroupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListGroupsResponse)
+	err := c.cc.Invoke(ctx, SensorService_ListGroups_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorServiceClient) GetGroupAverageTemperature(ctx context.Context, in *GroupRequest, opts ...grpc.CallOption) (*AverageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AverageResponse)
+	err := c.cc.Invoke(ctx, SensorService_GetGroupAverageTemperature_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorServiceClient) GetGroupAverageTransparency(ctx context.Context, in *GroupRequest, opts ...grpc.CallOption) (*AverageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AverageResponse)
+	err := c.cc.Invoke(ctx, SensorService_GetGroupAverageTransparency_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorServiceClient) GetGroupSpecies(ctx context.Context, in *GroupSpeciesRequest, opts ...grpc.CallOption) (*SpeciesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SpeciesResponse)
+	err := c.cc.Invoke(ctx, SensorService_GetGroupSpecies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorServiceClient) GetRegionMinTemperature(ctx context.Context, in *RegionRequest, opts ...grpc.CallOption) (*TemperatureResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TemperatureResponse)
+	err := c.cc.Invoke(ctx, SensorService_GetRegionMinTemperature_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorServiceClient) GetRegionMaxTemperature(ctx context.Context, in *RegionRequest, opts ...grpc.CallOption) (*TemperatureResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TemperatureResponse)
+	err := c.cc.Invoke(ctx, SensorService_GetRegionMaxTemperature_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorServiceClient) GetSensorAverageTemperature(ctx context.Context, in *SensorAverageRequest, opts ...grpc.CallOption) (*AverageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AverageResponse)
+	err := c.cc.Invoke(ctx, SensorService_GetSensorAverageTemperature_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorServiceClient) SubscribeReadings(ctx context.Context, in *SubscribeReadingsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Reading], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SensorService_ServiceDesc.Streams[0], SensorService_SubscribeReadings_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeReadingsRequest, Reading]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SensorService_SubscribeReadingsClient = grpc.ServerStreamingClient[Reading]
+
+// SensorServiceServer is the server API for SensorService service.
+// All implementations must embed UnimplementedSensorServiceServer
+// for forward compatibility.
+//
+// SensorService serves the same queries as the REST API and streams the new readings.
+type SensorServiceServer interface {
+	// ListGroups returns the names of all groups.
+	ListGroups(context.Context, *ListGroupsRequest) (*ListGroupsResponse, error)
+	// GetGroupAverageTemperature returns the current average temperature of the group.
+	GetGroupAverageTemperature(context.Context, *GroupRequest) (*AverageResponse, error)
+	// GetGroupAverageTransparency returns the current average transparency of the group.
+	GetGroupAverageTransparency(context.Context, *GroupRequest) (*AverageResponse, error)
+	// GetGroupSpecies returns the species currently detected by the group, the most numerous first.
+	GetGroupSpecies(context.Context, *GroupSpeciesRequest) (*SpeciesResponse, error)
+	// GetRegionMinTemperature returns the minimal current temperature in the region.
+	GetRegionMinTemperature(context.Context, *RegionRequest) (*TemperatureResponse, error)
+	// GetRegionMaxTemperature returns the maximal current temperature in the region.
+	GetRegionMaxTemperature(context.Context, *RegionRequest) (*TemperatureResponse, error)
+	// GetSensorAverageTemperature returns the average temperature detected by the sensor.
+	GetSensorAverageTemperature(context.Context, *SensorAverageRequest) (*AverageResponse, error)
+	// SubscribeReadings streams the readings saved after the subscription until it is cancelled.
+	// The readings of every metric come in the order they are saved, so a reading committed late
+	// comes after the ones committed before it. The stream is not ordered by the reading time,
+	// neither within a metric nor across the metrics.
+	SubscribeReadings(*SubscribeReadingsRequest, grpc.ServerStreamingServer[Reading]) error
+	mustEmbedUnimplementedSensorServiceServer()
+}
+
+// UnimplementedSensorServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSensorServiceServer struct{}
+
+func (UnimplementedSensorServiceServer) ListGroups(context.Context, *ListGroupsRequest) (*ListGroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGroups not implemented")
+}
+func (UnimplementedSensorServiceServer) GetGroupAverageTemperature(context.Context, *GroupRequest) (*AverageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGroupAverageTemperature not implemented")
+}
+func (UnimplementedSensorServiceServer) GetGroupAverageTransparency(context.Context, *GroupRequest) (*AverageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGroupAverageTransparency not implemented")
+}
+func (UnimplementedSensorServiceServer) GetGroupSpecies(context.Context, *GroupSpeciesRequest) (*SpeciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGroupSpecies not implemented")
+}
+func (UnimplementedSensorServiceServer) GetRegionMinTemperature(context.Context, *RegionRequest) (*TemperatureResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRegionMinTemperature not implemented")
+}
+func (UnimplementedSensorServiceServer) GetRegionMaxTemperature(context.Context, *RegionRequest) (*TemperatureResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRegionMaxTemperature not implemented")
+}
+func (UnimplementedSensorServiceServer) GetSensorAverageTemperature(context.Context, *SensorAverageRequest) (*AverageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSensorAverageTemperature not implemented")
+}
+func (UnimplementedSensorServiceServer) SubscribeReadings(*SubscribeReadingsRequest, grpc.ServerStreamingServer[Reading]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeReadings not implemented")
+}
+func (UnimplementedSensorServiceServer) mustEmbedUnimplementedSensorServiceServer() {}
+func (UnimplementedSensorServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeSensorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SensorServiceServer will
+// result in compilation errors.
+type UnsafeSensorServiceServer interface {
+	mustEmbedUnimplementedSensorServiceServer()
+}
+
+func RegisterSensorServiceServer(s grpc.ServiceRegistrar, srv SensorServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSensorServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SensorService_ServiceDesc, srv)
+}
+
+func _SensorService_ListGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorServiceServer).ListGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SensorService_ListGroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorServiceServer).ListGroups(ctx, req.(*ListGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SensorService_GetGroupAverageTemperature_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorServiceServer).GetGroupAverageTemperature(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SensorService_GetGroupAverageTemperature_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorServiceServer).GetGroupAverageTemperature(ctx, req.(*GroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SensorService_GetGroupAverageTransparency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorServiceServer).GetGroupAverageTransparency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SensorService_GetGroupAverageTransparency_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorServiceServer).GetGroupAverageTransparency(ctx, req.(*GroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SensorService_GetGroupSpecies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupSpeciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorServiceServer).GetGroupSpecies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SensorService_GetGroupSpecies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorServiceServer).GetGroupSpecies(ctx, req.(*GroupSpeciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SensorService_GetRegionMinTemperature_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorServiceServer).GetRegionMinTemperature(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SensorService_GetRegionMinTemperature_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorServiceServer).GetRegionMinTemperature(ctx, req.(*RegionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SensorService_GetRegionMaxTemperature_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorServiceServer).GetRegionMaxTemperature(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SensorService_GetRegionMaxTemperature_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorServiceServer).GetRegionMaxTemperature(ctx, req.(*RegionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SensorService_GetSensorAverageTemperature_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SensorAverageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorServiceServer).GetSensorAverageTemperature(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SensorService_GetSensorAverageTemperature_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorServiceServer).GetSensorAverageTemperature(ctx, req.(*SensorAverageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SensorService_SubscribeReadings_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeReadingsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SensorServiceServer).SubscribeReadings(m, &grpc.GenericServerStream[SubscribeReadingsRequest, Reading]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SensorService_SubscribeReadingsServer = grpc.ServerStreamingServer[Reading]
+
+// SensorService_ServiceDesc is the grpc.ServiceDesc for SensorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SensorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fakesensors.v1.SensorService",
+	HandlerType: (*SensorServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListGroups",
+			Handler:    _SensorService_ListGroups_Handler,
+		},
+		{
+			MethodName: "GetGroupAverageTemperature",
+			Handler:    _SensorService_GetGroupAverageTemperature_Handler,
+		},
+		{
+			MethodName: "GetGroupAverageTransparency",
+			Handler:    _SensorService_GetGroupAverageTransparency_Handler,
+		},
+		{
+			MethodName: "GetGroupSpecies",
+			Handler:    _SensorService_GetGroupSpecies_Handler,
+		},
+		{
+			MethodName: "GetRegionMinTemperature",
+			Handler:    _SensorService_GetRegionMinTemperature_Handler,
+		},
+		{
+			MethodName: "GetRegionMaxTemperature",
+			Handler:    _SensorService_GetRegionMaxTemperature_Handler,
+		},
+		{
+			MethodName: "GetSensorAverageTemperature",
+			Handler:    _SensorService_GetSensorAverageTemperature_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeReadings",
+			Handler:       _SensorService_SubscribeReadings_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sensor.proto",
+}
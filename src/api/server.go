@@ -1,18 +1,54 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
 	"github.com/jenyasd209/fake-sensors/src/api/routes"
 	"github.com/jenyasd209/fake-sensors/src/storage"
 )
 
 type Server struct {
 	router *routes.Router
+
+	lock     sync.Mutex
+	server   *http.Server
+	shutdown bool
 }
 
-func DefaultApiServer(storage *storage.Storage) *Server {
-	return &Server{router: routes.NewRouter(storage)}
+func DefaultApiServer(storage *storage.Storage, opts ...routes.RouterOption) *Server {
+	return &Server{router: routes.NewRouter(storage, opts...)}
 }
 
+// Run serves the API until Shutdown is called, it returns nil after a shutdown.
 func (s *Server) Run(addr string) error {
-	return s.router.Run(addr)
+	s.lock.Lock()
+	if s.shutdown {
+		s.lock.Unlock()
+		return nil
+	}
+	s.server = &http.Server{Addr: addr, Handler: s.router.Handler()}
+	s.lock.Unlock()
+
+	err := s.server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// Shutdown stops accepting connections and waits for the requests in progress until ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.shutdown = true
+	if s.server == nil {
+		return nil
+	}
+
+	return s.server.Shutdown(ctx)
 }
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jenyasd209/fake-sensors/src/config"
+)
+
+var ErrUnknownCommand = errors.New("unknown command")
+
+// runConfig handles the config commands, print writes the effective configuration loaded
+// from the file, the environment and the flags.
+func runConfig(_ context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return fmt.Errorf("%w: config commands: print", ErrUnknownCommand)
+	}
+
+	cfg, err := config.Load(flag.NewFlagSet("config print", flag.ExitOnError), args[1:])
+	if err != nil {
+		return err
+	}
+
+	return cfg.Print(os.Stdout)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/config"
+	"github.com/jenyasd209/fake-sensors/src/service"
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+var ErrNotConfirmed = errors.New("reset deletes all data, confirm it with -yes")
+
+const migrateCommands = "migrate commands: up, down, status"
+
+// runMigrate applies or rolls back the schema migrations, up is the default command.
+func runMigrate(ctx context.Context, args []string) error {
+	name := "up"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if name != "up" && name != "down" && name != "status" {
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, migrateCommands)
+	}
+
+	flags := flag.NewFlagSet("migrate "+name, flag.ExitOnError)
+	to := flags.Int("to", -1, "target version, up applies all pending and down rolls back the last one by default")
+
+	cfg, err := config.Load(flags, args)
+	if err != nil {
+		return err
+	}
+	cfg.Storage.AutoMigrate = false
+
+	s, err := service.OpenStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	migrations, err := s.Migrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "status":
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, m := range migrations {
+			applied := "pending"
+			if m.AppliedAt != nil {
+				applied = m.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", m.Version, m.Name, applied)
+		}
+		return w.Flush()
+	case "up":
+		target := uint(0)
+		if *to > 0 {
+			target = uint(*to)
+		}
+
+		applied, err := s.MigrateUp(ctx, target)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range applied {
+			fmt.Printf("applied %s\n", m)
+		}
+		fmt.Println("database schema is up to date")
+	case "down":
+		target := previousVersion(migrations)
+		if *to >= 0 {
+			target = uint(*to)
+		}
+
+		rolledBack, err := s.MigrateDown(ctx, target)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range rolledBack {
+			fmt.Printf("rolled back %s\n", m)
+		}
+	}
+
+	return nil
+}
+
+// previousVersion returns the version applied before the last applied migration.
+func previousVersion(migrations []*storage.Migration) uint {
+	var last, previous uint
+	for _, m := range migrations {
+		if m.AppliedAt != nil {
+			previous, last = last, m.Version
+		}
+	}
+
+	return previous
+}
+
+// runReset deletes all data, the service has to be stopped, otherwise its generator keeps
+// writing readings of the deleted sensors.
+func runReset(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("reset", flag.ExitOnError)
+	yes := flags.Bool("yes", false, "confirm all groups, sensors and readings are deleted")
+
+	cfg, err := config.Load(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if !*yes {
+		return ErrNotConfirmed
+	}
+
+	s, err := service.OpenStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if err = s.Reset(ctx); err != nil {
+		return err
+	}
+
+	fmt.Println("all groups, sensors and readings are deleted")
+	return nil
+}
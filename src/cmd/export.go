@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/jenyasd209/fake-sensors/src/config"
+	"github.com/jenyasd209/fake-sensors/src/export"
+	"github.com/jenyasd209/fake-sensors/src/service"
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+func runExport(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	kind := flags.String("kind", string(export.Readings), "records kind: readings, fish, sensors or labels")
+	format := flags.String("format", string(export.CSV), "file format: csv, ndjson or parquet")
+	out := flags.String("out", "", "output file, stdout if empty")
+	group := flags.String("group", "", "group name")
+	index := flags.Int64("index", -1, "sensor index in the group")
+	from := flags.String("from", "", "from time (RFC3339)")
+	till := flags.String("till", "", "till time (RFC3339)")
+	region := regionFlags(flags)
+
+	cfg, err := config.Load(flags, args)
+	if err != nil {
+		return err
+	}
+
+	k, err := export.ParseKind(*kind)
+	if err != nil {
+		return err
+	}
+
+	f, err := export.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	conditions, err := timeConditions(*from, *till)
+	if err != nil {
+		return err
+	}
+
+	filter := &storage.ExportFilter{Group: *group, Region: region(), Conditions: conditions}
+	if *index >= 0 {
+		i := uint64(*index)
+		filter.IndexInGroup = &i
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	s, err := service.OpenStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	return export.Export(ctx, s, w, k, f, filter)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/generator"
+)
+
+func runGenerate(ctx context.Context, args []string) error {
+	cfg, s, err := openStorage(flag.NewFlagSet("generate", flag.ExitOnError), args)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	g, err := generator.NewGenerator(s, cfg.GeneratorOptions()...)
+	if err != nil {
+		return err
+	}
+
+	created, err := g.Seed(ctx)
+	if err != nil {
+		return err
+	}
+
+	sensors, err := s.GetAllSensors()
+	if err != nil {
+		return err
+	}
+
+	groups, err := s.GetAllGroups()
+	if err != nil {
+		return err
+	}
+
+	if !created {
+		fmt.Printf("groups exist already: %d groups with %d sensors\n", len(groups), len(sensors))
+		return nil
+	}
+
+	fmt.Printf("created %d groups with %d sensors\n", len(groups), len(sensors))
+	return nil
+}
+
+// runBackfill generates the readings of the stored sensors for the past period, the groups
+// are seeded first when the database is empty.
+func runBackfill(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("backfill", flag.ExitOnError)
+	last := flags.Duration("last", 24*time.Hour, "period before -till to generate readings for")
+	from := flags.String("from", "", "from time (RFC3339), overrides -last")
+	till := flags.String("till", "", "till time (RFC3339), now if empty")
+
+	cfg, s, err := openStorage(flags, args)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	end := time.Now()
+	if *till != "" {
+		if end, err = time.Parse(time.RFC3339, *till); err != nil {
+			return err
+		}
+	}
+
+	start := end.Add(-*last)
+	if *from != "" {
+		if start, err = time.Parse(time.RFC3339, *from); err != nil {
+			return err
+		}
+	}
+
+	if !start.Before(end) {
+		return fmt.Errorf("backfill period is empty: %s is not before %s", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+
+	g, err := generator.NewGenerator(s, cfg.GeneratorOptions()...)
+	if err != nil {
+		return err
+	}
+
+	if _, err = g.Seed(ctx); err != nil {
+		return err
+	}
+
+	count, err := g.Backfill(ctx, start, end)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("generated %d sensor updates from %s till %s\n", count, start.Format(time.RFC3339), end.Format(time.RFC3339))
+	return nil
+}
@@ -2,18 +2,142 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/jenyasd209/fake-sensors/src/config"
 	"github.com/jenyasd209/fake-sensors/src/service"
+	"github.com/jenyasd209/fake-sensors/src/storage"
 )
 
+const defaultCommand = "serve"
+
+// command is a subcommand of the CLI, run gets the arguments following the command name.
+type command struct {
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+func commands() map[string]*command {
+	return map[string]*command{
+		"serve":    {summary: "serve the API and generate data, the default command", run: runServe},
+		"generate": {summary: "seed the sensor groups and sensors unless they exist", run: runGenerate},
+		"backfill": {summary: "generate historical readings of the stored sensors", run: runBackfill},
+		"migrate":  {summary: "apply, roll back or list the schema migrations", run: runMigrate},
+		"export":   {summary: "export readings, fish or sensors to a file", run: runExport},
+		"query":    {summary: "print averages, min and max temperatures or species", run: runQuery},
+		"sensors":  {summary: "list, add or remove sensors", run: runSensors},
+		"reset":    {summary: "delete all groups, sensors and readings", run: runReset},
+		"config":   {summary: "print the effective configuration", run: runConfig},
+	}
+}
+
 func main() {
-	s, err := service.NewService()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	name, args := defaultCommand, os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	cmd, ok := commands()[name]
+	if !ok {
+		usage()
+		if name != "help" {
+			os.Exit(2)
+		}
+		return
+	}
+
+	if err := cmd.run(ctx, args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	cmds := commands()
+	names := make([]string, 0, len(cmds))
+	for name := range cmds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", filepath.Base(os.Args[0]))
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, cmds[name].summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun %s <command> -h to list the flags of the command.\n", filepath.Base(os.Args[0]))
+}
+
+// openStorage loads the configuration with the flags of the command and connects to the storage.
+func openStorage(fs *flag.FlagSet, args []string) (*config.Config, *storage.Storage, error) {
+	cfg, err := config.Load(fs, args)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
 
-	err = s.Start(context.TODO())
+	s, err := service.OpenStorage(cfg)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
+
+	return cfg, s, nil
+}
+
+// regionFlags registers the region bounds flags, the returned function builds the options of
+// the flags which are set.
+func regionFlags(fs *flag.FlagSet) func() []storage.CoordinateOption {
+	xMin := fs.Float64("xMin", math.NaN(), "min X coordinate")
+	xMax := fs.Float64("xMax", math.NaN(), "max X coordinate")
+	yMin := fs.Float64("yMin", math.NaN(), "min Y coordinate")
+	yMax := fs.Float64("yMax", math.NaN(), "max Y coordinate")
+	zMin := fs.Float64("zMin", math.NaN(), "min Z coordinate")
+	zMax := fs.Float64("zMax", math.NaN(), "max Z coordinate")
+
+	return func() []storage.CoordinateOption {
+		var opts []storage.CoordinateOption
+		for v, opt := range map[*float64]func(float64) storage.CoordinateOption{
+			xMin: storage.WithXMin, xMax: storage.WithXMax,
+			yMin: storage.WithYMin, yMax: storage.WithYMax,
+			zMin: storage.WithZMin, zMax: storage.WithZMax,
+		} {
+			if !math.IsNaN(*v) {
+				opts = append(opts, opt(*v))
+			}
+		}
+
+		return opts
+	}
+}
+
+// timeConditions builds the conditions of the RFC3339 time range, empty bounds are left open.
+func timeConditions(from, till string) ([]storage.ConditionOption, error) {
+	var conditions []storage.ConditionOption
+	if from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, storage.WithCreatedFrom(t))
+	}
+
+	if till != "" {
+		t, err := time.Parse(time.RFC3339, till)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, storage.WithCreatedTill(t))
+	}
+
+	return conditions, nil
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+var ErrMissingFlag = errors.New("missing flag")
+
+var queries = []string{
+	"avg-temperature", "avg-transparency", "min-temperature", "max-temperature", "species", "sensor-temperature",
+}
+
+// runQuery prints the same values the API serves, the first argument is the value to query.
+func runQuery(ctx context.Context, args []string) error {
+	if len(args) == 0 || !slices.Contains(queries, args[0]) {
+		return fmt.Errorf("%w: query one of %s", ErrUnknownCommand, strings.Join(queries, ", "))
+	}
+	kind := args[0]
+
+	flags := flag.NewFlagSet("query "+kind, flag.ExitOnError)
+	group := flags.String("group", "", "group name for the averages and species")
+	sensor := flags.String("sensor", "", "sensor code name like alpha3 or UUID for sensor-temperature")
+	top := flags.Int("top", 0, "number of the most numerous species, all species if 0")
+	from := flags.String("from", "", "from time (RFC3339) for species and sensor-temperature")
+	till := flags.String("till", "", "till time (RFC3339) for species and sensor-temperature")
+	region := regionFlags(flags)
+
+	_, s, err := openStorage(flags, args[1:])
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	conditions, err := timeConditions(*from, *till)
+	if err != nil {
+		return err
+	}
+
+	requireGroup := func() error {
+		if *group == "" {
+			return fmt.Errorf("%w: -group", ErrMissingFlag)
+		}
+		return nil
+	}
+
+	switch kind {
+	case "avg-temperature":
+		if err = requireGroup(); err != nil {
+			return err
+		}
+
+		avg, err := s.GetAvgTemperature(ctx, *group)
+		if err != nil {
+			return err
+		}
+		fmt.Println(strconv.FormatFloat(avg, 'f', 2, 64))
+	case "avg-transparency":
+		if err = requireGroup(); err != nil {
+			return err
+		}
+
+		avg, err := s.GetAvgTransparency(ctx, *group)
+		if err != nil {
+			return err
+		}
+		fmt.Println(avg)
+	case "min-temperature", "max-temperature":
+		query := s.GetMinTemperatureByRegion
+		if kind == "max-temperature" {
+			query = s.GetMaxTemperatureByRegion
+		}
+
+		t, err := query(ctx, region()...)
+		if err != nil {
+			return err
+		}
+		fmt.Println(strconv.FormatFloat(t, 'f', 2, 64))
+	case "species":
+		if err = requireGroup(); err != nil {
+			return err
+		}
+
+		fishes, err := s.GetCurrentSpecies(ctx, *group, *top, conditions...)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "SPECIES\tCOUNT")
+		for _, fish := range fishes {
+			fmt.Fprintf(w, "%s\t%d\n", fish.Name, fish.Count)
+		}
+		return w.Flush()
+	case "sensor-temperature":
+		if *sensor == "" {
+			return fmt.Errorf("%w: -sensor", ErrMissingFlag)
+		}
+
+		found, err := s.GetSensor(*sensor)
+		if err != nil {
+			return err
+		}
+
+		avg, err := s.GetSensorAvgTemperature(found.ID, conditions...)
+		if err != nil {
+			return err
+		}
+		fmt.Println(strconv.FormatFloat(avg, 'f', 2, 64))
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+const sensorCommands = "sensors commands: list, add, remove"
+
+// runSensors manages the stored sensors, a running generator picks the changes up within its
+// sensor sync interval.
+func runSensors(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, sensorCommands)
+	}
+
+	switch args[0] {
+	case "list":
+		return listSensors(args[1:])
+	case "add":
+		return addSensor(args[1:])
+	case "remove":
+		return removeSensors(ctx, args[1:])
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownCommand, sensorCommands)
+}
+
+func listSensors(args []string) error {
+	flags := flag.NewFlagSet("sensors list", flag.ExitOnError)
+	group := flags.String("group", "", "list the sensors of the group only")
+
+	_, s, err := openStorage(flags, args)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	sensors, err := s.GetAllSensors(storage.WithSensorGroup(*group), storage.WithSensorSort(storage.SortByCodeName, false))
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCODE\tUUID\tX\tY\tZ\tRATE")
+	for _, sensor := range sensors {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%.2f\t%.2f\t%.2f\t%s\n",
+			sensor.ID, sensor.CodeName, sensor.UUID, sensor.X, sensor.Y, sensor.Z, sensor.DataOutputRate)
+	}
+
+	return w.Flush()
+}
+
+func addSensor(args []string) error {
+	flags := flag.NewFlagSet("sensors add", flag.ExitOnError)
+	group := flags.String("group", "", "group of the sensor")
+	x := flags.Float64("x", 0, "X coordinate")
+	y := flags.Float64("y", 0, "Y coordinate")
+	z := flags.Float64("z", 0, "Z coordinate")
+	rate := flags.Duration("rate", 0, "data output rate, the configured min data output rate if 0")
+	calibration := storage.DefaultCalibration()
+	flags.Float64Var(&calibration.Offset, "offset", calibration.Offset, "calibration offset in the temperature unit")
+	flags.Float64Var(&calibration.Gain, "gain", calibration.Gain, "calibration gain")
+	flags.Float64Var(&calibration.NoiseStd, "noise", calibration.NoiseStd, "measurement noise std in the temperature unit")
+	flags.Float64Var(&calibration.Resolution, "resolution", calibration.Resolution, "step the readings are rounded to, 0 does not round")
+	unit := flags.String("unit", string(calibration.Unit), "temperature unit: celsius, fahrenheit or kelvin")
+
+	cfg, s, err := openStorage(flags, args)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if *group == "" {
+		return fmt.Errorf("%w: -group", ErrMissingFlag)
+	}
+
+	if *rate <= 0 {
+		*rate = cfg.Generator.MinDataOutputRate.Duration()
+	}
+
+	if calibration.Unit, err = storage.ParseTemperatureUnit(*unit); err != nil {
+		return err
+	}
+
+	sensor := &storage.Sensor{X: *x, Y: *y, Z: *z, DataOutputRate: rate.Round(time.Second), Calibration: calibration}
+	if err = s.AddSensor(*group, sensor); err != nil {
+		return err
+	}
+
+	fmt.Printf("added sensor %s with id %d and UUID %s\n", sensor.CodeName, sensor.ID, sensor.UUID)
+	return nil
+}
+
+// removeSensors removes the sensors given by ids, code names like alpha3 or UUIDs.
+func removeSensors(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("sensors remove", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: sensors remove [flags] <id, code name or UUID>...")
+		flags.PrintDefaults()
+	}
+	purge := flags.Bool("purge", false, "delete the readings and fish of the sensors as well")
+
+	_, s, err := openStorage(flags, args)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("%w: sensor ids or code names", ErrMissingFlag)
+	}
+
+	sensors, err := s.GetAllSensors()
+	if err != nil {
+		return err
+	}
+
+	ids := make(map[string]uint, len(sensors)*3)
+	for _, sensor := range sensors {
+		ids[strconv.FormatUint(uint64(sensor.ID), 10)] = sensor.ID
+		ids[sensor.CodeName] = sensor.ID
+		ids[sensor.UUID.String()] = sensor.ID
+	}
+
+	for _, arg := range flags.Args() {
+		id, ok := ids[arg]
+		if !ok {
+			return fmt.Errorf("%w: %s", storage.ErrUnknownSensor, arg)
+		}
+
+		if err = s.RemoveSensor(ctx, id, *purge); err != nil {
+			return err
+		}
+		fmt.Printf("removed sensor %s\n", arg)
+	}
+
+	return nil
+}
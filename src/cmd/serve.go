@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/jenyasd209/fake-sensors/src/config"
+	"github.com/jenyasd209/fake-sensors/src/service"
+)
+
+func runServe(ctx context.Context, args []string) error {
+	cfg, err := config.Load(flag.NewFlagSet("serve", flag.ExitOnError), args)
+	if err != nil {
+		return err
+	}
+
+	s, err := service.NewService(cfg)
+	if err != nil {
+		return err
+	}
+
+	return s.Start(ctx)
+}
@@ -0,0 +1,378 @@
+package config
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/generator"
+	"github.com/jenyasd209/fake-sensors/src/storage"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// LeaderMode runs the generator only on the instance holding the leadership.
+	LeaderMode = "leader"
+	// ShardedMode makes every instance a generator worker with its share of the groups.
+	ShardedMode = "sharded"
+
+	catchUpSkip  = "skip"
+	catchUpBurst = "burst"
+
+	maskedSecret = "******"
+)
+
+// Config is the whole configuration of the service. Every field is loaded from the YAML file,
+// the environment variable and the command line flag, the later sources override the earlier.
+//
+// The env tag overrides the variable name which is derived from the field path otherwise,
+// for example GENERATOR_FIELD_SEED for generator.field.seed.
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Cache     CacheConfig     `yaml:"cache"`
+	Health    HealthConfig    `yaml:"health"`
+	Generator GeneratorConfig `yaml:"generator"`
+}
+
+type ServerConfig struct {
+	Address         string   `yaml:"address" usage:"address the API listens on"`
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" usage:"how long requests and the generator are drained on shutdown"`
+
+	GRPCAddress         string   `yaml:"grpc_address" usage:"address the gRPC API listens on"`
+	ReadingPollInterval Duration `yaml:"reading_poll_interval" usage:"how often the new readings are looked up for the gRPC subscriptions"`
+}
+
+type StorageConfig struct {
+	Host         string `yaml:"host" env:"POSTGRES_HOST" usage:"postgres host"`
+	Port         string `yaml:"port" env:"POSTGRES_PORT" usage:"postgres port"`
+	User         string `yaml:"user" env:"POSTGRES_USER" usage:"postgres user"`
+	Password     string `yaml:"password" env:"POSTGRES_PASSWORD" usage:"postgres user password"`
+	Name         string `yaml:"name" env:"POSTGRES_DB" usage:"postgres database name"`
+	RedisAddress string `yaml:"redis_address" env:"REDIS_ADDRESS" usage:"redis host and port"`
+	AutoMigrate  bool   `yaml:"auto_migrate" usage:"apply the pending schema migrations on start"`
+}
+
+type CacheConfig struct {
+	AverageTTL   Duration `yaml:"average_ttl" usage:"how long group averages are cached"`
+	RegionTTL    Duration `yaml:"region_ttl" usage:"how long regional min and max temperatures are cached"`
+	SpeciesTTL   Duration `yaml:"species_ttl" usage:"how long species lists of groups are cached"`
+	WriteThrough bool     `yaml:"write_through" usage:"update cached group averages on every write instead of dropping them"`
+}
+
+// HealthConfig decides when quiet sensors are late or offline.
+type HealthConfig struct {
+	CheckInterval Duration `yaml:"check_interval" usage:"how often status changes of the sensors are recorded as events"`
+	LateTolerance float64  `yaml:"late_tolerance" usage:"fraction of the data output rate a report may be delayed by before the sensor is late"`
+	OfflineAfter  int      `yaml:"offline_after" usage:"number of missed reports a sensor is offline after"`
+}
+
+type GeneratorConfig struct {
+	Mode     string `yaml:"mode" usage:"leader runs the generator on one instance, sharded splits the groups between all instances"`
+	WorkerId string `yaml:"worker_id" env:"WORKER_ID" usage:"name of the generator worker, host name and process id if empty"`
+
+	GroupsCount       uint16   `yaml:"groups_count" usage:"number of sensor groups created on the first start"`
+	MinSensors        uint16   `yaml:"min_sensors" usage:"min number of sensors in a new group"`
+	MaxSensors        uint16   `yaml:"max_sensors" usage:"max number of sensors in a new group"`
+	MinDataOutputRate Duration `yaml:"min_data_output_rate" usage:"min interval between updates of a new sensor"`
+	MaxDataOutputRate Duration `yaml:"max_data_output_rate" usage:"max interval between updates of a new sensor"`
+	FishListLength    int      `yaml:"fish_list_length" usage:"number of species a new sensor observes"`
+	Bounds            Bounds   `yaml:"bounds"`
+
+	NeighboursCount    int      `yaml:"neighbours_count" usage:"number of the closest sensors a sensor reading depends on"`
+	SensorSyncInterval Duration `yaml:"sensor_sync_interval" usage:"how often sensors added or removed in the database are picked up"`
+	FlushSize          int      `yaml:"flush_size" usage:"number of buffered updates written at once"`
+	FlushInterval      Duration `yaml:"flush_interval" usage:"how often buffered updates are written"`
+	Jitter             float64  `yaml:"jitter" usage:"fraction of the data output rate updates are randomly shifted by"`
+	CatchUp            string   `yaml:"catch_up" usage:"skip or burst the updates missed while the generator was behind"`
+
+	Field       FieldConfig       `yaml:"field"`
+	Hardware    HardwareConfig    `yaml:"hardware"`
+	Calibration CalibrationConfig `yaml:"calibration"`
+	Anomalies   AnomaliesConfig   `yaml:"anomalies"`
+	Replay      ReplayConfig      `yaml:"replay"`
+}
+
+// Bounds limit the coordinates new sensors are placed at.
+type Bounds struct {
+	MinX float64 `yaml:"min_x" usage:"min X coordinate of new sensors"`
+	MaxX float64 `yaml:"max_x" usage:"max X coordinate of new sensors"`
+	MinY float64 `yaml:"min_y" usage:"min Y coordinate of new sensors"`
+	MaxY float64 `yaml:"max_y" usage:"max Y coordinate of new sensors"`
+	MinZ float64 `yaml:"min_z" usage:"min Z coordinate of new sensors, the surface is at -1000"`
+	MaxZ float64 `yaml:"max_z" usage:"max Z coordinate of new sensors"`
+}
+
+// FieldConfig describes the environment the sensors sample their readings from.
+type FieldConfig struct {
+	Seed                 int64    `yaml:"seed" usage:"seed of the field noise, random if 0"`
+	SurfaceTemperature   float64  `yaml:"surface_temperature" usage:"water temperature at the surface"`
+	DeepTemperature      float64  `yaml:"deep_temperature" usage:"water temperature below the thermocline"`
+	ThermoclineDepth     float64  `yaml:"thermocline_depth" usage:"depth of the thermocline middle"`
+	ThermoclineThickness float64  `yaml:"thermocline_thickness" usage:"thickness of the thermocline"`
+	GradientX            float64  `yaml:"gradient_x" usage:"temperature change per unit along X"`
+	GradientY            float64  `yaml:"gradient_y" usage:"temperature change per unit along Y"`
+	Evolution            Duration `yaml:"evolution" usage:"how long the field noise takes to change"`
+
+	Latitude      float64 `yaml:"latitude" usage:"latitude the cycles are simulated at"`
+	Longitude     float64 `yaml:"longitude" usage:"longitude the cycles are simulated at"`
+	SimulatedDate string  `yaml:"simulated_date" usage:"date (YYYY-MM-DD) the current moment corresponds to, today if empty"`
+
+	DiurnalAmplitude  float64  `yaml:"diurnal_amplitude" usage:"surface temperature change over a day"`
+	DiurnalDepth      float64  `yaml:"diurnal_depth" usage:"depth the daily change fades at"`
+	SeasonalAmplitude float64  `yaml:"seasonal_amplitude" usage:"surface temperature change over a year"`
+	SeasonalDepth     float64  `yaml:"seasonal_depth" usage:"depth the seasonal change fades at"`
+	TideAmplitude     float64  `yaml:"tide_amplitude" usage:"transparency change over a tide"`
+	TideDepth         float64  `yaml:"tide_depth" usage:"depth the tide change fades at"`
+	TidePeriod        Duration `yaml:"tide_period" usage:"period of the tides"`
+}
+
+// HardwareConfig describes the batteries, failures and maintenance of the simulated sensors.
+type HardwareConfig struct {
+	BatteryDrain        float64  `yaml:"battery_drain" usage:"battery percent every report takes, 0 keeps the batteries full"`
+	LowBatteryNoise     float64  `yaml:"low_battery_noise" usage:"how many times the measurement error grows by as a low battery runs flat"`
+	MTBF                Duration `yaml:"mtbf" usage:"mean time between sensor failures, 0 disables failures"`
+	MaintenanceInterval Duration `yaml:"maintenance_interval" usage:"how often sensors get a new battery and are repaired, 0 disables maintenance"`
+}
+
+// CalibrationConfig describes the measurement errors new sensors are created with.
+type CalibrationConfig struct {
+	MaxOffset    float64 `yaml:"max_offset" usage:"max calibration offset of new sensors in Celsius degrees"`
+	MaxGainError float64 `yaml:"max_gain_error" usage:"max deviation of the calibration gain of new sensors from 1"`
+	MinNoiseStd  float64 `yaml:"min_noise_std" usage:"min measurement noise std of new sensors in Celsius degrees"`
+	MaxNoiseStd  float64 `yaml:"max_noise_std" usage:"max measurement noise std of new sensors in Celsius degrees"`
+	Resolution   float64 `yaml:"resolution" usage:"step the readings of new sensors are rounded to in their unit, 0 does not round"`
+	Units        string  `yaml:"units" usage:"comma separated temperature units (celsius, fahrenheit, kelvin) new sensors report in"`
+}
+
+// TemperatureUnits returns the configured units, the configuration has to be valid.
+func (c *CalibrationConfig) TemperatureUnits() []storage.TemperatureUnit {
+	var units []storage.TemperatureUnit
+	for _, name := range strings.Split(c.Units, ",") {
+		if unit, err := storage.ParseTemperatureUnit(strings.TrimSpace(name)); err == nil {
+			units = append(units, unit)
+		}
+	}
+
+	return units
+}
+
+// AnomaliesConfig sets how often the generator produces labelled anomalies, zero disables a kind
+// and every kind is disabled by default.
+type AnomaliesConfig struct {
+	SpikeInterval   Duration `yaml:"spike_interval" usage:"mean time between temperature spikes of a sensor"`
+	DriftInterval   Duration `yaml:"drift_interval" usage:"mean time between temperature drifts of a sensor"`
+	DropoutInterval Duration `yaml:"dropout_interval" usage:"mean time between dropouts of a sensor"`
+	ShockInterval   Duration `yaml:"shock_interval" usage:"mean time between population shocks around a sensor"`
+	EventInterval   Duration `yaml:"event_interval" usage:"mean time between scenario events of the generator"`
+}
+
+// ReplayConfig makes the generator replay a recorded dataset instead of generating data.
+type ReplayConfig struct {
+	File  string  `yaml:"file" env:"REPLAY_FILE" usage:"CSV or NDJSON dataset to replay"`
+	Speed float64 `yaml:"speed" env:"REPLAY_SPEED" usage:"speed factor for the recorded intervals"`
+	Loop  bool    `yaml:"loop" env:"REPLAY_LOOP" usage:"start the dataset over when it ends"`
+}
+
+// Default returns the configuration the service runs with when nothing is set, the values
+// match the defaults of the storage and generator packages.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Address:         ":8080",
+			ShutdownTimeout: Duration(15 * time.Second),
+
+			GRPCAddress:         ":9090",
+			ReadingPollInterval: Duration(time.Second),
+		},
+		Storage: StorageConfig{
+			Host:         "0.0.0.0",
+			Port:         "5432",
+			User:         "postgres",
+			Password:     "pswd",
+			Name:         "sensor",
+			RedisAddress: "0.0.0.0:6379",
+			AutoMigrate:  true,
+		},
+		Cache: CacheConfig{
+			AverageTTL: Duration(10 * time.Second),
+			RegionTTL:  Duration(10 * time.Second),
+			SpeciesTTL: Duration(10 * time.Second),
+		},
+		Health: HealthConfig{
+			CheckInterval: Duration(30 * time.Second),
+			LateTolerance: 0.5,
+			OfflineAfter:  3,
+		},
+		Generator: GeneratorConfig{
+			Mode:               LeaderMode,
+			GroupsCount:        24,
+			MinSensors:         2,
+			MaxSensors:         10,
+			MinDataOutputRate:  Duration(2 * time.Minute),
+			MaxDataOutputRate:  Duration(20 * time.Minute),
+			FishListLength:     10,
+			Bounds:             Bounds{MinX: -1000, MaxX: 1000, MinY: -1000, MaxY: 1000, MinZ: -1000, MaxZ: 1000},
+			NeighboursCount:    4,
+			SensorSyncInterval: Duration(time.Minute),
+			FlushSize:          1000,
+			FlushInterval:      Duration(time.Second),
+			Jitter:             0.05,
+			CatchUp:            catchUpSkip,
+			Field: FieldConfig{
+				SurfaceTemperature:   22,
+				DeepTemperature:      4,
+				ThermoclineDepth:     300,
+				ThermoclineThickness: 120,
+				GradientX:            0.002,
+				GradientY:            -0.003,
+				Evolution:            Duration(6 * time.Hour),
+				Latitude:             45,
+				DiurnalAmplitude:     1.5,
+				DiurnalDepth:         15,
+				SeasonalAmplitude:    8,
+				SeasonalDepth:        120,
+				TideAmplitude:        12,
+				TideDepth:            60,
+				TidePeriod:           Duration(12*time.Hour + 25*time.Minute),
+			},
+			Hardware: HardwareConfig{
+				BatteryDrain:        0.02,
+				LowBatteryNoise:     4,
+				MTBF:                Duration(90 * 24 * time.Hour),
+				MaintenanceInterval: Duration(30 * 24 * time.Hour),
+			},
+			Calibration: CalibrationConfig{
+				MaxOffset:    0.5,
+				MaxGainError: 0.02,
+				MinNoiseStd:  0.1,
+				MaxNoiseStd:  0.3,
+				Resolution:   0.1,
+				Units:        string(storage.Celsius),
+			},
+			Replay: ReplayConfig{Speed: 1},
+		},
+	}
+}
+
+// StorageOptions returns the options to open the configured storage with.
+func (c *Config) StorageOptions() []storage.Option {
+	return []storage.Option{
+		storage.WithDbHost(c.Storage.Host),
+		storage.WithDbPort(c.Storage.Port),
+		storage.WithDbUser(c.Storage.User),
+		storage.WithDbPassword(c.Storage.Password),
+		storage.WithDbName(c.Storage.Name),
+		storage.WithRedisAddress(c.Storage.RedisAddress),
+		storage.WithAutoMigrate(c.Storage.AutoMigrate),
+		storage.WithAverageCacheTTL(c.Cache.AverageTTL.Duration()),
+		storage.WithRegionCacheTTL(c.Cache.RegionTTL.Duration()),
+		storage.WithSpeciesCacheTTL(c.Cache.SpeciesTTL.Duration()),
+		storage.WithWriteThroughAverages(c.Cache.WriteThrough),
+		storage.WithLateTolerance(c.Health.LateTolerance),
+		storage.WithOfflineAfter(c.Health.OfflineAfter),
+	}
+}
+
+// GeneratorOptions returns the options to create the configured generator with, the
+// configuration has to be valid.
+func (c *Config) GeneratorOptions() []generator.DataOption {
+	g := c.Generator
+	f := g.Field
+
+	catchUp := generator.CatchUpSkip
+	if g.CatchUp == catchUpBurst {
+		catchUp = generator.CatchUpBurst
+	}
+
+	opts := []generator.DataOption{
+		generator.WithGroupsCount(g.GroupsCount),
+		generator.WithSensorsCount(g.MinSensors, g.MaxSensors),
+		generator.WithDataOutputRate(uint(g.MinDataOutputRate.Duration()/time.Second), uint(g.MaxDataOutputRate.Duration()/time.Second)),
+		generator.WithFishListLength(g.FishListLength),
+		generator.WithBounds(
+			generator.Coordinate{X: g.Bounds.MinX, Y: g.Bounds.MinY, Z: g.Bounds.MinZ},
+			generator.Coordinate{X: g.Bounds.MaxX, Y: g.Bounds.MaxY, Z: g.Bounds.MaxZ},
+		),
+		generator.WithNeighboursCount(g.NeighboursCount),
+		generator.WithSensorSyncInterval(g.SensorSyncInterval.Duration()),
+		generator.WithWriteBatch(g.FlushSize, g.FlushInterval.Duration()),
+		generator.WithJitter(g.Jitter),
+		generator.WithCatchUp(catchUp),
+		generator.WithSurfaceTemperature(f.SurfaceTemperature, f.DeepTemperature),
+		generator.WithThermocline(f.ThermoclineDepth, f.ThermoclineThickness),
+		generator.WithTemperatureGradient(f.GradientX, f.GradientY),
+		generator.WithFieldEvolution(f.Evolution.Duration()),
+		generator.WithLocation(f.Latitude, f.Longitude),
+		generator.WithDiurnalCycle(f.DiurnalAmplitude, f.DiurnalDepth),
+		generator.WithSeasonalCycle(f.SeasonalAmplitude, f.SeasonalDepth),
+		generator.WithTideCycle(f.TideAmplitude, f.TideDepth, f.TidePeriod.Duration()),
+		generator.WithBattery(g.Hardware.BatteryDrain, g.Hardware.LowBatteryNoise),
+		generator.WithFailures(g.Hardware.MTBF.Duration()),
+		generator.WithMaintenance(g.Hardware.MaintenanceInterval.Duration()),
+		generator.WithCalibrationErrors(g.Calibration.MaxOffset, g.Calibration.MaxGainError),
+		generator.WithNoiseStd(g.Calibration.MinNoiseStd, g.Calibration.MaxNoiseStd),
+		generator.WithResolution(g.Calibration.Resolution),
+		generator.WithTemperatureUnits(g.Calibration.TemperatureUnits()...),
+		generator.WithAnomalies(
+			g.Anomalies.SpikeInterval.Duration(),
+			g.Anomalies.DriftInterval.Duration(),
+			g.Anomalies.DropoutInterval.Duration(),
+			g.Anomalies.ShockInterval.Duration(),
+			g.Anomalies.EventInterval.Duration(),
+		),
+		generator.WithReplay(g.Replay.File, g.Replay.Speed, g.Replay.Loop),
+	}
+
+	if f.Seed != 0 {
+		opts = append(opts, generator.WithFieldSeed(f.Seed))
+	}
+
+	if date, err := time.Parse(time.DateOnly, f.SimulatedDate); err == nil {
+		opts = append(opts, generator.WithSimulatedDate(date))
+	}
+
+	return opts
+}
+
+// Print writes the configuration as YAML with the secrets masked.
+func (c *Config) Print(w io.Writer) error {
+	masked := *c
+	if masked.Storage.Password != "" {
+		masked.Storage.Password = maskedSecret
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&masked); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// Duration is a time.Duration written as a string like "1m30s" in the config file.
+type Duration time.Duration
+
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDefaultIsValid(t *testing.T) {
+	require.NoError(t, Default().Validate())
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`
+server:
+  address: ":9000"
+generator:
+  jitter: 0.2
+  min_sensors: 3
+  sensor_sync_interval: 30s
+  field:
+    seed: 7
+`), 0o600)
+	require.NoError(t, err)
+
+	t.Setenv("GENERATOR_JITTER", "0.3")
+	t.Setenv("GENERATOR_FIELD_SEED", "8")
+	t.Setenv("POSTGRES_DB", "fish")
+
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{
+		"-config", path,
+		"-generator.field.seed", "9",
+		"-cache.write-through",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, ":9000", cfg.Server.Address, "file overrides defaults")
+	assert.Equal(t, uint16(3), cfg.Generator.MinSensors)
+	assert.Equal(t, 30*time.Second, cfg.Generator.SensorSyncInterval.Duration())
+	assert.Equal(t, 0.3, cfg.Generator.Jitter, "env overrides the file")
+	assert.Equal(t, "fish", cfg.Storage.Name, "env names may be overridden")
+	assert.Equal(t, int64(9), cfg.Generator.Field.Seed, "flags override env")
+	assert.True(t, cfg.Cache.WriteThrough)
+	assert.Equal(t, Default().Generator.MaxSensors, cfg.Generator.MaxSensors, "unset values keep defaults")
+}
+
+func TestLoadTOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	err := os.WriteFile(path, []byte(`
+[server]
+address = ":9000"
+
+[generator]
+jitter = 0.2
+min_sensors = 3
+sensor_sync_interval = "30s"
+
+[generator.field]
+seed = 7
+simulated_date = 2023-08-15
+
+[cache]
+write_through = true
+`), 0o600)
+	require.NoError(t, err)
+
+	t.Setenv("GENERATOR_JITTER", "0.3")
+
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-config", path})
+	require.NoError(t, err)
+
+	assert.Equal(t, ":9000", cfg.Server.Address)
+	assert.Equal(t, uint16(3), cfg.Generator.MinSensors)
+	assert.Equal(t, 30*time.Second, cfg.Generator.SensorSyncInterval.Duration())
+	assert.Equal(t, 0.3, cfg.Generator.Jitter, "env overrides the file")
+	assert.Equal(t, int64(7), cfg.Generator.Field.Seed)
+	assert.Equal(t, "2023-08-15", cfg.Generator.Field.SimulatedDate)
+	assert.True(t, cfg.Cache.WriteThrough)
+	assert.Equal(t, Default().Generator.MaxSensors, cfg.Generator.MaxSensors, "unset values keep defaults")
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Run("UnknownFileField", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("generator:\n  jiter: 0.1\n"), 0o600))
+
+		_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-config", path})
+		require.ErrorIs(t, err, ErrConfigFile)
+		assert.ErrorContains(t, err, "jiter")
+	})
+
+	t.Run("UnknownTOMLField", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.toml")
+		require.NoError(t, os.WriteFile(path, []byte("[generator]\njiter = 0.1\n"), 0o600))
+
+		_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-config", path})
+		require.ErrorIs(t, err, ErrConfigFile)
+		assert.ErrorContains(t, err, "unknown setting generator.jiter")
+	})
+
+	t.Run("MalformedTOMLValue", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.toml")
+		require.NoError(t, os.WriteFile(path, []byte("[generator]\nmin_sensors = \"few\"\n"), 0o600))
+
+		_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-config", path})
+		require.ErrorIs(t, err, ErrConfigFile)
+		assert.ErrorIs(t, err, ErrInvalidValue)
+		assert.ErrorContains(t, err, "generator.min_sensors")
+	})
+
+	t.Run("MalformedEnv", func(t *testing.T) {
+		t.Setenv("CACHE_AVERAGE_TTL", "soon")
+
+		_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+		require.ErrorIs(t, err, ErrInvalidValue)
+		assert.ErrorContains(t, err, "CACHE_AVERAGE_TTL")
+	})
+
+	t.Run("MalformedFlag", func(t *testing.T) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(&bytes.Buffer{})
+
+		_, err := Load(fs, []string{"-generator.groups-count", "many"})
+		require.ErrorContains(t, err, "generator.groups-count")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{
+			"-generator.min-sensors", "12",
+			"-generator.catch-up", "later",
+			"-server.grpc-address", ":8080",
+		})
+		require.ErrorIs(t, err, ErrInvalidConfig)
+		assert.ErrorContains(t, err, "generator.min_sensors (12) must be less than generator.max_sensors (10)")
+		assert.ErrorContains(t, err, `generator.catch_up must be skip or burst, got "later"`)
+		assert.ErrorContains(t, err, "server.grpc_address must differ from server.address")
+	})
+}
+
+func TestPrint(t *testing.T) {
+	cfg := Default()
+	cfg.Storage.Password = "secret"
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, cfg.Print(buf))
+	assert.NotContains(t, buf.String(), "secret")
+	assert.Equal(t, "secret", cfg.Storage.Password)
+
+	printed := &Config{}
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), printed))
+	printed.Storage.Password = cfg.Storage.Password
+	assert.Equal(t, cfg, printed, "printed config can be loaded back")
+}
@@ -0,0 +1,315 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	fileFlag = "config"
+	fileEnv  = "CONFIG_FILE"
+)
+
+var (
+	ErrConfigFile   = errors.New("cannot read the config file")
+	ErrInvalidValue = errors.New("invalid value")
+
+	durationType = reflect.TypeOf(Duration(0))
+)
+
+// setting is a single configuration field with the names it is set by in every source.
+type setting struct {
+	path  string
+	env   string
+	usage string
+	value reflect.Value
+}
+
+func (s *setting) flagName() string {
+	return strings.ReplaceAll(s.path, "_", "-")
+}
+
+// settings lists the fields of the configuration, nested structs are flattened into paths
+// like generator.field.seed.
+func settings(c *Config) []*setting {
+	return collect(reflect.ValueOf(c).Elem(), "", nil)
+}
+
+func collect(v reflect.Value, prefix string, result []*setting) []*setting {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		path := prefix + field.Tag.Get("yaml")
+
+		if field.Type.Kind() == reflect.Struct {
+			result = collect(v.Field(i), path+".", result)
+			continue
+		}
+
+		env := field.Tag.Get("env")
+		if env == "" {
+			env = strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+		}
+
+		result = append(result, &setting{path: path, env: env, usage: field.Tag.Get("usage"), value: v.Field(i)})
+	}
+
+	return result
+}
+
+// Load builds the configuration from the defaults, the YAML or TOML file, the environment and the
+// flags in args, every source overrides the previous ones. The flags of every setting and
+// -config for the file path are registered in fs, so commands may add their own flags before.
+// The file is taken from CONFIG_FILE when the flag is not set. The result is validated.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	c := Default()
+	all := settings(c)
+
+	path := fs.String(fileFlag, os.Getenv(fileEnv), "YAML or TOML (.toml) config file, the "+fileEnv+" env var by default")
+	flags := make(map[string]*flagValue, len(all))
+	for _, s := range all {
+		f := &flagValue{setting: s}
+		flags[s.flagName()] = f
+		fs.Var(f, s.flagName(), s.usage+" ("+s.env+")")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *path != "" {
+		if err := c.readFile(*path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range all {
+		raw, ok := os.LookupEnv(s.env)
+		if !ok {
+			continue
+		}
+
+		if err := set(s.value, raw); err != nil {
+			return nil, fmt.Errorf("%w of %s env var: %w", ErrInvalidValue, s.env, err)
+		}
+	}
+
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		if v, ok := flags[f.Name]; ok && err == nil {
+			err = set(v.setting.value, v.raw)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// readFile reads the file as TOML when it has the .toml extension and as YAML otherwise.
+func (c *Config) readFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrConfigFile, err)
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = c.readTOML(file)
+	} else {
+		err = c.readYAML(file)
+	}
+	if err != nil {
+		return fmt.Errorf("%w %s: %w", ErrConfigFile, path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) readYAML(r io.Reader) error {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	return nil
+}
+
+// readTOML sets the settings of the TOML file, tables and keys are named like in YAML and the
+// values are parsed like the env vars.
+func (c *Config) readTOML(r io.Reader) error {
+	var tree map[string]interface{}
+	if err := toml.NewDecoder(r).Decode(&tree); err != nil {
+		return err
+	}
+
+	byPath := make(map[string]*setting)
+	for _, s := range settings(c) {
+		byPath[s.path] = s
+	}
+
+	return setTOML(tree, "", byPath)
+}
+
+func setTOML(table map[string]interface{}, prefix string, settings map[string]*setting) error {
+	for key, value := range table {
+		path := prefix + key
+		if nested, ok := value.(map[string]interface{}); ok {
+			if err := setTOML(nested, path+".", settings); err != nil {
+				return err
+			}
+			continue
+		}
+
+		s, ok := settings[path]
+		if !ok {
+			return errors.New("unknown setting " + path)
+		}
+
+		raw, err := tomlString(value)
+		if err == nil {
+			err = set(s.value, raw)
+		}
+		if err != nil {
+			return fmt.Errorf("%w of %s: %w", ErrInvalidValue, path, err)
+		}
+	}
+
+	return nil
+}
+
+// tomlString formats the TOML value the way it is written in the env vars.
+func tomlString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case toml.LocalDate:
+		return v.String(), nil
+	case toml.LocalDateTime:
+		return v.String(), nil
+	case toml.LocalTime:
+		return v.String(), nil
+	default:
+		return "", errors.New("unsupported value " + fmt.Sprint(value))
+	}
+}
+
+// set parses the raw value into the field.
+func set(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint16, reflect.Uint:
+		u, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return errors.New("unsupported type " + v.Type().String())
+	}
+
+	return nil
+}
+
+// flagValue keeps the raw flag value until the file and the environment are applied, so
+// flags take precedence regardless of the order the sources are read in.
+type flagValue struct {
+	setting *setting
+	raw     string
+}
+
+func (f *flagValue) String() string {
+	if f == nil || f.setting == nil {
+		return ""
+	}
+
+	return format(f.setting.value)
+}
+
+func (f *flagValue) Set(raw string) error {
+	if err := set(reflect.New(f.setting.value.Type()).Elem(), raw); err != nil {
+		return err
+	}
+
+	f.raw = raw
+	return nil
+}
+
+// IsBoolFlag allows boolean settings to be set by the flag name alone.
+func (f *flagValue) IsBoolFlag() bool {
+	return f.setting != nil && f.setting.value.Kind() == reflect.Bool
+}
+
+func format(v reflect.Value) string {
+	if v.Type() == durationType {
+		return Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint16, reflect.Uint:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	}
+
+	return ""
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+var ErrInvalidConfig = errors.New("invalid config")
+
+// maxGroupsCount is the number of group names the generator has.
+const maxGroupsCount = 24
+
+// Validate checks the configuration and reports every invalid setting at once.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.check(c.Server.Address != "", "server.address must not be empty")
+	v.check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	v.check(c.Server.GRPCAddress != "", "server.grpc_address must not be empty")
+	v.check(c.Server.Address != c.Server.GRPCAddress, "server.grpc_address must differ from server.address")
+	v.check(c.Server.ReadingPollInterval > 0, "server.reading_poll_interval must be positive")
+
+	v.check(c.Storage.Host != "", "storage.host must not be empty")
+	v.check(c.Storage.Name != "", "storage.name must not be empty")
+	v.check(c.Storage.User != "", "storage.user must not be empty")
+	v.check(c.Storage.RedisAddress != "", "storage.redis_address must not be empty")
+	if _, err := strconv.ParseUint(c.Storage.Port, 10, 16); err != nil {
+		v.fail("storage.port must be a port number, got " + strconv.Quote(c.Storage.Port))
+	}
+
+	v.check(c.Cache.AverageTTL > 0, "cache.average_ttl must be positive")
+	v.check(c.Cache.RegionTTL > 0, "cache.region_ttl must be positive")
+	v.check(c.Cache.SpeciesTTL > 0, "cache.species_ttl must be positive")
+
+	v.check(c.Health.CheckInterval > 0, "health.check_interval must be positive")
+	v.check(c.Health.LateTolerance > 0, "health.late_tolerance must be positive, got "+formatFloat(c.Health.LateTolerance))
+	v.check(c.Health.OfflineAfter > 0, "health.offline_after must be positive")
+
+	g := c.Generator
+	v.check(g.Mode == LeaderMode || g.Mode == ShardedMode,
+		"generator.mode must be "+LeaderMode+" or "+ShardedMode+", got "+strconv.Quote(g.Mode))
+	v.check(g.GroupsCount > 0 && g.GroupsCount <= maxGroupsCount,
+		"generator.groups_count must be from 1 to "+strconv.Itoa(maxGroupsCount)+", got "+strconv.Itoa(int(g.GroupsCount)))
+	v.check(g.MinSensors > 0, "generator.min_sensors must be positive")
+	v.check(g.MinSensors < g.MaxSensors, "generator.min_sensors ("+strconv.Itoa(int(g.MinSensors))+
+		") must be less than generator.max_sensors ("+strconv.Itoa(int(g.MaxSensors))+")")
+	v.check(g.MinDataOutputRate.Duration() >= time.Second, "generator.min_data_output_rate must be at least 1s")
+	v.check(g.MaxDataOutputRate.Duration()-g.MinDataOutputRate.Duration() >= time.Second,
+		"generator.min_data_output_rate ("+g.MinDataOutputRate.String()+
+			") must be at least 1s less than generator.max_data_output_rate ("+g.MaxDataOutputRate.String()+")")
+	v.check(g.FishListLength > 0, "generator.fish_list_length must be positive")
+	v.less(g.Bounds.MinX, g.Bounds.MaxX, "generator.bounds.min_x", "generator.bounds.max_x")
+	v.less(g.Bounds.MinY, g.Bounds.MaxY, "generator.bounds.min_y", "generator.bounds.max_y")
+	v.less(g.Bounds.MinZ, g.Bounds.MaxZ, "generator.bounds.min_z", "generator.bounds.max_z")
+
+	v.check(g.NeighboursCount > 0, "generator.neighbours_count must be positive")
+	v.check(g.SensorSyncInterval > 0, "generator.sensor_sync_interval must be positive")
+	v.check(g.FlushSize > 0, "generator.flush_size must be positive")
+	v.check(g.FlushInterval > 0, "generator.flush_interval must be positive")
+	v.check(g.Jitter >= 0 && g.Jitter < 1, "generator.jitter must be from 0 to 1 exclusive, got "+formatFloat(g.Jitter))
+	v.check(g.CatchUp == catchUpSkip || g.CatchUp == catchUpBurst,
+		"generator.catch_up must be "+catchUpSkip+" or "+catchUpBurst+", got "+strconv.Quote(g.CatchUp))
+
+	f := g.Field
+	v.check(f.ThermoclineDepth >= 0, "generator.field.thermocline_depth must not be negative")
+	v.check(f.ThermoclineThickness > 0, "generator.field.thermocline_thickness must be positive")
+	v.check(f.Evolution > 0, "generator.field.evolution must be positive")
+	v.check(f.Latitude >= -90 && f.Latitude <= 90, "generator.field.latitude must be from -90 to 90, got "+formatFloat(f.Latitude))
+	v.check(f.Longitude >= -180 && f.Longitude <= 180, "generator.field.longitude must be from -180 to 180, got "+formatFloat(f.Longitude))
+	if f.SimulatedDate != "" {
+		if _, err := time.Parse(time.DateOnly, f.SimulatedDate); err != nil {
+			v.fail("generator.field.simulated_date must be a YYYY-MM-DD date, got " + strconv.Quote(f.SimulatedDate))
+		}
+	}
+	v.check(f.DiurnalAmplitude >= 0, "generator.field.diurnal_amplitude must not be negative")
+	v.check(f.DiurnalDepth > 0, "generator.field.diurnal_depth must be positive")
+	v.check(f.SeasonalAmplitude >= 0, "generator.field.seasonal_amplitude must not be negative")
+	v.check(f.SeasonalDepth > 0, "generator.field.seasonal_depth must be positive")
+	v.check(f.TideAmplitude >= 0, "generator.field.tide_amplitude must not be negative")
+	v.check(f.TideDepth > 0, "generator.field.tide_depth must be positive")
+	v.check(f.TidePeriod > 0, "generator.field.tide_period must be positive")
+
+	h := g.Hardware
+	v.check(h.BatteryDrain >= 0 && h.BatteryDrain <= 100,
+		"generator.hardware.battery_drain must be from 0 to 100, got "+formatFloat(h.BatteryDrain))
+	v.check(h.LowBatteryNoise >= 0, "generator.hardware.low_battery_noise must not be negative")
+	v.check(h.MTBF >= 0, "generator.hardware.mtbf must not be negative")
+	v.check(h.MaintenanceInterval >= 0, "generator.hardware.maintenance_interval must not be negative")
+
+	cal := g.Calibration
+	v.check(cal.MaxOffset >= 0, "generator.calibration.max_offset must not be negative")
+	v.check(cal.MaxGainError >= 0 && cal.MaxGainError < 1,
+		"generator.calibration.max_gain_error must be from 0 to 1 exclusive, got "+formatFloat(cal.MaxGainError))
+	v.check(cal.MinNoiseStd >= 0, "generator.calibration.min_noise_std must not be negative")
+	v.check(cal.MinNoiseStd <= cal.MaxNoiseStd, "generator.calibration.min_noise_std ("+formatFloat(cal.MinNoiseStd)+
+		") must not be greater than generator.calibration.max_noise_std ("+formatFloat(cal.MaxNoiseStd)+")")
+	v.check(cal.Resolution >= 0, "generator.calibration.resolution must not be negative")
+	for _, unit := range strings.Split(cal.Units, ",") {
+		if _, err := storage.ParseTemperatureUnit(strings.TrimSpace(unit)); err != nil {
+			v.fail("generator.calibration.units: " + err.Error())
+		}
+	}
+
+	a := g.Anomalies
+	v.check(a.SpikeInterval >= 0, "generator.anomalies.spike_interval must not be negative")
+	v.check(a.DriftInterval >= 0, "generator.anomalies.drift_interval must not be negative")
+	v.check(a.DropoutInterval >= 0, "generator.anomalies.dropout_interval must not be negative")
+	v.check(a.ShockInterval >= 0, "generator.anomalies.shock_interval must not be negative")
+	v.check(a.EventInterval >= 0, "generator.anomalies.event_interval must not be negative")
+
+	v.check(g.Replay.Speed > 0, "generator.replay.speed must be positive")
+
+	return v.err
+}
+
+type validator struct {
+	err error
+}
+
+func (v *validator) check(ok bool, message string) {
+	if !ok {
+		v.fail(message)
+	}
+}
+
+func (v *validator) fail(message string) {
+	v.err = multierror.Append(v.err, fmt.Errorf("%w: %s", ErrInvalidConfig, message))
+}
+
+func (v *validator) less(min, max float64, minName, maxName string) {
+	v.check(min < max, minName+" ("+formatFloat(min)+") must be less than "+maxName+" ("+formatFloat(max)+")")
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
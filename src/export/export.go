@@ -0,0 +1,104 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+type Format string
+
+const (
+	CSV     Format = "csv"
+	NDJSON  Format = "ndjson"
+	Parquet Format = "parquet"
+)
+
+type Kind string
+
+const (
+	Readings Kind = "readings"
+	Fishes   Kind = "fish"
+	Sensors  Kind = "sensors"
+	Labels   Kind = "labels"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown export format")
+	ErrUnknownKind   = errors.New("unknown export kind")
+)
+
+func ParseFormat(format string) (Format, error) {
+	switch f := Format(format); f {
+	case CSV, NDJSON, Parquet:
+		return f, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+}
+
+func ParseKind(kind string) (Kind, error) {
+	switch k := Kind(kind); k {
+	case Readings, Fishes, Sensors, Labels:
+		return k, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownKind, kind)
+}
+
+// ContentType returns the MIME type of the exported data.
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv"
+	case NDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/vnd.apache.parquet"
+	}
+}
+
+// Export streams records of the kind matching the filter from the storage into w. The output
+// is buffered and the buffered part is dropped on an error, so an error of the query leaves
+// w untouched.
+func Export(ctx context.Context, s *storage.Storage, w io.Writer, kind Kind, format Format, filter *storage.ExportFilter) error {
+	switch kind {
+	case Readings:
+		return export(w, format, func(write func(*storage.ReadingRecord) error) error {
+			return s.StreamReadings(ctx, filter, write)
+		}, newReadingRow)
+	case Fishes:
+		return export(w, format, func(write func(*storage.FishRecord) error) error {
+			return s.StreamFishes(ctx, filter, write)
+		}, newFishRow)
+	case Sensors:
+		return export(w, format, func(write func(*storage.SensorRecord) error) error {
+			return s.StreamSensors(ctx, filter, write)
+		}, newSensorRow)
+	case Labels:
+		return export(w, format, func(write func(*storage.LabelRecord) error) error {
+			return s.StreamLabels(ctx, filter, write)
+		}, newLabelRow)
+	}
+
+	return ErrUnknownKind
+}
+
+func export[R any, T row](w io.Writer, format Format, stream func(func(*R) error) error, convert func(*R) T) error {
+	rw, err := newRowWriter[T](w, format)
+	if err != nil {
+		return err
+	}
+
+	err = stream(func(record *R) error {
+		return rw.Write(convert(record))
+	})
+	if err != nil {
+		return err
+	}
+
+	return rw.Close()
+}
@@ -0,0 +1,112 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readingRows() []ReadingRow {
+	at := time.Date(2023, 11, 1, 10, 0, 0, 500000000, time.UTC)
+	trueValue := 12.25
+
+	return []ReadingRow{
+		{SensorId: 1, Group: "alpha", IndexInGroup: 3, Timestamp: at, Metric: storage.TemperatureMetric, Value: 12.5, TrueValue: &trueValue, Labels: "spike,drift"},
+		{SensorId: 2, Group: "beta", IndexInGroup: 0, Timestamp: at.Add(time.Minute), Metric: storage.TransparencyMetric, Value: 40},
+	}
+}
+
+func write[T row](t *testing.T, format Format, rows []T) []byte {
+	var buf bytes.Buffer
+	rw, err := newRowWriter[T](&buf, format)
+	require.NoError(t, err)
+	for _, r := range rows {
+		require.NoError(t, rw.Write(r))
+	}
+	require.NoError(t, rw.Close())
+
+	return buf.Bytes()
+}
+
+func TestCsvRoundTrip(t *testing.T) {
+	rows := readingRows()
+	records, err := csv.NewReader(bytes.NewReader(write(t, CSV, rows))).ReadAll()
+	require.NoError(t, err)
+
+	require.Len(t, records, len(rows)+1)
+	assert.Equal(t, ReadingRow{}.header(), records[0])
+	for i, r := range rows {
+		assert.Equal(t, r.record(), records[i+1])
+	}
+	assert.Equal(t, "", records[2][6], "a missing true value is empty")
+}
+
+func TestNdjsonRoundTrip(t *testing.T) {
+	rows := readingRows()
+	dec := json.NewDecoder(bytes.NewReader(write(t, NDJSON, rows)))
+
+	for _, r := range rows {
+		var got ReadingRow
+		require.NoError(t, dec.Decode(&got))
+		assert.Equal(t, r, got)
+	}
+	assert.False(t, dec.More())
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	rows := readingRows()
+	data := write(t, Parquet, rows)
+
+	got, err := parquet.Read[ReadingRow](bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	assert.Equal(t, rows, got)
+}
+
+func TestParquetRowGroups(t *testing.T) {
+	rows := make([]ReadingRow, 2*parquetRowGroupSize+1)
+	for i := range rows {
+		rows[i] = readingRows()[i%2]
+	}
+	data := write(t, Parquet, rows)
+
+	f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	assert.Len(t, f.RowGroups(), 3, "the rows are written out in row groups")
+	assert.Equal(t, int64(len(rows)), f.NumRows())
+}
+
+func TestExportError(t *testing.T) {
+	for _, format := range []Format{CSV, NDJSON, Parquet} {
+		t.Run(string(format), func(t *testing.T) {
+			errStream := errors.New("connection lost")
+
+			var buf bytes.Buffer
+			err := export(&buf, format, func(write func(*storage.ReadingRecord) error) error {
+				if err := write(&storage.ReadingRecord{SensorId: 1, Metric: storage.TemperatureMetric}); err != nil {
+					return err
+				}
+				return errStream
+			}, newReadingRow)
+
+			assert.ErrorIs(t, err, errStream)
+			assert.Zero(t, buf.Len(), "nothing is written, so the error can be reported instead")
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	_, err := ParseFormat("xml")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+
+	_, err = ParseKind("whales")
+	assert.ErrorIs(t, err, ErrUnknownKind)
+}
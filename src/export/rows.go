@@ -0,0 +1,200 @@
+package export
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+type row interface {
+	header() []string
+	record() []string
+}
+
+type ReadingRow struct {
+	SensorId     uint64    `json:"sensor_id" parquet:"sensor_id"`
+	Group        string    `json:"group" parquet:"group,dict"`
+	IndexInGroup uint64    `json:"index_in_group" parquet:"index_in_group"`
+	Timestamp    time.Time `json:"timestamp" parquet:"timestamp,timestamp(microsecond)"`
+	Metric       string    `json:"metric" parquet:"metric,dict"`
+	Value        float64   `json:"value" parquet:"value"`
+	TrueValue    *float64  `json:"true_value" parquet:"true_value,optional"`
+	Labels       string    `json:"labels" parquet:"labels,dict"`
+}
+
+func newReadingRow(r *storage.ReadingRecord) ReadingRow {
+	return ReadingRow{
+		SensorId:     r.SensorId,
+		Group:        r.GroupName,
+		IndexInGroup: r.IndexInGroup,
+		Timestamp:    r.CreatedAt.UTC(),
+		Metric:       r.Metric,
+		Value:        r.Value,
+		TrueValue:    r.TrueValue,
+		Labels:       r.Labels,
+	}
+}
+
+func (ReadingRow) header() []string {
+	return []string{"sensor_id", "group", "index_in_group", "timestamp", "metric", "value", "true_value", "labels"}
+}
+
+func (r ReadingRow) record() []string {
+	return []string{
+		strconv.FormatUint(r.SensorId, 10),
+		r.Group,
+		strconv.FormatUint(r.IndexInGroup, 10),
+		r.Timestamp.Format(time.RFC3339Nano),
+		r.Metric,
+		strconv.FormatFloat(r.Value, 'f', -1, 64),
+		formatOptional(r.TrueValue),
+		r.Labels,
+	}
+}
+
+func formatOptional(v *float64) string {
+	if v == nil {
+		return ""
+	}
+
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+type FishRow struct {
+	SensorId     uint64    `json:"sensor_id" parquet:"sensor_id"`
+	Group        string    `json:"group" parquet:"group,dict"`
+	IndexInGroup uint64    `json:"index_in_group" parquet:"index_in_group"`
+	Timestamp    time.Time `json:"timestamp" parquet:"timestamp,timestamp(microsecond)"`
+	Species      string    `json:"species" parquet:"species,dict"`
+	Count        uint64    `json:"count" parquet:"count"`
+}
+
+func newFishRow(r *storage.FishRecord) FishRow {
+	return FishRow{
+		SensorId:     r.SensorId,
+		Group:        r.GroupName,
+		IndexInGroup: r.IndexInGroup,
+		Timestamp:    r.CreatedAt.UTC(),
+		Species:      r.Name,
+		Count:        r.Count,
+	}
+}
+
+func (FishRow) header() []string {
+	return []string{"sensor_id", "group", "index_in_group", "timestamp", "species", "count"}
+}
+
+func (r FishRow) record() []string {
+	return []string{
+		strconv.FormatUint(r.SensorId, 10),
+		r.Group,
+		strconv.FormatUint(r.IndexInGroup, 10),
+		r.Timestamp.Format(time.RFC3339Nano),
+		r.Species,
+		strconv.FormatUint(r.Count, 10),
+	}
+}
+
+type SensorRow struct {
+	SensorId       uint64    `json:"sensor_id" parquet:"sensor_id"`
+	Group          string    `json:"group" parquet:"group,dict"`
+	IndexInGroup   uint64    `json:"index_in_group" parquet:"index_in_group"`
+	X              float64   `json:"x" parquet:"x"`
+	Y              float64   `json:"y" parquet:"y"`
+	Z              float64   `json:"z" parquet:"z"`
+	DataOutputRate float64   `json:"data_output_rate" parquet:"data_output_rate"`
+	CreatedAt      time.Time `json:"created_at" parquet:"created_at,timestamp(microsecond)"`
+
+	CalibrationOffset     float64 `json:"calibration_offset" parquet:"calibration_offset"`
+	CalibrationGain       float64 `json:"calibration_gain" parquet:"calibration_gain"`
+	CalibrationNoiseStd   float64 `json:"calibration_noise_std" parquet:"calibration_noise_std"`
+	CalibrationResolution float64 `json:"calibration_resolution" parquet:"calibration_resolution"`
+	CalibrationUnit       string  `json:"calibration_unit" parquet:"calibration_unit,dict"`
+}
+
+func newSensorRow(r *storage.SensorRecord) SensorRow {
+	return SensorRow{
+		SensorId:       r.Id,
+		Group:          r.GroupName,
+		IndexInGroup:   r.IndexInGroup,
+		X:              r.X,
+		Y:              r.Y,
+		Z:              r.Z,
+		DataOutputRate: r.DataOutputRate.Seconds(),
+		CreatedAt:      r.CreatedAt.UTC(),
+
+		CalibrationOffset:     r.CalibrationOffset,
+		CalibrationGain:       r.CalibrationGain,
+		CalibrationNoiseStd:   r.CalibrationNoiseStd,
+		CalibrationResolution: r.CalibrationResolution,
+		CalibrationUnit:       r.CalibrationUnit,
+	}
+}
+
+func (SensorRow) header() []string {
+	return []string{"sensor_id", "group", "index_in_group", "x", "y", "z", "data_output_rate", "created_at",
+		"calibration_offset", "calibration_gain", "calibration_noise_std", "calibration_resolution", "calibration_unit"}
+}
+
+func (r SensorRow) record() []string {
+	return []string{
+		strconv.FormatUint(r.SensorId, 10),
+		r.Group,
+		strconv.FormatUint(r.IndexInGroup, 10),
+		strconv.FormatFloat(r.X, 'f', -1, 64),
+		strconv.FormatFloat(r.Y, 'f', -1, 64),
+		strconv.FormatFloat(r.Z, 'f', -1, 64),
+		strconv.FormatFloat(r.DataOutputRate, 'f', -1, 64),
+		r.CreatedAt.Format(time.RFC3339Nano),
+		strconv.FormatFloat(r.CalibrationOffset, 'f', -1, 64),
+		strconv.FormatFloat(r.CalibrationGain, 'f', -1, 64),
+		strconv.FormatFloat(r.CalibrationNoiseStd, 'f', -1, 64),
+		strconv.FormatFloat(r.CalibrationResolution, 'f', -1, 64),
+		r.CalibrationUnit,
+	}
+}
+
+type LabelRow struct {
+	SensorId     uint64    `json:"sensor_id" parquet:"sensor_id"`
+	Group        string    `json:"group" parquet:"group,dict"`
+	IndexInGroup uint64    `json:"index_in_group" parquet:"index_in_group"`
+	Kind         string    `json:"kind" parquet:"kind,dict"`
+	Metric       string    `json:"metric" parquet:"metric,dict"`
+	StartedAt    time.Time `json:"started_at" parquet:"started_at,timestamp(microsecond)"`
+	EndedAt      time.Time `json:"ended_at" parquet:"ended_at,timestamp(microsecond)"`
+	Magnitude    float64   `json:"magnitude" parquet:"magnitude"`
+	Detail       string    `json:"detail" parquet:"detail"`
+}
+
+func newLabelRow(r *storage.LabelRecord) LabelRow {
+	return LabelRow{
+		SensorId:     r.SensorId,
+		Group:        r.GroupName,
+		IndexInGroup: r.IndexInGroup,
+		Kind:         r.Kind,
+		Metric:       r.Metric,
+		StartedAt:    r.CreatedAt.UTC(),
+		EndedAt:      r.EndedAt.UTC(),
+		Magnitude:    r.Magnitude,
+		Detail:       r.Detail,
+	}
+}
+
+func (LabelRow) header() []string {
+	return []string{"sensor_id", "group", "index_in_group", "kind", "metric", "started_at", "ended_at", "magnitude", "detail"}
+}
+
+func (r LabelRow) record() []string {
+	return []string{
+		strconv.FormatUint(r.SensorId, 10),
+		r.Group,
+		strconv.FormatUint(r.IndexInGroup, 10),
+		r.Kind,
+		r.Metric,
+		r.StartedAt.Format(time.RFC3339Nano),
+		r.EndedAt.Format(time.RFC3339Nano),
+		strconv.FormatFloat(r.Magnitude, 'f', -1, 64),
+		r.Detail,
+	}
+}
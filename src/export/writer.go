@@ -0,0 +1,116 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+const (
+	parquetBatchSize = 1024
+	// parquetRowGroupSize is the number of rows kept in memory until a row group is written out.
+	parquetRowGroupSize = 64 * 1024
+)
+
+type rowWriter[T row] interface {
+	Write(T) error
+	Close() error
+}
+
+func newRowWriter[T row](w io.Writer, format Format) (rowWriter[T], error) {
+	switch format {
+	case CSV:
+		return newCsvWriter[T](w)
+	case NDJSON:
+		return newJsonWriter[T](w), nil
+	case Parquet:
+		return newParquetWriter[T](w), nil
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+type csvWriter[T row] struct {
+	w *csv.Writer
+}
+
+func newCsvWriter[T row](w io.Writer) (*csvWriter[T], error) {
+	var header T
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header.header()); err != nil {
+		return nil, err
+	}
+
+	return &csvWriter[T]{w: cw}, nil
+}
+
+func (w *csvWriter[T]) Write(r T) error {
+	return w.w.Write(r.record())
+}
+
+func (w *csvWriter[T]) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type jsonWriter[T row] struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func newJsonWriter[T row](w io.Writer) *jsonWriter[T] {
+	buf := bufio.NewWriter(w)
+	return &jsonWriter[T]{buf: buf, enc: json.NewEncoder(buf)}
+}
+
+func (w *jsonWriter[T]) Write(r T) error {
+	return w.enc.Encode(r)
+}
+
+func (w *jsonWriter[T]) Close() error {
+	return w.buf.Flush()
+}
+
+// parquetWriter buffers rows into small batches, the parquet writer writes a row group out
+// every parquetRowGroupSize rows, so the export is never held in memory as a whole.
+type parquetWriter[T row] struct {
+	w     *parquet.GenericWriter[T]
+	batch []T
+}
+
+func newParquetWriter[T row](w io.Writer) *parquetWriter[T] {
+	return &parquetWriter[T]{
+		w:     parquet.NewGenericWriter[T](w, parquet.MaxRowsPerRowGroup(parquetRowGroupSize)),
+		batch: make([]T, 0, parquetBatchSize),
+	}
+}
+
+func (w *parquetWriter[T]) Write(r T) error {
+	w.batch = append(w.batch, r)
+	if len(w.batch) < parquetBatchSize {
+		return nil
+	}
+
+	return w.flush()
+}
+
+func (w *parquetWriter[T]) Close() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	return w.w.Close()
+}
+
+func (w *parquetWriter[T]) flush() error {
+	if len(w.batch) == 0 {
+		return nil
+	}
+
+	_, err := w.w.Write(w.batch)
+	w.batch = w.batch[:0]
+	return err
+}
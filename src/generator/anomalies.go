@@ -0,0 +1,228 @@
+package generator
+
+import (
+	"math"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+const (
+	minSpike, maxSpike = 3.0, 8.0
+
+	minDrift, maxDrift                 = 1.0, 4.0
+	minDriftDuration, maxDriftDuration = 6 * time.Hour, 48 * time.Hour
+
+	minDropoutReports, maxDropoutReports = 3, 20
+
+	minShockLoss, maxShockLoss = 0.5, 0.9
+
+	minEventDuration, maxEventDuration = 2 * time.Hour, 12 * time.Hour
+	minEventRadius, maxEventRadius     = 150.0, 400.0
+	// A scenario event is an intrusion of warm and turbid water, the changes are the largest
+	// in the middle of the event and in its center.
+	minEventTemperature, maxEventTemperature   = 2.0, 5.0
+	minEventTransparency, maxEventTransparency = 10.0, 40.0
+)
+
+// anomalyRules decide how often the generator produces anomalies, every anomaly is recorded
+// with a label. The intervals are the mean times between the anomalies of a sensor, except
+// scenario events which happen once per interval in the area of a random sensor. Zero
+// intervals disable the anomalies, so the generator produces none unless they are set.
+type anomalyRules struct {
+	spikeInterval   time.Duration
+	driftInterval   time.Duration
+	dropoutInterval time.Duration
+	shockInterval   time.Duration
+	eventInterval   time.Duration
+}
+
+// anomalyState is the anomalies a sensor is in.
+type anomalyState struct {
+	drift        *drift
+	dropoutUntil time.Time
+	// spike is the offset of the current reading.
+	spike float64
+}
+
+// offset is the error the anomalies add to the temperature read at the moment.
+func (s *anomalyState) offset(at time.Time) float64 {
+	return s.drift.offset(at) + s.spike
+}
+
+type drift struct {
+	start, end time.Time
+	magnitude  float64
+}
+
+// offset grows linearly from zero to the magnitude until the end of the drift.
+func (d *drift) offset(at time.Time) float64 {
+	if d == nil || at.Before(d.start) || at.After(d.end) || !d.end.After(d.start) {
+		return 0
+	}
+
+	return d.magnitude * float64(at.Sub(d.start)) / float64(d.end.Sub(d.start))
+}
+
+type scenarioEvent struct {
+	center     Coordinate
+	radius     float64
+	start, end time.Time
+
+	temperature, transparency float64
+}
+
+// weight is how strongly the event affects the coordinate at the moment, from 0 to 1.
+func (e *scenarioEvent) weight(c *Coordinate, at time.Time) float64 {
+	if at.Before(e.start) || !at.Before(e.end) {
+		return 0
+	}
+
+	d := distance(&e.center, c)
+	if d >= e.radius {
+		return 0
+	}
+
+	progress := float64(at.Sub(e.start)) / float64(e.end.Sub(e.start))
+	return math.Sin(math.Pi*progress) * (1 - (d/e.radius)*(d/e.radius))
+}
+
+// eventEffect returns the temperature and transparency changes the active scenario events make
+// at the coordinate, it has to be called under the lock.
+func (g *Generator) eventEffect(c *Coordinate, at time.Time) (float64, float64) {
+	var temperature, transparency float64
+	for _, e := range g.events {
+		w := e.weight(c, at)
+		temperature += e.temperature * w
+		transparency -= e.transparency * w
+	}
+
+	return temperature, transparency
+}
+
+// anomalyStep decides the anomalies of the sensor report due at the moment. It returns the
+// labels of the anomalies which started and whether the sensor reports.
+func (g *Generator) anomalyStep(n *regenerateNode, at time.Time) ([]*storage.AnomalyLabel, bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	r := &g.rules.anomalies
+	rate := n.sensor.DataOutputRate
+	state := &n.anomalies
+	state.spike = 0
+
+	labels := g.scenarioStep(n, at)
+
+	if at.Before(state.dropoutUntil) {
+		return labels, false
+	}
+
+	if chance(rate, r.dropoutInterval) {
+		reports := minDropoutReports + random.Intn(maxDropoutReports-minDropoutReports+1)
+		state.dropoutUntil = at.Add(time.Duration(reports) * rate)
+		labels = append(labels, newLabel(n, storage.AnomalyDropout, "", at, state.dropoutUntil, float64(reports)))
+		return labels, false
+	}
+
+	if state.drift != nil && at.After(state.drift.end) {
+		state.drift = nil
+	}
+	if state.drift == nil && chance(rate, r.driftInterval) {
+		state.drift = &drift{
+			start:     at,
+			end:       at.Add(randomDuration(minDriftDuration, maxDriftDuration)),
+			magnitude: randomSign() * randomPoint(minDrift, maxDrift),
+		}
+		labels = append(labels, newLabel(n, storage.AnomalyDrift, storage.TemperatureMetric, at, state.drift.end, state.drift.magnitude))
+	}
+
+	if chance(rate, r.spikeInterval) {
+		state.spike = randomSign() * randomPoint(minSpike, maxSpike)
+		labels = append(labels, newLabel(n, storage.AnomalySpike, storage.TemperatureMetric, at, at, state.spike))
+	}
+
+	if chance(rate, r.shockInterval) {
+		loss := randomPoint(minShockLoss, maxShockLoss)
+		g.population.Shock(n.sensor.ID, loss)
+		labels = append(labels, newLabel(n, storage.AnomalyPopulationShock, storage.FishMetric, at, at, loss))
+	}
+
+	return labels, true
+}
+
+// scenarioStep starts a scenario event around the sensor once the next event is due and
+// labels every sensor in its area, it has to be called under the lock.
+func (g *Generator) scenarioStep(n *regenerateNode, at time.Time) []*storage.AnomalyLabel {
+	interval := g.rules.anomalies.eventInterval
+	if interval <= 0 {
+		return nil
+	}
+
+	active := g.events[:0]
+	for _, e := range g.events {
+		if at.Before(e.end) {
+			active = append(active, e)
+		}
+	}
+	g.events = active
+
+	if g.nextEvent.IsZero() {
+		g.nextEvent = at.Add(time.Duration(random.ExpFloat64() * float64(interval)))
+	}
+	if at.Before(g.nextEvent) {
+		return nil
+	}
+	g.nextEvent = at.Add(time.Duration(random.ExpFloat64() * float64(interval)))
+
+	e := &scenarioEvent{
+		center:       n.coordinate,
+		radius:       randomPoint(minEventRadius, maxEventRadius),
+		start:        at,
+		end:          at.Add(randomDuration(minEventDuration, maxEventDuration)),
+		temperature:  randomPoint(minEventTemperature, maxEventTemperature),
+		transparency: randomPoint(minEventTransparency, maxEventTransparency),
+	}
+	g.events = append(g.events, e)
+
+	var labels []*storage.AnomalyLabel
+	for _, other := range g.listToRegenerate {
+		d := distance(&e.center, &other.coordinate)
+		if d >= e.radius {
+			continue
+		}
+
+		label := newLabel(other, storage.AnomalyScenarioEvent, "", e.start, e.end, e.temperature*(1-(d/e.radius)*(d/e.radius)))
+		label.Detail = "warm turbid water intrusion centered at " + n.sensor.CodeName
+		labels = append(labels, label)
+	}
+
+	return labels
+}
+
+func newLabel(n *regenerateNode, kind storage.AnomalyKind, metric string, start, end time.Time, magnitude float64) *storage.AnomalyLabel {
+	return &storage.AnomalyLabel{
+		CreatedAt: start,
+		EndedAt:   end,
+		SensorId:  n.sensor.ID,
+		Kind:      kind,
+		Metric:    metric,
+		Magnitude: magnitude,
+	}
+}
+
+// chance tells whether an event with the mean interval happens within the rate.
+func chance(rate, interval time.Duration) bool {
+	return interval > 0 && random.Float64() < -math.Expm1(-float64(rate)/float64(interval))
+}
+
+func randomDuration(min, max time.Duration) time.Duration {
+	return min + time.Duration(random.Int63n(int64(max-min)))
+}
+
+func randomSign() float64 {
+	if random.Intn(2) == 0 {
+		return -1
+	}
+
+	return 1
+}
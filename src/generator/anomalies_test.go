@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriftOffset(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := &drift{start: start, end: start.Add(10 * time.Hour), magnitude: 2}
+
+	assert.Equal(t, 0.0, d.offset(start.Add(-time.Hour)))
+	assert.InDelta(t, 1, d.offset(start.Add(5*time.Hour)), 1e-9)
+	assert.InDelta(t, 2, d.offset(start.Add(10*time.Hour)), 1e-9)
+	assert.Equal(t, 0.0, d.offset(start.Add(11*time.Hour)), "the sensor is recalibrated after the drift")
+
+	var none *drift
+	assert.Equal(t, 0.0, none.offset(start))
+}
+
+func TestScenarioEventWeight(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := &scenarioEvent{radius: 100, start: start, end: start.Add(2 * time.Hour)}
+
+	assert.InDelta(t, 1, e.weight(&Coordinate{}, start.Add(time.Hour)), 1e-9, "strongest in the middle and the center")
+	assert.InDelta(t, 0.75, e.weight(&Coordinate{X: 50}, start.Add(time.Hour)), 1e-9)
+	assert.Equal(t, 0.0, e.weight(&Coordinate{X: 100}, start.Add(time.Hour)), "outside the area")
+	assert.Equal(t, 0.0, e.weight(&Coordinate{}, start.Add(3*time.Hour)), "after the end")
+}
+
+func TestAnomalyStep(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newNode := func(id uint, x float64) *regenerateNode {
+		n := &regenerateNode{sensor: &storage.Sensor{DataOutputRate: time.Minute}, coordinate: Coordinate{X: x}}
+		n.sensor.ID = id
+		return n
+	}
+
+	g := &Generator{rules: defaultGeneratorRules(), population: newPopulationModel(nil, 0)}
+	n, far := newNode(1, 0), newNode(2, 10000)
+	g.listToRegenerate = []*regenerateNode{n, far}
+
+	g.rules.anomalies = anomalyRules{dropoutInterval: time.Nanosecond}
+	labels, reports := g.anomalyStep(n, at)
+	assert.False(t, reports)
+	require.Len(t, labels, 1)
+	assert.Equal(t, storage.AnomalyDropout, labels[0].Kind)
+	assert.Equal(t, n.anomalies.dropoutUntil, labels[0].EndedAt)
+
+	g.rules.anomalies = anomalyRules{spikeInterval: time.Nanosecond, driftInterval: time.Nanosecond, shockInterval: time.Nanosecond}
+	labels, reports = g.anomalyStep(n, at.Add(time.Minute))
+	assert.False(t, reports, "the dropout continues")
+	assert.Empty(t, labels)
+
+	labels, reports = g.anomalyStep(n, n.anomalies.dropoutUntil)
+	assert.True(t, reports)
+	kinds := make([]storage.AnomalyKind, 0, len(labels))
+	for _, label := range labels {
+		kinds = append(kinds, label.Kind)
+		assert.Equal(t, n.sensor.ID, label.SensorId)
+	}
+	assert.ElementsMatch(t, []storage.AnomalyKind{storage.AnomalyDrift, storage.AnomalySpike, storage.AnomalyPopulationShock}, kinds)
+	assert.NotZero(t, n.anomalies.spike)
+	require.NotNil(t, n.anomalies.drift)
+
+	g.rules.anomalies = anomalyRules{eventInterval: time.Nanosecond}
+	g.anomalyStep(n, at.Add(time.Hour))
+	labels, reports = g.anomalyStep(n, at.Add(2*time.Hour))
+	assert.True(t, reports)
+	require.Len(t, labels, 1, "the far sensor is out of the event area")
+	assert.Equal(t, storage.AnomalyScenarioEvent, labels[0].Kind)
+	assert.NotEmpty(t, g.events)
+}
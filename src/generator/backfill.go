@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"container/heap"
+	"context"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+// Backfill generates the readings the stored sensors would have reported between from and till,
+// so a new deployment starts with history. The sensors report in the order of time with their
+// data output rates, drain their batteries, fail and produce anomalies as they would live, the
+// updates are timestamped in the past. The hardware is simulated on copies of the sensors fresh
+// from a maintenance at from and is not saved, the stored sensors keep their state. It returns
+// the number of updates and must not run while the generator is started.
+func (g *Generator) Backfill(ctx context.Context, from, till time.Time) (int, error) {
+	g.reset()
+
+	if err := g.prepareSensors(); err != nil {
+		return 0, err
+	}
+
+	writer := g.storage.NewBatchWriter(
+		storage.WithFlushInterval(g.rules.flushInterval),
+		storage.WithFlushSize(g.rules.flushSize),
+	)
+	defer writer.Close()
+
+	nodes := g.sensors()
+	g.lock.Lock()
+	for _, n := range nodes {
+		sensor := *n.sensor
+		sensor.Battery, sensor.FailedAt, sensor.MaintainedAt = storage.FullBattery, nil, &from
+		n.sensor = &sensor
+	}
+	g.lock.Unlock()
+
+	queue := make(backfillQueue, 0, len(nodes))
+	for _, n := range nodes {
+		rate := n.sensor.DataOutputRate
+		if rate <= 0 {
+			rate = time.Second
+		}
+
+		queue = append(queue, &backfillEvent{node: n, rate: rate, at: from.Add(time.Duration(random.Float64() * float64(rate)))})
+	}
+	heap.Init(&queue)
+
+	count := 0
+	for len(queue) > 0 && !queue[0].at.After(till) {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		e := queue[0]
+		update, reports := g.step(e.node, e.at)
+		if update != nil && (reports || update.Labels != nil) {
+			update.Hardware = nil
+			if err := writer.Write(update); err != nil {
+				return count, err
+			}
+		}
+		if reports {
+			count++
+		}
+
+		e.at = e.at.Add(e.rate)
+		heap.Fix(&queue, 0)
+	}
+
+	return count, writer.Flush(ctx)
+}
+
+type backfillEvent struct {
+	node *regenerateNode
+	rate time.Duration
+	at   time.Time
+}
+
+type backfillQueue []*backfillEvent
+
+func (q backfillQueue) Len() int { return len(q) }
+
+func (q backfillQueue) Less(i, j int) bool { return q[i].at.Before(q[j].at) }
+
+func (q backfillQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *backfillQueue) Push(x any) { *q = append(*q, x.(*backfillEvent)) }
+
+func (q *backfillQueue) Pop() any {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+
+	return e
+}
@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"math"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+const (
+	defaultMaxCalibrationOffset = 0.5
+	defaultMaxGainError         = 0.02
+	defaultMinNoiseStd          = 0.1
+	defaultMaxNoiseStd          = 0.3
+	defaultResolution           = 0.1
+)
+
+// calibrationRules decide the calibration new sensors are created with. Offsets and noises are
+// given in Celsius degrees and converted to the unit of the sensor.
+type calibrationRules struct {
+	maxOffset    float64
+	maxGainError float64
+
+	minNoiseStd, maxNoiseStd float64
+
+	// resolution is the step the readings are rounded to in the unit of the sensor.
+	resolution float64
+	units      []storage.TemperatureUnit
+}
+
+func defaultCalibrationRules() calibrationRules {
+	return calibrationRules{
+		maxOffset:    defaultMaxCalibrationOffset,
+		maxGainError: defaultMaxGainError,
+		minNoiseStd:  defaultMinNoiseStd,
+		maxNoiseStd:  defaultMaxNoiseStd,
+		resolution:   defaultResolution,
+		units:        []storage.TemperatureUnit{storage.Celsius},
+	}
+}
+
+// calibration returns the calibration of a new sensor with random errors within the rules.
+func (r *calibrationRules) calibration() storage.Calibration {
+	unit := storage.Celsius
+	if len(r.units) > 0 {
+		unit = r.units[random.Intn(len(r.units))]
+	}
+
+	degree := 1 / unit.Degree()
+	return storage.Calibration{
+		Offset:     randomPoint(-r.maxOffset, r.maxOffset) * degree,
+		Gain:       1 + randomPoint(-r.maxGainError, r.maxGainError),
+		NoiseStd:   randomPoint(r.minNoiseStd, r.maxNoiseStd) * degree,
+		Resolution: r.resolution,
+		Unit:       unit,
+	}
+}
+
+// measure returns the temperature the sensor reads in Celsius for the true one, noise scales
+// the noise of the sensor up and offset is the error of its anomalies.
+func measure(sensor *storage.Sensor, temperature, noise, offset float64) float64 {
+	measured := sensor.Calibration.MeasureCelsius(temperature, random.NormFloat64()*noise) + offset
+	return math.Max(minTemperature, math.Min(maxTemperature, measured))
+}
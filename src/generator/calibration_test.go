@@ -0,0 +1,31 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalibrationRules(t *testing.T) {
+	r := calibrationRules{
+		maxOffset:    0.5,
+		maxGainError: 0.02,
+		minNoiseStd:  0.1,
+		maxNoiseStd:  0.3,
+		resolution:   0.1,
+		units:        []storage.TemperatureUnit{storage.Fahrenheit},
+	}
+
+	for i := 0; i < 100; i++ {
+		c := r.calibration()
+		assert.Equal(t, storage.Fahrenheit, c.Unit)
+		assert.Equal(t, 0.1, c.Resolution)
+		assert.LessOrEqual(t, c.Offset, 0.5*1.8+1e-9, "the offset is converted to the unit")
+		assert.GreaterOrEqual(t, c.Offset, -0.5*1.8-1e-9)
+		assert.InDelta(t, 1, c.Gain, 0.02)
+		assert.GreaterOrEqual(t, c.NoiseStd, 0.1*1.8-1e-9)
+		assert.LessOrEqual(t, c.NoiseStd, 0.3*1.8+1e-9)
+	}
+}
@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	defaultLatitude  = 45.0
+	defaultLongitude = 0.0
+
+	defaultDiurnalAmplitude = 1.5
+	defaultDiurnalDepth     = 15.0
+	diurnalPeakHour         = 15.0
+
+	defaultSeasonalAmplitude = 8.0
+	defaultSeasonalDepth     = 120.0
+	seasonalPeakDay          = 227.0 // mid August, surface water is the warmest in the northern hemisphere
+
+	defaultTideAmplitude = 12.0
+	defaultTideDepth     = 60.0
+	defaultTidePeriod    = 12*time.Hour + 25*time.Minute
+	springNeapPeriod     = 14*24*time.Hour + 18*time.Hour
+
+	daysInYear = 365.25
+)
+
+// cycleModel adds periodic components to the environment field: daily solar heating,
+// seasonal temperature curve and tide driven turbidity. Every component fades with depth.
+type cycleModel struct {
+	latitude, longitude float64
+	// seasonShift moves the wall clock to the simulated date.
+	seasonShift time.Duration
+
+	diurnalAmplitude, diurnalDepth   float64
+	seasonalAmplitude, seasonalDepth float64
+
+	tideAmplitude, tideDepth float64
+	tidePeriod               time.Duration
+}
+
+func defaultCycleModel() *cycleModel {
+	return &cycleModel{
+		latitude:          defaultLatitude,
+		longitude:         defaultLongitude,
+		diurnalAmplitude:  defaultDiurnalAmplitude,
+		diurnalDepth:      defaultDiurnalDepth,
+		seasonalAmplitude: defaultSeasonalAmplitude,
+		seasonalDepth:     defaultSeasonalDepth,
+		tideAmplitude:     defaultTideAmplitude,
+		tideDepth:         defaultTideDepth,
+		tidePeriod:        defaultTidePeriod,
+	}
+}
+
+func (cm *cycleModel) Temperature(c *Coordinate, at time.Time) float64 {
+	at = at.Add(cm.seasonShift).UTC()
+	d := depth(c.Z)
+
+	return cm.seasonal(at)*math.Exp(-d/cm.seasonalDepth) + cm.diurnal(at)*math.Exp(-d/cm.diurnalDepth)
+}
+
+func (cm *cycleModel) Transparency(c *Coordinate, at time.Time) float64 {
+	return cm.tide(at.Add(cm.seasonShift)) * math.Exp(-depth(c.Z)/cm.tideDepth)
+}
+
+// seasonal is the annual temperature anomaly, its amplitude grows with latitude and
+// the phase is inverted in the southern hemisphere.
+func (cm *cycleModel) seasonal(at time.Time) float64 {
+	lat := cm.latitude * math.Pi / 180
+	amplitude := cm.seasonalAmplitude * (0.15 + 0.85*math.Abs(math.Sin(lat)))
+
+	peak := seasonalPeakDay
+	if cm.latitude < 0 {
+		peak -= daysInYear / 2
+	}
+
+	day := float64(at.YearDay()) + float64(at.Hour())/24
+	return amplitude * math.Cos(2*math.Pi*(day-peak)/daysInYear)
+}
+
+// diurnal is the daily heating of the surface layer by the sun, it peaks in the afternoon
+// of the local solar time and is stronger in summer.
+func (cm *cycleModel) diurnal(at time.Time) float64 {
+	hour := float64(at.Hour()) + float64(at.Minute())/60 + cm.longitude/15
+	summer := 1.0
+	if cm.seasonalAmplitude > 0 {
+		summer += 0.5 * cm.seasonal(at) / cm.seasonalAmplitude
+	}
+
+	return cm.diurnalAmplitude * summer * math.Cos(2*math.Pi*(hour-diurnalPeakHour)/24)
+}
+
+// tide lowers transparency while tidal currents stir sediments up, twice per tidal period,
+// stronger on spring tides.
+func (cm *cycleModel) tide(at time.Time) float64 {
+	t := float64(at.UnixNano())
+	current := math.Abs(math.Sin(2 * math.Pi * t / float64(cm.tidePeriod)))
+	springNeap := 1 + 0.3*math.Cos(2*math.Pi*t/float64(springNeapPeriod))
+
+	return -cm.tideAmplitude * current * springNeap
+}
@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeasonalCycle(t *testing.T) {
+	cm := defaultCycleModel()
+	amplitude := defaultSeasonalAmplitude * (0.15 + 0.85*math.Sin(defaultLatitude*math.Pi/180))
+
+	peak := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(seasonalPeakDay)-1)
+	assert.InDelta(t, amplitude, cm.seasonal(peak), 1e-9, "the warmest in mid August")
+	assert.InDelta(t, -amplitude, cm.seasonal(peak.AddDate(0, 6, 0)), 0.05, "the coldest half a year later")
+	assert.InDelta(t, 0, cm.seasonal(peak.AddDate(0, 3, 0)), 0.3)
+
+	cm.latitude = -defaultLatitude
+	assert.InDelta(t, -amplitude, cm.seasonal(peak), 0.05, "the phase is inverted in the southern hemisphere")
+
+	cm.latitude = 0
+	assert.InDelta(t, 0.15*defaultSeasonalAmplitude, cm.seasonal(peak), 1e-9, "the amplitude is the smallest at the equator")
+}
+
+func TestDiurnalCycle(t *testing.T) {
+	cm := defaultCycleModel()
+	cm.seasonalAmplitude = 0
+	day := time.Date(2023, 8, 15, 0, 0, 0, 0, time.UTC)
+
+	assert.InDelta(t, defaultDiurnalAmplitude, cm.diurnal(day.Add(15*time.Hour)), 1e-9, "the peak is in the afternoon")
+	assert.InDelta(t, -defaultDiurnalAmplitude, cm.diurnal(day.Add(3*time.Hour)), 1e-9)
+	assert.InDelta(t, 0, cm.diurnal(day.Add(9*time.Hour)), 1e-9)
+
+	cm.longitude = 90
+	assert.InDelta(t, defaultDiurnalAmplitude, cm.diurnal(day.Add(9*time.Hour)), 1e-9, "the peak follows the local solar time")
+}
+
+func TestCyclesDepthAttenuation(t *testing.T) {
+	cm := defaultCycleModel()
+	cm.seasonalAmplitude = 0
+	at := time.Date(2023, 8, 15, 15, 0, 0, 0, time.UTC)
+
+	surface := &Coordinate{Z: defaultMinZ}
+	assert.InDelta(t, defaultDiurnalAmplitude, cm.Temperature(surface, at), 1e-9)
+	assert.InDelta(t, defaultDiurnalAmplitude/math.E, cm.Temperature(&Coordinate{Z: defaultMinZ + defaultDiurnalDepth}, at), 1e-9)
+	assert.InDelta(t, 0, cm.Temperature(&Coordinate{Z: 0}, at), 1e-9, "deep water does not feel the sun")
+
+	tideAt := time.Unix(0, int64(defaultTidePeriod/4))
+	tide := cm.Transparency(surface, tideAt)
+	assert.Less(t, tide, 0.0, "tidal currents make the water turbid")
+	assert.InDelta(t, tide/math.E, cm.Transparency(&Coordinate{Z: defaultMinZ + defaultTideDepth}, tideAt), 1e-9)
+	assert.InDelta(t, 0, cm.Transparency(surface, time.Unix(0, 0)), 1e-9, "slack water")
+}
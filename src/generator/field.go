@@ -0,0 +1,179 @@
+package generator
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	defaultSurfaceTemperature   = 22.0
+	defaultDeepTemperature      = 4.0
+	defaultThermoclineDepth     = 300.0
+	defaultThermoclineThickness = 120.0
+
+	defaultTemperatureGradientX = 0.002
+	defaultTemperatureGradientY = -0.003
+	defaultTemperatureNoise     = 2.5
+
+	defaultSurfaceTransparency = 35.0
+	defaultDeepTransparency    = 85.0
+	defaultTransparencyNoise   = 15.0
+
+	defaultHorizontalScale = 600.0
+	defaultVerticalScale   = 250.0
+	defaultFieldEvolution  = 6 * time.Hour
+
+	fieldOctaves = 3
+)
+
+// fieldModel is a continuous environment every sensor samples its "true" readings from.
+// Temperature is a thermocline profile over depth with a horizontal gradient, transparency
+// grows with depth, and both are disturbed by smooth noise drifting over time and by
+// periodic cycles, so close sensors read close values.
+type fieldModel struct {
+	seed uint64
+
+	surfaceTemperature, deepTemperature    float64
+	thermoclineDepth, thermoclineThickness float64
+	gradientX, gradientY                   float64
+	temperatureNoise                       float64
+
+	surfaceTransparency, deepTransparency float64
+	transparencyNoise                     float64
+
+	horizontalScale, verticalScale float64
+	evolution                      time.Duration
+
+	cycles *cycleModel
+}
+
+func defaultFieldModel() *fieldModel {
+	return &fieldModel{
+		seed:                 uint64(time.Now().UnixNano()),
+		surfaceTemperature:   defaultSurfaceTemperature,
+		deepTemperature:      defaultDeepTemperature,
+		thermoclineDepth:     defaultThermoclineDepth,
+		thermoclineThickness: defaultThermoclineThickness,
+		gradientX:            defaultTemperatureGradientX,
+		gradientY:            defaultTemperatureGradientY,
+		temperatureNoise:     defaultTemperatureNoise,
+		surfaceTransparency:  defaultSurfaceTransparency,
+		deepTransparency:     defaultDeepTransparency,
+		transparencyNoise:    defaultTransparencyNoise,
+		horizontalScale:      defaultHorizontalScale,
+		verticalScale:        defaultVerticalScale,
+		evolution:            defaultFieldEvolution,
+		cycles:               defaultCycleModel(),
+	}
+}
+
+// depth converts the Z coordinate to the depth below the surface, Z grows downwards from defaultMinZ.
+func depth(z float64) float64 {
+	d := z - defaultMinZ
+	if d < 0 {
+		return 0
+	}
+
+	return d
+}
+
+// mixedLayer is 1 above the thermocline and falls to 0 below it.
+func (f *fieldModel) mixedLayer(z float64) float64 {
+	return 0.5 * (1 - math.Tanh((depth(z)-f.thermoclineDepth)/f.thermoclineThickness))
+}
+
+func (f *fieldModel) Temperature(c *Coordinate, at time.Time) float64 {
+	mixed := f.mixedLayer(c.Z)
+
+	t := f.deepTemperature + (f.surfaceTemperature-f.deepTemperature)*mixed
+	t += (f.gradientX*c.X + f.gradientY*c.Y) * mixed
+	t += f.temperatureNoise * (0.2 + 0.8*mixed) * f.noise(c, at, 0)
+	t += f.cycles.Temperature(c, at)
+
+	return math.Max(minTemperature, math.Min(maxTemperature, t))
+}
+
+func (f *fieldModel) Transparency(c *Coordinate, at time.Time) float64 {
+	mixed := f.mixedLayer(c.Z)
+
+	t := f.deepTransparency + (f.surfaceTransparency-f.deepTransparency)*mixed
+	t += f.transparencyNoise * f.noise(c, at, 1)
+	t += f.cycles.Transparency(c, at)
+
+	return math.Max(0, math.Min(100, t))
+}
+
+// noise returns fractal value noise in [-1, 1] for the coordinate and moment, channel separates
+// independent fields sampled at the same point.
+func (f *fieldModel) noise(c *Coordinate, at time.Time, channel uint64) float64 {
+	x := c.X / f.horizontalScale
+	y := c.Y / f.horizontalScale
+	z := depth(c.Z) / f.verticalScale
+	w := float64(at.UnixNano()) / float64(f.evolution)
+
+	sum, amplitude, norm := 0.0, 1.0, 0.0
+	for octave := uint64(0); octave < fieldOctaves; octave++ {
+		sum += amplitude * f.valueNoise(x, y, z, w, channel*fieldOctaves+octave)
+		norm += amplitude
+
+		amplitude /= 2
+		x, y, z, w = x*2, y*2, z*2, w*2
+	}
+
+	return sum / norm
+}
+
+// valueNoise interpolates pseudo random lattice values over four dimensions.
+func (f *fieldModel) valueNoise(x, y, z, w float64, layer uint64) float64 {
+	p := [4]float64{x, y, z, w}
+	var cell [4]int64
+	var frac [4]float64
+	for i, v := range p {
+		fl := math.Floor(v)
+		cell[i] = int64(fl)
+		frac[i] = smoothStep(v - fl)
+	}
+
+	result := 0.0
+	for corner := 0; corner < 16; corner++ {
+		weight := 1.0
+		var lattice [4]int64
+		for i := 0; i < 4; i++ {
+			if corner&(1<<i) != 0 {
+				lattice[i] = cell[i] + 1
+				weight *= frac[i]
+			} else {
+				lattice[i] = cell[i]
+				weight *= 1 - frac[i]
+			}
+		}
+
+		result += weight * f.latticeValue(lattice, layer)
+	}
+
+	return result
+}
+
+func (f *fieldModel) latticeValue(lattice [4]int64, layer uint64) float64 {
+	h := f.seed ^ (layer * 0x9e3779b97f4a7c15)
+	for _, v := range lattice {
+		h ^= uint64(v) + 0x9e3779b97f4a7c15 + (h << 6) + (h >> 2)
+		h = splitMix(h)
+	}
+
+	return float64(h>>11)/float64(1<<53)*2 - 1
+}
+
+func splitMix(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+
+	return h
+}
+
+func smoothStep(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldDeterministicBySeed(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	points := []*Coordinate{{X: 0, Y: 0, Z: defaultMinZ}, {X: 120, Y: -340, Z: -700}, {X: 5000, Y: 2500, Z: -50}}
+
+	f, same, other := defaultFieldModel(), defaultFieldModel(), defaultFieldModel()
+	f.seed, same.seed, other.seed = 42, 42, 43
+
+	differs := false
+	for _, c := range points {
+		assert.Equal(t, f.Temperature(c, at), same.Temperature(c, at))
+		assert.Equal(t, f.Transparency(c, at), same.Transparency(c, at))
+		differs = differs || f.noise(c, at, 0) != other.noise(c, at, 0)
+	}
+	assert.True(t, differs, "another seed gives another field")
+}
+
+func TestFieldSpatialCoherence(t *testing.T) {
+	f := defaultFieldModel()
+	f.seed = 7
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	r := rand.New(rand.NewSource(1))
+
+	// near and far are the mean differences of the noise between points 10m and 5km apart.
+	near, far := 0.0, 0.0
+	const samples = 500
+	for i := 0; i < samples; i++ {
+		c := &Coordinate{X: r.Float64() * 10000, Y: r.Float64() * 10000, Z: -r.Float64() * 1000}
+		v := f.noise(c, at, 0)
+
+		near += math.Abs(v - f.noise(&Coordinate{X: c.X + 10, Y: c.Y, Z: c.Z}, at, 0))
+		far += math.Abs(v - f.noise(&Coordinate{X: c.X + 5000, Y: c.Y, Z: c.Z}, at, 0))
+	}
+
+	assert.Less(t, near/samples, 0.05)
+	assert.Greater(t, far/samples, 4*near/samples, "nearby points are closer than distant ones")
+}
+
+func TestFieldProfile(t *testing.T) {
+	f := defaultFieldModel()
+	f.temperatureNoise, f.transparencyNoise = 0, 0
+	f.cycles.diurnalAmplitude, f.cycles.seasonalAmplitude, f.cycles.tideAmplitude = 0, 0, 0
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	surface, deep := &Coordinate{Z: defaultMinZ}, &Coordinate{Z: 0}
+	assert.InDelta(t, defaultSurfaceTemperature, f.Temperature(surface, at), 0.5)
+	assert.InDelta(t, defaultDeepTemperature, f.Temperature(deep, at), 0.5)
+	assert.Less(t, f.Transparency(surface, at), f.Transparency(deep, at), "deep water is clearer")
+}
@@ -2,7 +2,9 @@ package generator
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"math"
 	"math/rand"
 	"runtime"
 	"sync"
@@ -14,8 +16,6 @@ import (
 )
 
 const (
-	defaultTransparencyInfelicity = uint8(10)
-
 	defaultMinSensorsCount = 2
 	defaultMaxSensorsCount = 10
 
@@ -23,7 +23,15 @@ const (
 	defaultMaxDataOutputRate = 1200
 
 	defaultFishListLength = 10
-	defaultMaxFishCount   = 10
+
+	defaultNeighboursCount    = 4
+	defaultSensorSyncInterval = time.Minute
+
+	defaultFlushInterval = time.Second
+	defaultFlushSize     = 1000
+
+	// seedLock serialises seeding of the sensor groups between instances.
+	seedLock = "fake-sensors/seed"
 
 	minTemperature = -273.17
 	maxTemperature = 56.7
@@ -37,9 +45,8 @@ const (
 	defaultMinZ = -1000.0
 	defaultMaxZ = 1000.0
 
-	temperatureSum               = (minTemperature - maxTemperature) * -1
-	tempPerPoint                 = temperatureSum / defaultMaxZ
-	allowedTemperatureDifference = 3
+	transparencyMeasurementError = 1.5
+	transparencyNeighbourWeight  = 0.2
 )
 
 var (
@@ -49,7 +56,7 @@ var (
 		"pi", "rho", "sigma", "tau", "upsilon", "phi", "chi", "psi", "omega",
 	}
 
-	random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	random = rand.New(&lockedSource{src: rand.NewSource(time.Now().UnixNano()).(rand.Source64)})
 
 	maxProc = runtime.GOMAXPROCS(0)
 )
@@ -59,42 +66,100 @@ type generatorRules struct {
 	minSensorsCount, maxSensorsCount     uint16
 	minDataOutputRate, maxDataOutputRate uint
 
+	neighboursCount    int
+	sensorSyncInterval time.Duration
+
+	flushInterval time.Duration
+	flushSize     int
+
+	jitter  float64
+	catchUp CatchUpPolicy
+
+	fishListLength int
+	// minBounds and maxBounds limit the coordinates new sensors are placed at.
+	minBounds, maxBounds Coordinate
+
+	field       *fieldModel
+	hardware    hardwareRules
+	calibration calibrationRules
+	anomalies   anomalyRules
+	replay      *replayRules
+
 	fishNames []string
 }
 
 func defaultGeneratorRules() *generatorRules {
 	return &generatorRules{
-		groupsCount:       uint16(len(greekLetters)),
-		minSensorsCount:   defaultMinSensorsCount,
-		maxSensorsCount:   defaultMaxSensorsCount,
-		minDataOutputRate: defaultMinDataOutputRate,
-		maxDataOutputRate: defaultMaxDataOutputRate,
-		fishNames:         []string{},
+		groupsCount:        uint16(len(greekLetters)),
+		minSensorsCount:    defaultMinSensorsCount,
+		maxSensorsCount:    defaultMaxSensorsCount,
+		minDataOutputRate:  defaultMinDataOutputRate,
+		maxDataOutputRate:  defaultMaxDataOutputRate,
+		neighboursCount:    defaultNeighboursCount,
+		sensorSyncInterval: defaultSensorSyncInterval,
+		flushInterval:      defaultFlushInterval,
+		flushSize:          defaultFlushSize,
+		jitter:             defaultJitter,
+		catchUp:            CatchUpSkip,
+		fishListLength:     defaultFishListLength,
+		minBounds:          Coordinate{X: defaultMinX, Y: defaultMinY, Z: defaultMinZ},
+		maxBounds:          Coordinate{X: defaultMaxX, Y: defaultMaxY, Z: defaultMaxZ},
+		field:              defaultFieldModel(),
+		hardware:           defaultHardwareRules(),
+		calibration:        defaultCalibrationRules(),
+		fishNames:          []string{},
 	}
 }
 
 type regenerateNode struct {
+	// sensor is replaced under the lock rather than changed, so the updates keep the sensor
+	// they were made for, see Generator.sensorOf.
 	sensor *storage.Sensor
 
 	previousUpdate time.Time
+	schedule       schedule
 
-	nearestTransparency uint8
 	currentTransparency uint8
+	anomalies           anomalyState
+
+	coordinate Coordinate
+	radius     float64
+	neighbours []*regenerateNode
+	dependents map[*regenerateNode]struct{}
 }
 
 type Generator struct {
 	rules *generatorRules
 
 	storage *storage.Storage
+	writer  *storage.BatchWriter
 
+	lock             sync.RWMutex
+	index            *sensorIndex
 	listToRegenerate []*regenerateNode
 	regenerateCh     chan *regenerateNode
+	scheduler        *scheduler
+
+	population *populationModel
 
+	// events are the active scenario events, nextEvent is when the next one starts.
+	events    []*scenarioEvent
+	nextEvent time.Time
+
+	// owned are the groups this generator is responsible for, nil means all groups.
+	owned map[uint64]struct{}
+	// sensorsVersion changes with listToRegenerate, so the replay maps the recorded sensors again.
+	sensorsVersion uint64
+
+	wg         sync.WaitGroup
 	cancelFunc context.CancelFunc
 }
 
-func NewGenerator(storage *storage.Storage) (*Generator, error) {
+func NewGenerator(storage *storage.Storage, opts ...DataOption) (*Generator, error) {
 	rules := defaultGeneratorRules()
+	for _, opt := range opts {
+		opt(rules)
+	}
 
 	fishNames, err := ParseFishNames()
 	if err != nil {
@@ -102,40 +167,112 @@ func NewGenerator(storage *storage.Storage) (*Generator, error) {
 	}
 	rules.fishNames = fishNames
 
+	if rules.replay != nil {
+		if err = rules.replay.check(); err != nil {
+			return nil, fmt.Errorf("cannot replay %s: %w", rules.replay.path, err)
+		}
+	}
+
 	generator := &Generator{
 		rules:            rules,
 		storage:          storage,
+		index:            newSensorIndex(rules.neighboursCount),
 		listToRegenerate: make([]*regenerateNode, 0, rules.groupsCount*rules.maxSensorsCount),
 		regenerateCh:     make(chan *regenerateNode, rules.groupsCount*rules.maxSensorsCount/2),
+		scheduler:        newScheduler(rules.jitter, rules.catchUp),
+		population:       newPopulationModel(rules.fishNames, rules.fishListLength),
 	}
 
 	return generator, nil
 }
 
+// Start begins the data generation, the generator may be started again after Stop and
+// continues from the stored state then.
 func (g *Generator) Start(ctx context.Context) error {
+	if g.rules.replay != nil {
+		if err := g.rules.replay.check(); err != nil {
+			return fmt.Errorf("cannot replay %s: %w", g.rules.replay.path, err)
+		}
+	}
+
 	childCtx, cancel := context.WithCancel(ctx)
 	g.cancelFunc = cancel
 
-	groups, err := g.storage.GetAllGroups()
-	if err != nil {
-		return err
-	}
+	g.reset()
 
-	if len(groups) == 0 {
-		g.generateSensorGroups()
+	if _, err := g.Seed(ctx); err != nil {
+		return err
 	}
 
-	err = g.prepareSensors()
+	err := g.prepareSensors()
 	if err != nil {
 		return err
 	}
 
+	// Started by a leader the writer stops saving once the leadership is lost.
+	g.writer = g.storage.NewBatchWriter(
+		storage.WithFlushInterval(g.rules.flushInterval),
+		storage.WithFlushSize(g.rules.flushSize),
+		storage.WithLeaderContext(ctx),
+	)
+
+	if g.rules.replay != nil {
+		g.wg.Add(1)
+		go func() {
+			defer g.wg.Done()
+			g.replay(childCtx)
+		}()
+		return nil
+	}
+
 	g.startMonitoring(childCtx)
 	return nil
 }
 
+// Seed creates the sensor groups with their sensors unless the database has groups already and
+// reports whether they were created.
+func (g *Generator) Seed(ctx context.Context) (bool, error) {
+	created := false
+	err := g.storage.Exclusive(ctx, seedLock, func() error {
+		groups, err := g.storage.GetAllGroups()
+		if err != nil {
+			return err
+		}
+
+		if len(groups) == 0 {
+			g.generateSensorGroups()
+			created = true
+		}
+		return nil
+	})
+
+	return created, err
+}
+
+// Stop stops the data generation and saves the updates which are not written yet.
 func (g *Generator) Stop() {
 	g.cancelFunc()
+	g.wg.Wait()
+
+	if g.writer != nil {
+		if err := g.writer.Close(); err != nil {
+			log.Printf("cannot save sensor updates: %s\n", err)
+		}
+	}
+}
+
+// reset drops the state of the previous run, another instance may have changed the data since then.
+func (g *Generator) reset() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.index = newSensorIndex(g.rules.neighboursCount)
+	g.listToRegenerate = make([]*regenerateNode, 0, cap(g.listToRegenerate))
+	g.sensorsVersion++
+	g.regenerateCh = make(chan *regenerateNode, cap(g.regenerateCh))
+	g.scheduler = newScheduler(g.rules.jitter, g.rules.catchUp)
+	g.population = newPopulationModel(g.rules.fishNames, g.rules.fishListLength)
+	g.events, g.nextEvent = nil, time.Time{}
 }
 
 func (g *Generator) prepareSensors() error {
@@ -144,13 +281,211 @@ func (g *Generator) prepareSensors() error {
 		return err
 	}
 
-	for _, sensor := range sortSensors(sensors) {
-		g.listToRegenerate = append(g.listToRegenerate, &regenerateNode{sensor: sensor})
+	fishes, err := g.storage.GetCurrentFishes()
+	if err != nil {
+		return err
 	}
+	g.population.Restore(fishes)
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for _, sensor := range sensors {
+		if g.owns(sensor) {
+			n := &regenerateNode{sensor: sensor}
+			g.listToRegenerate = append(g.listToRegenerate, n)
+			g.scheduler.Add(n, sensor.DataOutputRate)
+		}
+	}
+	g.index.Build(g.listToRegenerate)
+	g.sensorsVersion++
 
 	return nil
 }
 
+// AddSensor starts monitoring of the already stored sensor or moves it if the sensor is monitored already.
+func (g *Generator) AddSensor(sensor *storage.Sensor) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if n, ok := g.index.Get(sensor.ID); ok {
+		n.sensor = sensor
+		g.index.Move(n)
+		return
+	}
+
+	n := &regenerateNode{sensor: sensor}
+	g.index.Insert(n)
+	g.listToRegenerate = append(g.listToRegenerate, n)
+	g.sensorsVersion++
+	g.scheduler.Add(n, sensor.DataOutputRate)
+}
+
+// MoveSensor changes coordinates of the monitored sensor.
+func (g *Generator) MoveSensor(id uint, c Coordinate) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	n, ok := g.index.Get(id)
+	if !ok {
+		return false
+	}
+
+	moved := *n.sensor
+	moved.X, moved.Y, moved.Z = c.X, c.Y, c.Z
+	n.sensor = &moved
+	g.index.Move(n)
+
+	return true
+}
+
+// RemoveSensor stops monitoring of the sensor.
+func (g *Generator) RemoveSensor(id uint) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	n, ok := g.index.Remove(id)
+	if !ok {
+		return false
+	}
+	g.population.Remove(id)
+	g.scheduler.Remove(n)
+
+	for i, node := range g.listToRegenerate {
+		if node == n {
+			g.listToRegenerate = append(g.listToRegenerate[:i], g.listToRegenerate[i+1:]...)
+			g.sensorsVersion++
+			break
+		}
+	}
+
+	return true
+}
+
+// syncSensors applies sensors added, moved or removed in the storage since the previous sync
+// and the changes of the owned groups.
+func (g *Generator) syncSensors() error {
+	sensors, err := g.storage.GetAllSensors()
+	if err != nil {
+		return err
+	}
+
+	stored := make(map[uint]struct{}, len(sensors))
+	added := make(map[uint64]struct{})
+	for _, sensor := range sensors {
+		g.lock.RLock()
+		owned := g.owns(sensor)
+		n, ok := g.index.Get(sensor.ID)
+		moved := ok && n.coordinate != sensorCoordinate(sensor)
+		g.lock.RUnlock()
+
+		if !owned {
+			continue
+		}
+		stored[sensor.ID] = struct{}{}
+
+		if !ok {
+			g.AddSensor(sensor)
+			added[uint64(sensor.ID)] = struct{}{}
+		} else if moved {
+			g.MoveSensor(sensor.ID, sensorCoordinate(sensor))
+		}
+	}
+
+	if err = g.restorePopulation(added); err != nil {
+		return err
+	}
+
+	g.lock.RLock()
+	removed := make([]uint, 0)
+	for _, n := range g.listToRegenerate {
+		if _, ok := stored[n.sensor.ID]; !ok {
+			removed = append(removed, n.sensor.ID)
+		}
+	}
+	g.lock.RUnlock()
+
+	for _, id := range removed {
+		g.RemoveSensor(id)
+	}
+
+	return nil
+}
+
+// restorePopulation continues the fish population of the sensors taken over from another worker.
+func (g *Generator) restorePopulation(sensors map[uint64]struct{}) error {
+	if len(sensors) == 0 {
+		return nil
+	}
+
+	fishes, err := g.storage.GetCurrentFishes()
+	if err != nil {
+		return err
+	}
+
+	restored := make([]*storage.Fish, 0, len(fishes))
+	for _, fish := range fishes {
+		if _, ok := sensors[fish.SensorId]; ok {
+			restored = append(restored, fish)
+		}
+	}
+	g.population.Restore(restored)
+
+	return nil
+}
+
+// sample measures the environment field at the sensor position changed by the active scenario
+// events. It returns the true temperature and the one read through the calibration of the
+// sensor with the errors of its anomalies. Transparency is smoothed towards the latest readings
+// of the nearest sensors, the measurements of sensors with a low battery are noisier.
+func (g *Generator) sample(n *regenerateNode, at time.Time) (float64, float64, uint8) {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	noise := g.rules.hardware.noiseFactor(n.sensor.Battery)
+	dT, dTr := g.eventEffect(&n.coordinate, at)
+	temperature := math.Max(minTemperature, math.Min(maxTemperature, g.rules.field.Temperature(&n.coordinate, at)+dT))
+	transparency := g.rules.field.Transparency(&n.coordinate, at) + dTr
+
+	sum, count := 0.0, 0
+	for _, neighbour := range n.neighbours {
+		if neighbour.previousUpdate.IsZero() {
+			continue
+		}
+
+		sum += float64(neighbour.currentTransparency)
+		count++
+	}
+
+	if count > 0 {
+		transparency = (1-transparencyNeighbourWeight)*transparency + transparencyNeighbourWeight*sum/float64(count)
+	}
+	transparency += random.NormFloat64() * transparencyMeasurementError * noise
+
+	return temperature, measure(n.sensor, temperature, noise, n.anomalies.offset(at)), uint8(math.Round(math.Max(0, math.Min(100, transparency))))
+}
+
+// observeFishes advances the fish population around the sensor and its neighbours.
+func (g *Generator) observeFishes(n *regenerateNode, at time.Time) []*storage.Fish {
+	g.lock.RLock()
+	h := g.habitat(n, at)
+	neighbours := make([]habitat, 0, len(n.neighbours))
+	for _, neighbour := range n.neighbours {
+		neighbours = append(neighbours, g.habitat(neighbour, at))
+	}
+	g.lock.RUnlock()
+
+	return g.population.Step(h, neighbours)
+}
+
+func (g *Generator) habitat(n *regenerateNode, at time.Time) habitat {
+	return habitat{
+		sensorId:    n.sensor.ID,
+		temperature: g.rules.field.Temperature(&n.coordinate, at),
+		depth:       depth(n.coordinate.Z),
+	}
+}
+
 func (g *Generator) generateSensorGroups() {
 	letters := shuffleArray(greekLetters)
 
@@ -186,116 +521,152 @@ func (g *Generator) generateSensors() []*storage.Sensor {
 		sensors = append(sensors, &storage.Sensor{
 			Model:          gorm.Model{},
 			IndexInGroup:   uint64(i),
-			X:              randomPoint(defaultMinX, defaultMaxX),
-			Y:              randomPoint(defaultMinY, defaultMaxY),
-			Z:              randomPoint(defaultMinZ, defaultMaxZ),
+			X:              randomPoint(g.rules.minBounds.X, g.rules.maxBounds.X),
+			Y:              randomPoint(g.rules.minBounds.Y, g.rules.maxBounds.Y),
+			Z:              randomPoint(g.rules.minBounds.Z, g.rules.maxBounds.Z),
 			DataOutputRate: time.Second * time.Duration(dataOutputRate),
+			Calibration:    g.rules.calibration.calibration(),
 		})
 	}
 
 	return sensors
 }
 
-func (g *Generator) regenerateData(ctx context.Context) {
-	var err error
+// save queues the update, it fails only once the generator is stopped.
+func (g *Generator) save(update *storage.SensorUpdate) {
+	if err := g.writer.Write(update); err != nil {
+		log.Printf("cannot save update of sensor %d: %s\n", update.Sensor.ID, err)
+	}
+}
+
+func (g *Generator) regenerateData(ctx context.Context, regenerateCh <-chan *regenerateNode) {
+	defer g.wg.Done()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case n, ok := <-g.regenerateCh:
-			if !ok {
-				return
+		case n := <-regenerateCh:
+			now := time.Now()
+			if update, _ := g.step(n, now); update != nil {
+				g.save(update)
 			}
 
-			transparency := &storage.Transparency{
-				SensorId:     uint64(n.sensor.ID),
-				Transparency: randomTransparency(n.nearestTransparency),
-			}
-			err = g.storage.UpdateSensorData(
-				n.sensor,
-				g.newRandomFishList(uint64(n.sensor.ID), defaultFishListLength),
-				&storage.Temperature{
-					SensorId:    uint64(n.sensor.ID),
-					Temperature: randomTemperature(n.sensor.Z),
-				},
-				transparency,
-			)
-			if err != nil {
-				log.Printf("cannot save temperature for %d sensor: %s\n", n.sensor.ID, err)
-				continue
-			}
+			g.lock.RLock()
+			rate := n.sensor.DataOutputRate
+			g.lock.RUnlock()
 
-			n.currentTransparency = transparency.Transparency
-			n.previousUpdate = time.Now()
+			g.scheduler.Done(n, rate, now)
 		}
 	}
 }
 
+// step advances the hardware and the anomalies of the sensor to the report due at the moment
+// and samples the readings if the sensor reports. It returns the update to save, nil if there
+// is nothing to save, and whether the sensor reported.
+func (g *Generator) step(n *regenerateNode, at time.Time) (*storage.SensorUpdate, bool) {
+	hardware, labels, reports := g.hardwareStep(n, at)
+
+	if reports {
+		var anomalies []*storage.AnomalyLabel
+		anomalies, reports = g.anomalyStep(n, at)
+		labels = append(labels, anomalies...)
+	}
+
+	if !reports {
+		if hardware == nil && labels == nil {
+			return nil, false
+		}
+
+		return &storage.SensorUpdate{Sensor: g.sensorOf(n), Hardware: hardware, Labels: labels}, false
+	}
+
+	update := g.report(n, at)
+	update.Hardware, update.Labels = hardware, labels
+	return update, true
+}
+
+// report samples the readings of the sensor at the moment, the readings are timestamped with it.
+func (g *Generator) report(n *regenerateNode, at time.Time) *storage.SensorUpdate {
+	sensor := g.sensorOf(n)
+	model := gorm.Model{CreatedAt: at, UpdatedAt: at}
+	trueT, t, tr := g.sample(n, at)
+
+	fishes := g.observeFishes(n, at)
+	for _, fish := range fishes {
+		fish.Model = model
+	}
+
+	g.lock.Lock()
+	n.currentTransparency = tr
+	n.previousUpdate = at
+	g.lock.Unlock()
+
+	return &storage.SensorUpdate{
+		Sensor:       sensor,
+		Fishes:       fishes,
+		Temperature:  &storage.Temperature{Model: model, SensorId: uint64(sensor.ID), Temperature: t, TrueTemperature: &trueT},
+		Transparency: &storage.Transparency{Model: model, SensorId: uint64(sensor.ID), Transparency: tr},
+	}
+}
+
+// sensorOf returns the current sensor of the node, it is never changed once returned.
+func (g *Generator) sensorOf(n *regenerateNode) *storage.Sensor {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	return n.sensor
+}
+
+// Lag returns the scheduling metrics of the monitored sensors.
+func (g *Generator) Lag() []*SensorLag {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	return g.scheduler.Lag(g.listToRegenerate)
+}
+
 func (g *Generator) startMonitoring(ctx context.Context) {
-	if maxProc > 2 {
-		maxProc /= 2
+	workers := maxProc
+	if workers > 2 {
+		workers /= 2
 	}
 
-	for i := 0; i < maxProc; i++ {
-		go g.regenerateData(ctx)
+	g.lock.RLock()
+	regenerateCh := g.regenerateCh
+	sched := g.scheduler
+	g.lock.RUnlock()
+
+	for i := 0; i < workers; i++ {
+		g.wg.Add(1)
+		go g.regenerateData(ctx, regenerateCh)
 	}
 
+	g.wg.Add(2)
+	go func() {
+		defer g.wg.Done()
+		sched.Run(ctx, regenerateCh)
+	}()
+
 	go func() {
-		sleep := time.Duration(0)
+		defer g.wg.Done()
+
+		ticker := time.NewTicker(g.rules.sensorSyncInterval)
+		defer ticker.Stop()
+
 		for {
 			select {
 			case <-ctx.Done():
-				close(g.regenerateCh)
 				return
-			default:
-				for i, node := range g.listToRegenerate {
-					if i != 0 {
-						node.nearestTransparency = g.listToRegenerate[i-1].currentTransparency
-					}
-
-					diff := time.Now().Sub(node.previousUpdate)
-					if diff > node.sensor.DataOutputRate {
-						g.regenerateCh <- node
-						continue
-					}
-
-					newSleep := node.sensor.DataOutputRate - diff
-					if newSleep < sleep {
-						sleep = newSleep
-					}
+			case <-ticker.C:
+				if err := g.syncSensors(); err != nil {
+					log.Printf("cannot sync sensors: %s\n", err)
 				}
-
-				time.Sleep(sleep)
 			}
 		}
 	}()
 }
 
-func (g *Generator) newRandomFishList(sensorId uint64, count int) []*storage.Fish {
-	fishList := make([]*storage.Fish, count)
-	fishIndex := 0
-	usedIndex := make(map[int]struct{})
-
-	for i := 0; i < count; i++ {
-		fishIndex = random.Intn(len(g.rules.fishNames) - 1)
-		_, ok := usedIndex[fishIndex]
-		if ok {
-			i--
-			continue
-		}
-
-		fishList[i] = &storage.Fish{
-			SensorId: sensorId,
-			Name:     g.rules.fishNames[fishIndex],
-			Count:    uint64(random.Intn(defaultMaxFishCount-1) + 1),
-		}
-
-		usedIndex[fishIndex] = struct{}{}
-	}
-
-	return fishList
-}
-
 func shuffleArray(array []string) []string {
 	n := len(array)
 	mixedArray := make([]string, n)
@@ -309,35 +680,37 @@ func shuffleArray(array []string) []string {
 	return mixedArray
 }
 
-func randomTemperature(z float64) float64 {
-	t := maxTemperature - z*tempPerPoint
+func randomPoint(min, max float64) float64 {
+	if min > max {
+		min, max = max, min
+	}
 
-	minT := t - allowedTemperatureDifference
-	maxT := t + allowedTemperatureDifference
+	return min + random.Float64()*(max-min)
+}
 
-	return minT + random.Float64()*(maxT-minT)
+// lockedSource makes the shared random generator safe for the concurrent workers.
+type lockedSource struct {
+	lock sync.Mutex
+	src  rand.Source64
 }
 
-func randomTransparency(nearestT uint8) uint8 {
-	min := uint8(0)
-	max := nearestT + defaultTransparencyInfelicity
+func (s *lockedSource) Int63() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
 
-	if nearestT > 0 && nearestT >= defaultTransparencyInfelicity {
-		min = nearestT - defaultTransparencyInfelicity
-	}
+	return s.src.Int63()
+}
 
-	if max > 100 {
-		max = 100
-	}
+func (s *lockedSource) Uint64() uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
 
-	t := int(min) + random.Intn(int(max-min))
-	return uint8(t)
+	return s.src.Uint64()
 }
 
-func randomPoint(min, max float64) float64 {
-	if min > max {
-		min, max = max, min
-	}
+func (s *lockedSource) Seed(seed int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
 
-	return min + random.Float64()*(max-min)
+	s.src.Seed(seed)
 }
@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveSensorWhileReporting(t *testing.T) {
+	g, err := NewGenerator(nil)
+	require.NoError(t, err)
+
+	sensor := &storage.Sensor{DataOutputRate: time.Minute, Battery: storage.FullBattery, Calibration: storage.DefaultCalibration()}
+	sensor.ID = 1
+	g.AddSensor(sensor)
+	n, ok := g.index.Get(sensor.ID)
+	require.True(t, ok)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			assert.True(t, g.MoveSensor(sensor.ID, Coordinate{X: float64(i + 1)}))
+		}
+	}()
+
+	at := time.Now()
+	var updates []*storage.SensorUpdate
+	for i := 0; i < 100; i++ {
+		if update, _ := g.step(n, at.Add(time.Duration(i)*time.Minute)); update != nil {
+			updates = append(updates, update)
+		}
+	}
+	wg.Wait()
+
+	assert.Equal(t, 0.0, sensor.X, "the added sensor is not changed")
+	assert.Equal(t, 100.0, g.sensorOf(n).X)
+	assert.NotEmpty(t, updates)
+}
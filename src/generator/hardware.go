@@ -0,0 +1,132 @@
+package generator
+
+import (
+	"math"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+const (
+	defaultBatteryDrain        = 0.02
+	defaultLowBatteryNoise     = 4.0
+	defaultMTBF                = 90 * 24 * time.Hour
+	defaultMaintenanceInterval = 30 * 24 * time.Hour
+)
+
+// hardwareRules model the devices behind the sensors, zero values disable the behaviour.
+type hardwareRules struct {
+	// batteryDrain is the battery percent every report takes, so sensors with higher output
+	// rates run flat sooner.
+	batteryDrain float64
+	// lowBatteryNoise is how much the measurement error grows by as a low battery runs flat.
+	lowBatteryNoise float64
+	// mtbf is the mean time between failures, the failures are permanent until maintenance.
+	mtbf time.Duration
+	// maintenanceInterval is how often a sensor is visited, a visit replaces the battery and
+	// repairs a failed sensor.
+	maintenanceInterval time.Duration
+}
+
+func defaultHardwareRules() hardwareRules {
+	return hardwareRules{
+		batteryDrain:        defaultBatteryDrain,
+		lowBatteryNoise:     defaultLowBatteryNoise,
+		mtbf:                defaultMTBF,
+		maintenanceInterval: defaultMaintenanceInterval,
+	}
+}
+
+// noiseFactor is the multiplier of the measurement error at the battery level.
+func (r *hardwareRules) noiseFactor(battery float64) float64 {
+	if battery >= storage.LowBattery {
+		return 1
+	}
+
+	return 1 + r.lowBatteryNoise*(1-math.Max(0, battery)/storage.LowBattery)
+}
+
+// step advances the hardware of the sensor to the report due at the moment, a maintenance
+// visit which is due comes first. It returns the hardware state if it changed and whether
+// the sensor is able to report.
+func (r *hardwareRules) step(sensor *storage.Sensor, at time.Time) (*storage.SensorHardware, bool) {
+	changed := false
+
+	if r.maintenanceInterval > 0 {
+		visited := sensor.CreatedAt
+		if sensor.MaintainedAt != nil {
+			visited = *sensor.MaintainedAt
+		}
+
+		if at.Sub(visited) >= r.maintenanceInterval {
+			sensor.MaintainedAt = &at
+			sensor.Battery = storage.FullBattery
+			sensor.FailedAt = nil
+			changed = true
+		}
+	}
+
+	state := func() *storage.SensorHardware {
+		if !changed {
+			return nil
+		}
+		return sensor.Hardware()
+	}
+
+	if sensor.FailedAt != nil || sensor.Battery <= 0 {
+		return state(), false
+	}
+
+	if chance(sensor.DataOutputRate, r.mtbf) {
+		sensor.FailedAt = &at
+		changed = true
+		return state(), false
+	}
+
+	if r.batteryDrain > 0 {
+		sensor.Battery = math.Max(0, sensor.Battery-r.batteryDrain)
+		changed = true
+	}
+
+	return state(), true
+}
+
+// repairedAt is when the sensor is visited next, a failure or a flat battery lasts until then.
+// Without maintenance it is the moment itself.
+func (r *hardwareRules) repairedAt(sensor *storage.Sensor, at time.Time) time.Time {
+	if r.maintenanceInterval <= 0 {
+		return at
+	}
+
+	visited := sensor.CreatedAt
+	if sensor.MaintainedAt != nil {
+		visited = *sensor.MaintainedAt
+	}
+
+	return visited.Add(r.maintenanceInterval)
+}
+
+// hardwareStep advances the hardware of the monitored sensor, see hardwareRules.step. The
+// changed hardware is set on a copy of the sensor which replaces the one of the node. It
+// labels the failure or the flat battery the sensor got at the moment.
+func (g *Generator) hardwareStep(n *regenerateNode, at time.Time) (*storage.SensorHardware, []*storage.AnomalyLabel, bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	sensor := *n.sensor
+	hardware, reports := g.rules.hardware.step(&sensor, at)
+	if hardware == nil {
+		return nil, nil, reports
+	}
+
+	var labels []*storage.AnomalyLabel
+	switch {
+	case sensor.FailedAt != nil && n.sensor.FailedAt == nil:
+		labels = append(labels, newLabel(n, storage.AnomalyHardwareFailure, "", at, g.rules.hardware.repairedAt(&sensor, at), 0))
+	case sensor.Battery <= 0 && n.sensor.Battery > 0:
+		labels = append(labels, newLabel(n, storage.AnomalyBatteryDepleted, "", at, g.rules.hardware.repairedAt(&sensor, at), 0))
+	}
+	n.sensor = &sensor
+
+	return hardware, labels, reports
+}
@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHardwareStep(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newSensor := func(battery float64) *storage.Sensor {
+		sensor := &storage.Sensor{DataOutputRate: time.Minute, Battery: battery}
+		sensor.CreatedAt = created
+		return sensor
+	}
+
+	r := hardwareRules{batteryDrain: 1, lowBatteryNoise: 4, maintenanceInterval: 24 * time.Hour}
+
+	sensor := newSensor(50)
+	hardware, reports := r.step(sensor, created.Add(time.Hour))
+	assert.True(t, reports)
+	require.NotNil(t, hardware)
+	assert.Equal(t, 49.0, hardware.Battery, "every report drains the battery")
+
+	sensor = newSensor(0.5)
+	_, reports = r.step(sensor, created.Add(time.Hour))
+	assert.True(t, reports)
+	assert.Equal(t, 0.0, sensor.Battery)
+	assert.Equal(t, storage.HardwareDepleted, sensor.HardwareStatus())
+
+	hardware, reports = r.step(sensor, created.Add(2*time.Hour))
+	assert.False(t, reports, "a depleted sensor does not report")
+	assert.Nil(t, hardware, "nothing changed")
+
+	hardware, reports = r.step(sensor, created.Add(25*time.Hour))
+	assert.True(t, reports, "maintenance replaces the battery")
+	require.NotNil(t, hardware)
+	require.NotNil(t, hardware.MaintainedAt)
+	assert.Equal(t, created.Add(25*time.Hour), *hardware.MaintainedAt)
+	assert.Equal(t, storage.FullBattery-1, hardware.Battery)
+
+	failed := created.Add(time.Hour)
+	sensor = newSensor(80)
+	sensor.FailedAt = &failed
+	_, reports = r.step(sensor, created.Add(2*time.Hour))
+	assert.False(t, reports, "a failed sensor does not report")
+	_, reports = r.step(sensor, created.Add(25*time.Hour))
+	assert.True(t, reports, "maintenance repairs the sensor")
+	assert.Nil(t, sensor.FailedAt)
+
+	r = hardwareRules{mtbf: time.Nanosecond}
+	sensor = newSensor(80)
+	hardware, reports = r.step(sensor, created.Add(time.Hour))
+	assert.False(t, reports, "a failing sensor does not report")
+	require.NotNil(t, hardware)
+	assert.NotNil(t, hardware.FailedAt)
+	assert.Equal(t, storage.HardwareFailed, sensor.HardwareStatus())
+}
+
+func TestHardwareStepLabels(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newNode := func(battery float64) *regenerateNode {
+		n := &regenerateNode{sensor: &storage.Sensor{DataOutputRate: time.Minute, Battery: battery}}
+		n.sensor.ID = 1
+		n.sensor.CreatedAt = created
+		return n
+	}
+
+	g := &Generator{rules: defaultGeneratorRules()}
+	g.rules.hardware = hardwareRules{batteryDrain: 1, maintenanceInterval: 24 * time.Hour}
+
+	n := newNode(0.5)
+	_, labels, reports := g.hardwareStep(n, created.Add(time.Hour))
+	assert.True(t, reports)
+	require.Len(t, labels, 1)
+	assert.Equal(t, storage.AnomalyBatteryDepleted, labels[0].Kind)
+	assert.Equal(t, created.Add(time.Hour), labels[0].CreatedAt)
+	assert.Equal(t, created.Add(24*time.Hour), labels[0].EndedAt, "until the maintenance visit")
+
+	_, labels, reports = g.hardwareStep(n, created.Add(2*time.Hour))
+	assert.False(t, reports)
+	assert.Empty(t, labels, "the flat battery is labelled once")
+
+	g.rules.hardware = hardwareRules{mtbf: time.Nanosecond}
+	n = newNode(80)
+	_, labels, reports = g.hardwareStep(n, created.Add(time.Hour))
+	assert.False(t, reports)
+	require.Len(t, labels, 1)
+	assert.Equal(t, storage.AnomalyHardwareFailure, labels[0].Kind)
+	assert.Equal(t, labels[0].CreatedAt, labels[0].EndedAt, "no maintenance repairs the sensor")
+
+	_, labels, _ = g.hardwareStep(n, created.Add(2*time.Hour))
+	assert.Empty(t, labels, "the failure is labelled once")
+}
+
+func TestNoiseFactor(t *testing.T) {
+	r := hardwareRules{lowBatteryNoise: 4}
+
+	assert.Equal(t, 1.0, r.noiseFactor(storage.FullBattery))
+	assert.Equal(t, 1.0, r.noiseFactor(storage.LowBattery))
+	assert.InDelta(t, 3.0, r.noiseFactor(storage.LowBattery/2), 1e-9)
+	assert.InDelta(t, 5.0, r.noiseFactor(0), 1e-9)
+}
@@ -1,5 +1,11 @@
 package generator
 
+import (
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
 type DataOption func(data *generatorRules)
 
 func WithGroupsCount(t uint16) DataOption {
@@ -31,3 +37,264 @@ func WithDataOutputRate(min, max uint) DataOption {
 		gd.maxDataOutputRate = max
 	}
 }
+
+func WithNeighboursCount(count int) DataOption {
+	return func(gd *generatorRules) {
+		if count > 0 {
+			gd.neighboursCount = count
+		}
+	}
+}
+
+func WithSensorSyncInterval(interval time.Duration) DataOption {
+	return func(gd *generatorRules) {
+		if interval > 0 {
+			gd.sensorSyncInterval = interval
+		}
+	}
+}
+
+func WithFieldSeed(seed int64) DataOption {
+	return func(gd *generatorRules) {
+		gd.field.seed = uint64(seed)
+	}
+}
+
+func WithThermocline(depth, thickness float64) DataOption {
+	return func(gd *generatorRules) {
+		if depth >= 0 && thickness > 0 {
+			gd.field.thermoclineDepth = depth
+			gd.field.thermoclineThickness = thickness
+		}
+	}
+}
+
+func WithSurfaceTemperature(surface, deep float64) DataOption {
+	return func(gd *generatorRules) {
+		gd.field.surfaceTemperature = surface
+		gd.field.deepTemperature = deep
+	}
+}
+
+func WithTemperatureGradient(x, y float64) DataOption {
+	return func(gd *generatorRules) {
+		gd.field.gradientX = x
+		gd.field.gradientY = y
+	}
+}
+
+func WithFieldEvolution(period time.Duration) DataOption {
+	return func(gd *generatorRules) {
+		if period > 0 {
+			gd.field.evolution = period
+		}
+	}
+}
+
+func WithLocation(latitude, longitude float64) DataOption {
+	return func(gd *generatorRules) {
+		if latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180 {
+			gd.field.cycles.latitude = latitude
+			gd.field.cycles.longitude = longitude
+		}
+	}
+}
+
+// WithSimulatedDate makes the current moment correspond to the date, so any season can be simulated.
+func WithSimulatedDate(date time.Time) DataOption {
+	return func(gd *generatorRules) {
+		gd.field.cycles.seasonShift = time.Until(date)
+	}
+}
+
+func WithDiurnalCycle(amplitude, depth float64) DataOption {
+	return func(gd *generatorRules) {
+		if amplitude >= 0 && depth > 0 {
+			gd.field.cycles.diurnalAmplitude = amplitude
+			gd.field.cycles.diurnalDepth = depth
+		}
+	}
+}
+
+func WithSeasonalCycle(amplitude, depth float64) DataOption {
+	return func(gd *generatorRules) {
+		if amplitude >= 0 && depth > 0 {
+			gd.field.cycles.seasonalAmplitude = amplitude
+			gd.field.cycles.seasonalDepth = depth
+		}
+	}
+}
+
+func WithTideCycle(amplitude, depth float64, period time.Duration) DataOption {
+	return func(gd *generatorRules) {
+		if amplitude >= 0 && depth > 0 && period > 0 {
+			gd.field.cycles.tideAmplitude = amplitude
+			gd.field.cycles.tideDepth = depth
+			gd.field.cycles.tidePeriod = period
+		}
+	}
+}
+
+// WithReplay makes the generator replay the recorded CSV or NDJSON dataset instead of generating data.
+// Intervals between records are divided by the speed, the dataset starts over when loop is set.
+func WithReplay(path string, speed float64, loop bool) DataOption {
+	return func(gd *generatorRules) {
+		if path == "" {
+			return
+		}
+
+		if speed <= 0 {
+			speed = 1
+		}
+
+		gd.replay = &replayRules{path: path, speed: speed, loop: loop}
+	}
+}
+
+// WithWriteBatch sets how many sensor updates are buffered and how often they are flushed to the storage.
+func WithWriteBatch(size int, interval time.Duration) DataOption {
+	return func(gd *generatorRules) {
+		if size > 0 {
+			gd.flushSize = size
+		}
+
+		if interval > 0 {
+			gd.flushInterval = interval
+		}
+	}
+}
+
+// WithJitter shifts every update randomly by up to the fraction of the sensor data output rate,
+// so sensors with equal rates do not report at once.
+func WithJitter(fraction float64) DataOption {
+	return func(gd *generatorRules) {
+		if fraction >= 0 && fraction < 1 {
+			gd.jitter = fraction
+		}
+	}
+}
+
+// WithCatchUp sets what happens to the updates missed while the generator was behind.
+func WithCatchUp(policy CatchUpPolicy) DataOption {
+	return func(gd *generatorRules) {
+		gd.catchUp = policy
+	}
+}
+
+// WithFishListLength sets how many species a sensor observes when it starts.
+func WithFishListLength(length int) DataOption {
+	return func(gd *generatorRules) {
+		if length > 0 {
+			gd.fishListLength = length
+		}
+	}
+}
+
+// WithBounds limits the coordinates new sensors are placed at, the bounds are ignored unless
+// min is less than max on every axis.
+func WithBounds(min, max Coordinate) DataOption {
+	return func(gd *generatorRules) {
+		if min.X < max.X && min.Y < max.Y && min.Z < max.Z {
+			gd.minBounds = min
+			gd.maxBounds = max
+		}
+	}
+}
+
+// WithBattery sets the battery percent every report takes and how many times the measurement
+// error grows by as a low battery runs flat, a zero drain keeps the batteries full.
+func WithBattery(drain, lowBatteryNoise float64) DataOption {
+	return func(gd *generatorRules) {
+		if drain >= 0 && drain <= storage.FullBattery {
+			gd.hardware.batteryDrain = drain
+		}
+
+		if lowBatteryNoise >= 0 {
+			gd.hardware.lowBatteryNoise = lowBatteryNoise
+		}
+	}
+}
+
+// WithFailures sets the mean time between permanent failures of a sensor, zero disables failures.
+func WithFailures(mtbf time.Duration) DataOption {
+	return func(gd *generatorRules) {
+		if mtbf >= 0 {
+			gd.hardware.mtbf = mtbf
+		}
+	}
+}
+
+// WithMaintenance sets how often the sensors are visited to replace the battery and repair
+// failures, zero disables the visits.
+func WithMaintenance(interval time.Duration) DataOption {
+	return func(gd *generatorRules) {
+		if interval >= 0 {
+			gd.hardware.maintenanceInterval = interval
+		}
+	}
+}
+
+// WithCalibrationErrors sets the max offset in Celsius degrees and the max gain error new sensors
+// are calibrated with, zeros make them ideal.
+func WithCalibrationErrors(maxOffset, maxGainError float64) DataOption {
+	return func(gd *generatorRules) {
+		if maxOffset >= 0 {
+			gd.calibration.maxOffset = maxOffset
+		}
+
+		if maxGainError >= 0 && maxGainError < 1 {
+			gd.calibration.maxGainError = maxGainError
+		}
+	}
+}
+
+// WithNoiseStd sets the range of the measurement noise std in Celsius degrees of new sensors.
+func WithNoiseStd(min, max float64) DataOption {
+	return func(gd *generatorRules) {
+		if min >= 0 && min <= max {
+			gd.calibration.minNoiseStd = min
+			gd.calibration.maxNoiseStd = max
+		}
+	}
+}
+
+// WithResolution sets the step the readings of new sensors are rounded to in their units, zero
+// does not round.
+func WithResolution(resolution float64) DataOption {
+	return func(gd *generatorRules) {
+		if resolution >= 0 {
+			gd.calibration.resolution = resolution
+		}
+	}
+}
+
+// WithTemperatureUnits sets the units new sensors report in, every sensor gets one of them at random.
+func WithTemperatureUnits(units ...storage.TemperatureUnit) DataOption {
+	return func(gd *generatorRules) {
+		if len(units) > 0 {
+			gd.calibration.units = units
+		}
+	}
+}
+
+// WithAnomalies sets the mean times between the spikes, drifts, dropouts and population shocks of
+// a sensor and between the scenario events, zero disables the anomalies of the kind.
+func WithAnomalies(spike, drift, dropout, shock, event time.Duration) DataOption {
+	return func(gd *generatorRules) {
+		if spike >= 0 {
+			gd.anomalies.spikeInterval = spike
+		}
+		if drift >= 0 {
+			gd.anomalies.driftInterval = drift
+		}
+		if dropout >= 0 {
+			gd.anomalies.dropoutInterval = dropout
+		}
+		if shock >= 0 {
+			gd.anomalies.shockInterval = shock
+		}
+		if event >= 0 {
+			gd.anomalies.eventInterval = event
+		}
+	}
+}
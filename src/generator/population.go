@@ -0,0 +1,264 @@
+package generator
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+const (
+	defaultSpeciesCapacity = 60.0
+	defaultGrowthRate      = 0.15
+	defaultColonizeChance  = 0.02
+	defaultMigrationChance = 0.1
+
+	minSuitability = 0.05
+)
+
+// species describes the habitat a fish prefers, it is derived from the name so every run
+// and every instance agree on it.
+type species struct {
+	name string
+
+	minTemperature, maxTemperature float64
+	minDepth, maxDepth             float64
+	schooling                      bool
+}
+
+func newSpecies(name string) *species {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	seed := h.Sum64()
+
+	next := func() float64 {
+		seed = splitMix(seed + 0x9e3779b97f4a7c15)
+		return float64(seed>>11) / float64(1<<53)
+	}
+
+	temperature := 2 + next()*24
+	temperatureRange := 3 + next()*6
+	d := next() * depth(defaultMaxZ)
+	depthRange := 150 + next()*600
+
+	return &species{
+		name:           name,
+		minTemperature: temperature - temperatureRange,
+		maxTemperature: temperature + temperatureRange,
+		minDepth:       math.Max(0, d-depthRange),
+		maxDepth:       d + depthRange,
+		schooling:      next() < 0.4,
+	}
+}
+
+// suitability is 1 inside the preferred ranges and fades out of them.
+func (s *species) suitability(temperature, d float64) float64 {
+	return rangeFit(temperature, s.minTemperature, s.maxTemperature, 3) *
+		rangeFit(d, s.minDepth, s.maxDepth, 150)
+}
+
+func rangeFit(v, min, max, tolerance float64) float64 {
+	outside := 0.0
+	if v < min {
+		outside = min - v
+	} else if v > max {
+		outside = v - max
+	}
+
+	return math.Exp(-outside * outside / (2 * tolerance * tolerance))
+}
+
+// habitat is the state of a sensor area the population step depends on.
+type habitat struct {
+	sensorId    uint
+	temperature float64
+	depth       float64
+}
+
+// populationModel keeps fish counts per sensor. Counts follow logistic growth towards the
+// capacity of the habitat, species colonise suitable areas and schools move between
+// neighbouring sensors, so the species list of a sensor changes gradually.
+type populationModel struct {
+	lock sync.Mutex
+
+	species     []*species
+	byName      map[string]int
+	populations map[uint]map[int]float64
+	seeded      map[uint]struct{}
+
+	initialSpecies  int
+	capacity        float64
+	growthRate      float64
+	colonizeChance  float64
+	migrationChance float64
+}
+
+func newPopulationModel(names []string, initialSpecies int) *populationModel {
+	pm := &populationModel{
+		species:         make([]*species, 0, len(names)),
+		byName:          make(map[string]int, len(names)),
+		populations:     make(map[uint]map[int]float64),
+		seeded:          make(map[uint]struct{}),
+		initialSpecies:  initialSpecies,
+		capacity:        defaultSpeciesCapacity,
+		growthRate:      defaultGrowthRate,
+		colonizeChance:  defaultColonizeChance,
+		migrationChance: defaultMigrationChance,
+	}
+
+	for _, name := range names {
+		if _, ok := pm.byName[name]; ok || name == "" {
+			continue
+		}
+
+		pm.byName[name] = len(pm.species)
+		pm.species = append(pm.species, newSpecies(name))
+	}
+
+	return pm
+}
+
+// Restore loads the last known fish counts, so the population continues after a restart.
+func (pm *populationModel) Restore(fishes []*storage.Fish) {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	for _, fish := range fishes {
+		i, ok := pm.byName[fish.Name]
+		if !ok {
+			continue
+		}
+
+		pm.sensorPopulation(uint(fish.SensorId))[i] = float64(fish.Count)
+		pm.seeded[uint(fish.SensorId)] = struct{}{}
+	}
+}
+
+func (pm *populationModel) Remove(sensorId uint) {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	delete(pm.populations, sensorId)
+	delete(pm.seeded, sensorId)
+}
+
+// Shock removes the fraction of every species around the sensor.
+func (pm *populationModel) Shock(sensorId uint, loss float64) {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	population := pm.sensorPopulation(sensorId)
+	for i, count := range population {
+		population[i] = count * (1 - loss)
+	}
+}
+
+// Step advances the population of the sensor area and returns the fish currently observed there.
+func (pm *populationModel) Step(h habitat, neighbours []habitat) []*storage.Fish {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	population := pm.sensorPopulation(h.sensorId)
+	if _, ok := pm.seeded[h.sensorId]; !ok {
+		pm.seed(h, population)
+		pm.seeded[h.sensorId] = struct{}{}
+	}
+
+	for i, count := range population {
+		s := pm.species[i]
+		capacity := pm.capacity * s.suitability(h.temperature, h.depth)
+
+		if capacity < 1 {
+			count *= 0.5 + 0.3*random.Float64()
+		} else {
+			count += pm.growthRate * count * (1 - count/capacity) * (0.5 + random.Float64())
+		}
+
+		if len(neighbours) > 0 && random.Float64() < pm.migrationChance {
+			count -= pm.migrate(s, i, count, neighbours)
+		}
+
+		if count < 0.5 {
+			delete(population, i)
+			continue
+		}
+
+		population[i] = count
+	}
+
+	if len(pm.species) > 0 && random.Float64() < pm.colonizeChance {
+		i := random.Intn(len(pm.species))
+		if _, ok := population[i]; !ok && pm.species[i].suitability(h.temperature, h.depth) > minSuitability {
+			population[i] = float64(1 + random.Intn(3))
+		}
+	}
+
+	fishes := make([]*storage.Fish, 0, len(population))
+	for i, count := range population {
+		fishes = append(fishes, &storage.Fish{
+			SensorId: uint64(h.sensorId),
+			Name:     pm.species[i].name,
+			Count:    uint64(math.Round(count)),
+		})
+	}
+
+	sort.Slice(fishes, func(i, j int) bool {
+		return fishes[i].Name < fishes[j].Name
+	})
+
+	return fishes
+}
+
+// migrate moves a part of the population, or the whole school, to the neighbour with the
+// best habitat for the species and returns the moved count.
+func (pm *populationModel) migrate(s *species, i int, count float64, neighbours []habitat) float64 {
+	target := neighbours[0]
+	best := -1.0
+	for _, n := range neighbours {
+		suitability := s.suitability(n.temperature, n.depth) * (0.8 + 0.4*random.Float64())
+		if suitability > best {
+			best, target = suitability, n
+		}
+	}
+
+	if best < minSuitability {
+		return 0
+	}
+
+	moved := count * (0.1 + 0.3*random.Float64())
+	if s.schooling {
+		moved = count
+	}
+
+	pm.sensorPopulation(target.sensorId)[i] += moved
+	return moved
+}
+
+// seed settles the initial species of a new sensor area, chosen by their habitat suitability.
+func (pm *populationModel) seed(h habitat, population map[int]float64) {
+	if len(pm.species) == 0 {
+		return
+	}
+
+	for attempt := 0; attempt < pm.initialSpecies*10 && len(population) < pm.initialSpecies; attempt++ {
+		i := random.Intn(len(pm.species))
+		suitability := pm.species[i].suitability(h.temperature, h.depth)
+		if _, ok := population[i]; ok || suitability < minSuitability || random.Float64() > suitability {
+			continue
+		}
+
+		population[i] = pm.capacity * suitability * (0.3 + 0.5*random.Float64())
+	}
+}
+
+func (pm *populationModel) sensorPopulation(sensorId uint) map[int]float64 {
+	population, ok := pm.populations[sensorId]
+	if !ok {
+		population = make(map[int]float64)
+		pm.populations[sensorId] = population
+	}
+
+	return population
+}
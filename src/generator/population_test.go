@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpeciesDerivedFromName(t *testing.T) {
+	assert.Equal(t, newSpecies("Tuna"), newSpecies("Tuna"))
+	assert.NotEqual(t, newSpecies("Tuna"), newSpecies("Cod"))
+}
+
+// preferredHabitat is the habitat in the middle of the preferred ranges of the species.
+func preferredHabitat(s *species, sensorId uint) habitat {
+	return habitat{
+		sensorId:    sensorId,
+		temperature: (s.minTemperature + s.maxTemperature) / 2,
+		depth:       (s.minDepth + s.maxDepth) / 2,
+	}
+}
+
+func TestPopulationStep(t *testing.T) {
+	pm := newPopulationModel([]string{"Tuna"}, 1)
+	pm.colonizeChance, pm.migrationChance = 0, 0
+	h := preferredHabitat(pm.species[0], 1)
+	require.Equal(t, 1.0, pm.species[0].suitability(h.temperature, h.depth))
+
+	pm.populations[1] = map[int]float64{0: 5}
+	pm.seeded[1] = struct{}{}
+
+	previous := 5.0
+	for i := 0; i < 200; i++ {
+		fishes := pm.Step(h, nil)
+		require.Len(t, fishes, 1)
+
+		count := pm.populations[1][0]
+		assert.GreaterOrEqual(t, count, previous, "the population grows below the capacity")
+		assert.LessOrEqual(t, count-previous, 1.5*pm.growthRate*previous, "the counts change gradually")
+		assert.LessOrEqual(t, count, pm.capacity, "the capacity of the habitat is never exceeded")
+		previous = count
+	}
+	assert.InDelta(t, pm.capacity, previous, 1, "the population settles at the capacity")
+}
+
+func TestPopulationDeclinesInUnsuitableHabitat(t *testing.T) {
+	pm := newPopulationModel([]string{"Tuna"}, 1)
+	pm.colonizeChance, pm.migrationChance = 0, 0
+	s := pm.species[0]
+	h := habitat{sensorId: 1, temperature: s.maxTemperature + 30, depth: s.maxDepth + 2000}
+
+	pm.populations[1] = map[int]float64{0: 50}
+	pm.seeded[1] = struct{}{}
+	pm.Shock(1, 0.5)
+	assert.Equal(t, 25.0, pm.populations[1][0])
+
+	for i := 0; i < 50; i++ {
+		for _, fish := range pm.Step(h, nil) {
+			assert.LessOrEqual(t, fish.Count, uint64(25))
+		}
+		for _, count := range pm.populations[1] {
+			assert.Greater(t, count, 0.0, "the counts are never negative")
+		}
+	}
+	assert.Empty(t, pm.populations[1], "the species leaves the area")
+}
+
+func TestPopulationMigration(t *testing.T) {
+	pm := newPopulationModel([]string{"Tuna"}, 1)
+	pm.colonizeChance, pm.migrationChance = 0, 1
+	s := pm.species[0]
+	s.schooling = true
+
+	h := preferredHabitat(s, 1)
+	pm.populations[1] = map[int]float64{0: 20}
+	pm.seeded[1] = struct{}{}
+	pm.seeded[2] = struct{}{}
+
+	fishes := pm.Step(h, []habitat{preferredHabitat(s, 2)})
+	assert.Empty(t, fishes, "the whole school moved")
+	assert.Greater(t, pm.populations[2][0], 20.0, "nothing is lost on the way")
+}
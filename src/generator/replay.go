@@ -0,0 +1,509 @@
+package generator
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+const (
+	replayFishMetric  = "fish"
+	replayFrameWindow = time.Second
+)
+
+var (
+	ErrBadReplayRecord = errors.New("bad replay record")
+	ErrBadReplayFile   = errors.New("bad replay file")
+)
+
+type replayRules struct {
+	path  string
+	speed float64
+	loop  bool
+}
+
+// replayRecord is a single recorded value. Fish observations use the "fish" metric, the
+// species name and the count as the value.
+type replayRecord struct {
+	Sensor    string
+	Timestamp time.Time
+	Metric    string
+	Value     float64
+	Species   string
+}
+
+// replayFrame joins the records of one sensor reported together.
+type replayFrame struct {
+	sensor string
+	at     time.Time
+
+	temperature  *float64
+	transparency *float64
+	fishes       map[string]uint64
+}
+
+type replayReader interface {
+	Read() (*replayRecord, error)
+}
+
+// check opens the dataset and reads its first record, so a missing or malformed file is
+// reported before the replay starts.
+func (r *replayRules) check() error {
+	file, err := os.Open(r.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader, err := newReplayReader(file, r.path)
+	if err != nil {
+		return err
+	}
+
+	_, err = reader.Read()
+	if err == io.EOF {
+		return fmt.Errorf("%w: %s has no records", ErrBadReplayFile, r.path)
+	}
+
+	return err
+}
+
+// replay drives the sensors from the recorded dataset instead of the environment model,
+// keeping the recorded intervals scaled by the speed factor.
+func (g *Generator) replay(ctx context.Context) {
+	for {
+		err := g.replayFile(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("cannot replay %s: %s\n", g.rules.replay.path, err)
+			return
+		}
+
+		if !g.rules.replay.loop || ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (g *Generator) replayFile(ctx context.Context) error {
+	file, err := os.Open(g.rules.replay.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader, err := newReplayReader(file, g.rules.replay.path)
+	if err != nil {
+		return err
+	}
+
+	var (
+		sensors  *replaySensorMap
+		previous time.Time
+	)
+
+	return readReplayFrames(reader, func(frame *replayFrame) error {
+		if !previous.IsZero() && frame.at.After(previous) {
+			wait := time.Duration(float64(frame.at.Sub(previous)) / g.rules.replay.speed)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		previous = frame.at
+
+		if sensors, err = g.replaySensors(sensors); err != nil {
+			return err
+		}
+
+		n, ok := sensors.resolve(frame.sensor)
+		if !ok {
+			return nil
+		}
+
+		g.replayFrame(n, frame)
+		return nil
+	})
+}
+
+// replaySensors returns the map of the recorded sensors onto the current sensors of the
+// generator. The map is built again when the sensors changed, so sensors added or taken over
+// during the replay are replayed into as well, the recorded sensors keep their sensors.
+func (g *Generator) replaySensors(m *replaySensorMap) (*replaySensorMap, error) {
+	g.lock.RLock()
+	version := g.sensorsVersion
+	g.lock.RUnlock()
+
+	if m != nil && m.version == version {
+		return m, nil
+	}
+
+	groups, err := g.storage.GetAllGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	// The version is taken before the sensors, so a change in between builds the map again.
+	nodes := g.sensors()
+	g.lock.RLock()
+	next := newReplaySensorMap(nodes, groups)
+	g.lock.RUnlock()
+	next.version = version
+
+	next.keep(m)
+	return next, nil
+}
+
+// replayFrame writes the frame through the same writer as generated data. Only the recorded
+// metrics are written, recorded temperatures have no true value.
+func (g *Generator) replayFrame(n *regenerateNode, frame *replayFrame) {
+	sensor := g.sensorOf(n)
+	update := &storage.SensorUpdate{Sensor: sensor}
+
+	if frame.temperature != nil {
+		update.Temperature = &storage.Temperature{SensorId: uint64(sensor.ID), Temperature: *frame.temperature}
+	}
+
+	if frame.transparency != nil {
+		tr := uint8(math.Max(0, math.Min(100, math.Round(*frame.transparency))))
+		update.Transparency = &storage.Transparency{SensorId: uint64(sensor.ID), Transparency: tr}
+	}
+
+	for name, count := range frame.fishes {
+		update.Fishes = append(update.Fishes, &storage.Fish{SensorId: uint64(sensor.ID), Name: name, Count: count})
+	}
+
+	g.save(update)
+
+	g.lock.Lock()
+	if update.Transparency != nil {
+		n.currentTransparency = update.Transparency.Transparency
+	}
+	n.previousUpdate = time.Now()
+	g.lock.Unlock()
+}
+
+func (g *Generator) sensors() []*regenerateNode {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	nodes := make([]*regenerateNode, len(g.listToRegenerate))
+	copy(nodes, g.listToRegenerate)
+
+	return nodes
+}
+
+// replaySensorMap maps recorded sensors onto the stored ones. A recorded sensor matching a
+// code name or an id of the stored sensor is replayed into it, others take free sensors in order.
+type replaySensorMap struct {
+	// version is the version of the generator sensors the map was built for.
+	version  uint64
+	byName   map[string]*regenerateNode
+	mapped   map[string]*regenerateNode
+	used     map[*regenerateNode]struct{}
+	sensors  []*regenerateNode
+	nextFree int
+}
+
+func newReplaySensorMap(nodes []*regenerateNode, groups []*storage.Group) *replaySensorMap {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].sensor.ID < nodes[j].sensor.ID
+	})
+
+	groupNames := make(map[uint64]string, len(groups))
+	for _, group := range groups {
+		groupNames[uint64(group.ID)] = group.Name
+	}
+
+	byName := make(map[string]*regenerateNode, len(nodes)*2)
+	for _, n := range nodes {
+		byName[strconv.FormatUint(uint64(n.sensor.ID), 10)] = n
+		if name, ok := groupNames[n.sensor.GroupId]; ok {
+			byName[name+strconv.FormatUint(n.sensor.IndexInGroup, 10)] = n
+		}
+	}
+
+	return &replaySensorMap{
+		byName:  byName,
+		mapped:  make(map[string]*regenerateNode),
+		used:    make(map[*regenerateNode]struct{}),
+		sensors: nodes,
+	}
+}
+
+// keep maps the recorded sensors of the previous map onto the same sensors if they are still
+// in this map.
+func (m *replaySensorMap) keep(previous *replaySensorMap) {
+	if previous == nil {
+		return
+	}
+
+	current := make(map[*regenerateNode]struct{}, len(m.sensors))
+	for _, n := range m.sensors {
+		current[n] = struct{}{}
+	}
+
+	for recorded, n := range previous.mapped {
+		if _, ok := current[n]; ok {
+			m.mapped[recorded] = n
+			m.used[n] = struct{}{}
+		}
+	}
+}
+
+func (m *replaySensorMap) resolve(recorded string) (*regenerateNode, bool) {
+	if n, ok := m.mapped[recorded]; ok {
+		return n, true
+	}
+
+	if len(m.sensors) == 0 {
+		return nil, false
+	}
+
+	n, ok := m.byName[recorded]
+	if !ok {
+		n = m.nextSensor()
+	}
+
+	m.mapped[recorded] = n
+	m.used[n] = struct{}{}
+
+	return n, true
+}
+
+// nextSensor returns the next sensor nothing is replayed into yet, or the next one in
+// order when there are more recorded sensors than stored.
+func (m *replaySensorMap) nextSensor() *regenerateNode {
+	for i := 0; i < len(m.sensors); i++ {
+		n := m.sensors[(m.nextFree+i)%len(m.sensors)]
+		if _, ok := m.used[n]; !ok {
+			m.nextFree = (m.nextFree + i + 1) % len(m.sensors)
+			return n
+		}
+	}
+
+	n := m.sensors[m.nextFree]
+	m.nextFree = (m.nextFree + 1) % len(m.sensors)
+
+	return n
+}
+
+// readReplayFrames joins records of the same sensor within replayFrameWindow into frames and
+// passes them to fn ordered by time. Records are expected to be ordered by time.
+func readReplayFrames(reader replayReader, fn func(*replayFrame) error) error {
+	pending := make(map[string]*replayFrame)
+
+	flush := func(before time.Time, all bool) error {
+		ready := make([]*replayFrame, 0)
+		for sensor, frame := range pending {
+			if all || before.Sub(frame.at) > replayFrameWindow {
+				ready = append(ready, frame)
+				delete(pending, sensor)
+			}
+		}
+
+		sort.Slice(ready, func(i, j int) bool {
+			return ready[i].at.Before(ready[j].at)
+		})
+
+		for _, frame := range ready {
+			if err := fn(frame); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return flush(time.Time{}, true)
+		} else if err != nil {
+			return err
+		}
+
+		if err = flush(record.Timestamp, false); err != nil {
+			return err
+		}
+
+		frame, ok := pending[record.Sensor]
+		if !ok {
+			frame = &replayFrame{sensor: record.Sensor, at: record.Timestamp, fishes: make(map[string]uint64)}
+			pending[record.Sensor] = frame
+		}
+
+		value := record.Value
+		switch record.Metric {
+		case storage.TemperatureMetric:
+			frame.temperature = &value
+		case storage.TransparencyMetric:
+			frame.transparency = &value
+		case replayFishMetric:
+			frame.fishes[record.Species] = uint64(math.Max(0, math.Round(value)))
+		}
+	}
+}
+
+func newReplayReader(r io.Reader, path string) (replayReader, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return newCsvReplayReader(r)
+	case ".ndjson", ".jsonl", ".json":
+		return &jsonReplayReader{dec: json.NewDecoder(r)}, nil
+	}
+
+	return nil, fmt.Errorf("unknown replay file format: %s", path)
+}
+
+type jsonReplayReader struct {
+	dec *json.Decoder
+}
+
+func (r *jsonReplayReader) Read() (*replayRecord, error) {
+	var raw map[string]any
+	if err := r.dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch value := v.(type) {
+		case string:
+			fields[k] = value
+		case float64:
+			fields[k] = strconv.FormatFloat(value, 'f', -1, 64)
+		}
+	}
+
+	return newReplayRecord(fields)
+}
+
+type csvReplayReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+func newCsvReplayReader(r io.Reader) (*csvReplayReader, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: the header is missing", ErrBadReplayFile)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err = checkReplayHeader(header); err != nil {
+		return nil, err
+	}
+
+	return &csvReplayReader{r: cr, header: append([]string(nil), header...)}, nil
+}
+
+// checkReplayHeader makes sure the columns every record needs are present.
+func checkReplayHeader(header []string) error {
+	columns := make(map[string]string, len(header))
+	for _, name := range header {
+		columns[name] = "x"
+	}
+
+	required := [][]string{{"sensor", "sensor_id", "code_name", "group"}, {"timestamp", "time"}, {"value", "count"}}
+	for _, names := range required {
+		if firstField(columns, names...) == "" {
+			return fmt.Errorf("%w: none of the columns %s is present", ErrBadReplayFile, strings.Join(names, ", "))
+		}
+	}
+
+	return nil
+}
+
+func (r *csvReplayReader) Read() (*replayRecord, error) {
+	row, err := r.r.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string, len(r.header))
+	for i, name := range r.header {
+		if i < len(row) {
+			fields[name] = row[i]
+		}
+	}
+
+	return newReplayRecord(fields)
+}
+
+// newReplayRecord builds the record from named fields, files produced by the export are accepted as well.
+func newReplayRecord(fields map[string]string) (*replayRecord, error) {
+	record := &replayRecord{
+		Sensor:  firstField(fields, "sensor", "sensor_id", "code_name"),
+		Metric:  firstField(fields, "metric"),
+		Species: firstField(fields, "species"),
+	}
+
+	if record.Sensor == "" && fields["group"] != "" {
+		record.Sensor = fields["group"] + fields["index_in_group"]
+	}
+
+	if record.Species != "" && record.Metric == "" {
+		record.Metric = replayFishMetric
+	}
+
+	if record.Sensor == "" {
+		return nil, fmt.Errorf("%w: sensor is missing", ErrBadReplayRecord)
+	}
+
+	ts, err := parseReplayTime(firstField(fields, "timestamp", "time"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadReplayRecord, err)
+	}
+	record.Timestamp = ts
+
+	record.Value, err = strconv.ParseFloat(firstField(fields, "value", "count"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadReplayRecord, err)
+	}
+
+	return record, nil
+}
+
+// parseReplayTime accepts RFC3339 or UNIX timestamps in seconds.
+func parseReplayTime(v string) (time.Time, error) {
+	if ts, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return ts, nil
+	}
+
+	seconds, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bad timestamp %q", v)
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))), nil
+}
+
+func firstField(fields map[string]string, names ...string) string {
+	for _, name := range names {
+		if v, ok := fields[name]; ok && v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testReplayCsv = `sensor,timestamp,metric,value,species
+a1,2023-11-01T00:00:00Z,temperature,12.5,
+a1,2023-11-01T00:00:00.2Z,transparency,40,
+a1,2023-11-01T00:00:00.3Z,fish,4,Tuna
+b2,2023-11-01T00:00:01Z,temperature,8,
+a1,2023-11-01T00:00:10Z,temperature,13,
+`
+
+func TestReadReplayFrames(t *testing.T) {
+	reader, err := newReplayReader(strings.NewReader(testReplayCsv), "data.csv")
+	require.NoError(t, err, err)
+
+	frames := make([]*replayFrame, 0)
+	err = readReplayFrames(reader, func(frame *replayFrame) error {
+		frames = append(frames, frame)
+		return nil
+	})
+	require.NoError(t, err, err)
+	require.Equal(t, 3, len(frames))
+
+	assert.Equal(t, "a1", frames[0].sensor)
+	assert.Equal(t, 12.5, *frames[0].temperature)
+	assert.Equal(t, 40.0, *frames[0].transparency)
+	assert.Equal(t, map[string]uint64{"Tuna": 4}, frames[0].fishes)
+
+	assert.Equal(t, "b2", frames[1].sensor)
+	assert.Nil(t, frames[1].transparency)
+
+	assert.Equal(t, "a1", frames[2].sensor)
+	assert.Equal(t, 10*time.Second, frames[2].at.Sub(frames[0].at))
+}
+
+func TestReplayCheck(t *testing.T) {
+	dir := t.TempDir()
+	file := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	_, err := NewGenerator(nil, WithReplay(filepath.Join(dir, "missing.csv"), 1, false))
+	assert.ErrorContains(t, err, "missing.csv", "the generator is not created for a missing file")
+
+	rules := &replayRules{path: file("good.csv", testReplayCsv)}
+	assert.NoError(t, rules.check())
+
+	rules.path = file("columns.csv", "sensor,metric,value\na1,temperature,12.5\n")
+	assert.ErrorIs(t, rules.check(), ErrBadReplayFile)
+
+	rules.path = file("empty.csv", "")
+	assert.ErrorIs(t, rules.check(), ErrBadReplayFile)
+
+	rules.path = file("header.csv", "sensor,timestamp,metric,value\n")
+	assert.ErrorIs(t, rules.check(), ErrBadReplayFile, "a dataset without records")
+
+	rules.path = file("bad.ndjson", `{"sensor": "a1", "timestamp": "yesterday", "value": 1}`)
+	assert.ErrorIs(t, rules.check(), ErrBadReplayRecord)
+
+	rules.path = file("data.xml", "<readings/>")
+	assert.Error(t, rules.check())
+}
+
+func TestReplaySensorMapKeep(t *testing.T) {
+	newNode := func(id uint) *regenerateNode {
+		n := &regenerateNode{sensor: &storage.Sensor{}}
+		n.sensor.ID = id
+		return n
+	}
+
+	first, second, third := newNode(1), newNode(2), newNode(3)
+	previous := newReplaySensorMap([]*regenerateNode{first, second}, nil)
+	n, ok := previous.resolve("x")
+	require.True(t, ok)
+	assert.Equal(t, first, n)
+	n, _ = previous.resolve("y")
+	assert.Equal(t, second, n)
+
+	m := newReplaySensorMap([]*regenerateNode{second, third}, nil)
+	m.keep(previous)
+
+	n, _ = m.resolve("y")
+	assert.Equal(t, second, n, "a recorded sensor keeps its sensor")
+	n, _ = m.resolve("x")
+	assert.Equal(t, third, n, "the removed sensor is replaced by the added one")
+
+	empty := newReplaySensorMap(nil, nil)
+	_, ok = empty.resolve("x")
+	assert.False(t, ok, "nothing is replayed without sensors")
+}
@@ -0,0 +1,239 @@
+package generator
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultJitter = 0.05
+
+	// maxBurst is the most missed updates sent one after another, a sensor further behind skips them.
+	maxBurst = 10
+)
+
+// CatchUpPolicy decides what happens to the updates a sensor missed while the generator was behind.
+type CatchUpPolicy uint8
+
+const (
+	// CatchUpSkip drops the missed updates and continues on the original schedule.
+	CatchUpSkip CatchUpPolicy = iota
+	// CatchUpBurst sends the missed updates one after another until the sensor is on schedule.
+	CatchUpBurst
+)
+
+// SensorLag shows how late the updates of a sensor are started compared to their schedule.
+type SensorLag struct {
+	SensorId   uint
+	Lag        time.Duration
+	MaxLag     time.Duration
+	Updates    uint64
+	Skipped    uint64
+	NextUpdate time.Time
+}
+
+// schedule is the scheduling state of a sensor, it is guarded by the scheduler lock.
+type schedule struct {
+	nominal time.Time
+	due     time.Time
+
+	queued  bool
+	index   int
+	removed bool
+
+	lag, maxLag      time.Duration
+	updates, skipped uint64
+}
+
+// scheduler keeps the sensors in a min-heap ordered by the time of their next update and
+// sleeps until the earliest one is due, so it costs nothing between the updates.
+type scheduler struct {
+	lock  sync.Mutex
+	queue scheduleQueue
+	wake  chan struct{}
+
+	jitter  float64
+	catchUp CatchUpPolicy
+}
+
+func newScheduler(jitter float64, catchUp CatchUpPolicy) *scheduler {
+	return &scheduler{
+		wake:    make(chan struct{}, 1),
+		jitter:  jitter,
+		catchUp: catchUp,
+	}
+}
+
+// Add schedules the first update of the sensor, the first updates are spread over the jitter.
+func (s *scheduler) Add(n *regenerateNode, rate time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	n.schedule = schedule{nominal: time.Now()}
+	n.schedule.due = n.schedule.nominal.Add(time.Duration(random.Float64() * s.jitter * float64(rate)))
+	s.push(n)
+}
+
+// Remove stops the updates of the sensor, an update in progress is not rescheduled.
+func (s *scheduler) Remove(n *regenerateNode) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	n.schedule.removed = true
+	if n.schedule.queued {
+		heap.Remove(&s.queue, n.schedule.index)
+	}
+}
+
+// Done records the lag of the update started at the given time and schedules the next one.
+func (s *scheduler) Done(n *regenerateNode, rate time.Duration, started time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sc := &n.schedule
+	if sc.removed {
+		return
+	}
+
+	sc.updates++
+	sc.lag = started.Sub(sc.due)
+	if sc.lag > sc.maxLag {
+		sc.maxLag = sc.lag
+	}
+
+	if rate <= 0 {
+		rate = time.Second
+	}
+
+	now := time.Now()
+	next := sc.nominal.Add(rate)
+	if next.Before(now) {
+		missed := uint64(now.Sub(next) / rate)
+		if s.catchUp == CatchUpSkip || missed >= maxBurst {
+			next = next.Add(time.Duration(missed+1) * rate)
+			sc.skipped += missed + 1
+		}
+	}
+
+	sc.nominal = next
+	sc.due = next.Add(time.Duration((2*random.Float64() - 1) * s.jitter * float64(rate)))
+	s.push(n)
+}
+
+// Run sends the sensors to out when their updates are due until ctx is done.
+func (s *scheduler) Run(ctx context.Context, out chan<- *regenerateNode) {
+	timer := time.NewTimer(time.Hour)
+	stopTimer(timer)
+
+	for {
+		s.lock.Lock()
+		var next *regenerateNode
+		wait := time.Duration(-1)
+		if len(s.queue) > 0 {
+			if d := time.Until(s.queue[0].schedule.due); d > 0 {
+				wait = d
+			} else {
+				next = heap.Pop(&s.queue).(*regenerateNode)
+			}
+		}
+		s.lock.Unlock()
+
+		if next != nil {
+			select {
+			case out <- next:
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		var fired <-chan time.Time
+		if wait >= 0 {
+			timer.Reset(wait)
+			fired = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			stopTimer(timer)
+			return
+		case <-s.wake:
+		case <-fired:
+		}
+		stopTimer(timer)
+	}
+}
+
+// Lag returns the scheduling metrics of the sensors.
+func (s *scheduler) Lag(nodes []*regenerateNode) []*SensorLag {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	lags := make([]*SensorLag, 0, len(nodes))
+	for _, n := range nodes {
+		lags = append(lags, &SensorLag{
+			SensorId:   n.sensor.ID,
+			Lag:        n.schedule.lag,
+			MaxLag:     n.schedule.maxLag,
+			Updates:    n.schedule.updates,
+			Skipped:    n.schedule.skipped,
+			NextUpdate: n.schedule.due,
+		})
+	}
+
+	return lags
+}
+
+// push queues the sensor and wakes the scheduler up if it became the earliest one.
+func (s *scheduler) push(n *regenerateNode) {
+	heap.Push(&s.queue, n)
+	if n.schedule.index == 0 {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func stopTimer(timer *time.Timer) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+}
+
+type scheduleQueue []*regenerateNode
+
+func (q scheduleQueue) Len() int { return len(q) }
+
+func (q scheduleQueue) Less(i, j int) bool {
+	return q[i].schedule.due.Before(q[j].schedule.due)
+}
+
+func (q scheduleQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].schedule.index = i
+	q[j].schedule.index = j
+}
+
+func (q *scheduleQueue) Push(x any) {
+	n := x.(*regenerateNode)
+	n.schedule.index = len(*q)
+	n.schedule.queued = true
+	*q = append(*q, n)
+}
+
+func (q *scheduleQueue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+
+	n.schedule.queued = false
+	n.schedule.index = -1
+	return n
+}
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerOrder(t *testing.T) {
+	s := newScheduler(0, CatchUpSkip)
+
+	for i := 3; i > 0; i-- {
+		n := &regenerateNode{sensor: &storage.Sensor{}}
+		n.sensor.ID = uint(i)
+		n.schedule.due = time.Now().Add(time.Duration(i) * 10 * time.Millisecond)
+		s.push(n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	out := make(chan *regenerateNode)
+	go s.Run(ctx, out)
+
+	for i := uint(1); i <= 3; i++ {
+		select {
+		case n := <-out:
+			assert.Equal(t, i, n.sensor.ID)
+		case <-ctx.Done():
+			require.Fail(t, "sensor is not scheduled")
+		}
+	}
+}
+
+func TestSchedulerCatchUp(t *testing.T) {
+	rate := time.Second
+	behind := func(policy CatchUpPolicy) *regenerateNode {
+		s := newScheduler(0, policy)
+		n := &regenerateNode{sensor: &storage.Sensor{}}
+		s.Add(n, rate)
+
+		n.schedule.nominal = time.Now().Add(-5 * rate)
+		n.schedule.due = n.schedule.nominal
+		s.Done(n, rate, time.Now())
+
+		return n
+	}
+
+	t.Run("Skip", func(t *testing.T) {
+		n := behind(CatchUpSkip)
+		assert.True(t, n.schedule.due.After(time.Now()))
+		assert.Equal(t, uint64(5), n.schedule.skipped)
+		assert.GreaterOrEqual(t, n.schedule.lag, 5*rate)
+	})
+
+	t.Run("Burst", func(t *testing.T) {
+		n := behind(CatchUpBurst)
+		assert.True(t, n.schedule.due.Before(time.Now()))
+		assert.Equal(t, uint64(0), n.schedule.skipped)
+	})
+
+	t.Run("Removed", func(t *testing.T) {
+		s := newScheduler(0, CatchUpSkip)
+		n := &regenerateNode{sensor: &storage.Sensor{}}
+		s.Add(n, rate)
+		s.Remove(n)
+		s.Done(n, rate, time.Now())
+
+		assert.Equal(t, 0, s.queue.Len())
+	})
+}
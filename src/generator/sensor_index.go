@@ -0,0 +1,346 @@
+package generator
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+const dimensions = 3
+
+type Coordinate struct {
+	X, Y, Z float64
+}
+
+func (c *Coordinate) axis(a int) float64 {
+	switch a {
+	case 0:
+		return c.X
+	case 1:
+		return c.Y
+	default:
+		return c.Z
+	}
+}
+
+func sensorCoordinate(sensor *storage.Sensor) Coordinate {
+	return Coordinate{X: sensor.X, Y: sensor.Y, Z: sensor.Z}
+}
+
+func distance(c1, c2 *Coordinate) float64 {
+	dx := c2.X - c1.X
+	dy := c2.Y - c1.Y
+	dz := c2.Z - c1.Z
+
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+type kdNode struct {
+	node       *regenerateNode
+	coordinate Coordinate
+	axis       int
+	removed    bool
+
+	left, right *kdNode
+}
+
+// sensorIndex is a k-d tree over sensor coordinates. Besides the tree itself it keeps
+// for every node its nearest neighbours and the reverse links, so adding, moving or
+// removing a sensor only recomputes neighbours of the nodes it affects.
+type sensorIndex struct {
+	root  *kdNode
+	nodes map[uint]*kdNode
+
+	neighboursCount int
+	maxRadius       float64
+	removed         int
+}
+
+func newSensorIndex(neighboursCount int) *sensorIndex {
+	return &sensorIndex{
+		nodes:           make(map[uint]*kdNode),
+		neighboursCount: neighboursCount,
+	}
+}
+
+func (si *sensorIndex) Len() int {
+	return len(si.nodes)
+}
+
+func (si *sensorIndex) Get(id uint) (*regenerateNode, bool) {
+	kn, ok := si.nodes[id]
+	if !ok {
+		return nil, false
+	}
+
+	return kn.node, true
+}
+
+// Build replaces the whole index with a balanced tree over nodes and recomputes all neighbours.
+func (si *sensorIndex) Build(nodes []*regenerateNode) {
+	kdNodes := make([]*kdNode, 0, len(nodes))
+	si.nodes = make(map[uint]*kdNode, len(nodes))
+	for _, n := range nodes {
+		kn := &kdNode{node: n, coordinate: sensorCoordinate(n.sensor)}
+		kdNodes = append(kdNodes, kn)
+		si.nodes[n.sensor.ID] = kn
+	}
+
+	si.root = buildKdTree(kdNodes, 0)
+	si.removed = 0
+	si.maxRadius = 0
+
+	for _, n := range nodes {
+		n.neighbours = nil
+		n.dependents = make(map[*regenerateNode]struct{})
+	}
+
+	for _, n := range nodes {
+		si.link(n)
+	}
+}
+
+// Insert adds the node to the index and refreshes neighbours of the nodes which now
+// have it among their nearest.
+func (si *sensorIndex) Insert(n *regenerateNode) {
+	if _, ok := si.nodes[n.sensor.ID]; ok {
+		si.Remove(n.sensor.ID)
+	}
+
+	kn := &kdNode{node: n, coordinate: sensorCoordinate(n.sensor)}
+	si.root = insertKdNode(si.root, kn, 0)
+	si.nodes[n.sensor.ID] = kn
+
+	if n.dependents == nil {
+		n.dependents = make(map[*regenerateNode]struct{})
+	}
+
+	affected := si.withinRadius(&kn.coordinate, si.maxRadius, n)
+	si.link(n)
+
+	for _, a := range affected {
+		if len(a.neighbours) < si.neighboursCount || distance(&kn.coordinate, &a.coordinate) < a.radius {
+			si.link(a)
+		}
+	}
+}
+
+// Remove deletes the sensor from the index and refreshes neighbours of the nodes which had it among their nearest.
+func (si *sensorIndex) Remove(id uint) (*regenerateNode, bool) {
+	kn, ok := si.nodes[id]
+	if !ok {
+		return nil, false
+	}
+
+	kn.removed = true
+	delete(si.nodes, id)
+	si.removed++
+
+	n := kn.node
+	si.unlink(n)
+
+	for dependent := range n.dependents {
+		si.link(dependent)
+	}
+	n.dependents = make(map[*regenerateNode]struct{})
+
+	if si.removed > len(si.nodes) {
+		si.rebuildTree()
+	}
+
+	return n, true
+}
+
+// Move updates the node coordinate, taken from its sensor, in the index.
+func (si *sensorIndex) Move(n *regenerateNode) {
+	si.Remove(n.sensor.ID)
+	si.Insert(n)
+}
+
+// Nearest returns up to k nodes closest to the coordinate ordered by distance, exclude is skipped.
+func (si *sensorIndex) Nearest(c *Coordinate, k int, exclude *regenerateNode) []*regenerateNode {
+	if k <= 0 {
+		return nil
+	}
+
+	best := make(neighbourHeap, 0, k)
+	searchNearest(si.root, c, k, exclude, &best)
+
+	sort.Sort(sort.Reverse(&best))
+	nodes := make([]*regenerateNode, len(best))
+	for i, b := range best {
+		nodes[i] = b.node
+	}
+
+	return nodes
+}
+
+func (si *sensorIndex) withinRadius(c *Coordinate, radius float64, exclude *regenerateNode) []*regenerateNode {
+	nodes := make([]*regenerateNode, 0)
+	searchRadius(si.root, c, radius, exclude, &nodes)
+
+	return nodes
+}
+
+func (si *sensorIndex) link(n *regenerateNode) {
+	si.unlink(n)
+
+	n.coordinate = sensorCoordinate(n.sensor)
+	n.neighbours = si.Nearest(&n.coordinate, si.neighboursCount, n)
+	for _, neighbour := range n.neighbours {
+		neighbour.dependents[n] = struct{}{}
+	}
+
+	n.radius = math.Inf(1)
+	if len(n.neighbours) == si.neighboursCount && len(n.neighbours) > 0 {
+		n.radius = distance(&n.coordinate, &n.neighbours[len(n.neighbours)-1].coordinate)
+	}
+
+	if n.radius > si.maxRadius {
+		si.maxRadius = n.radius
+	}
+}
+
+func (si *sensorIndex) unlink(n *regenerateNode) {
+	for _, neighbour := range n.neighbours {
+		delete(neighbour.dependents, n)
+	}
+	n.neighbours = nil
+}
+
+func (si *sensorIndex) rebuildTree() {
+	kdNodes := make([]*kdNode, 0, len(si.nodes))
+	for _, kn := range si.nodes {
+		kdNodes = append(kdNodes, &kdNode{node: kn.node, coordinate: kn.coordinate})
+	}
+
+	si.root = buildKdTree(kdNodes, 0)
+	si.removed = 0
+
+	si.maxRadius = 0
+	for _, kn := range kdNodes {
+		si.nodes[kn.node.sensor.ID] = kn
+		if kn.node.radius > si.maxRadius {
+			si.maxRadius = kn.node.radius
+		}
+	}
+}
+
+func buildKdTree(nodes []*kdNode, depth int) *kdNode {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	axis := depth % dimensions
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].coordinate.axis(axis) < nodes[j].coordinate.axis(axis)
+	})
+
+	median := len(nodes) / 2
+	for median > 0 && nodes[median-1].coordinate.axis(axis) == nodes[median].coordinate.axis(axis) {
+		median--
+	}
+
+	root := nodes[median]
+	root.axis = axis
+	root.left = buildKdTree(nodes[:median], depth+1)
+	root.right = buildKdTree(nodes[median+1:], depth+1)
+
+	return root
+}
+
+func insertKdNode(root, kn *kdNode, depth int) *kdNode {
+	if root == nil {
+		kn.axis = depth % dimensions
+		kn.left, kn.right = nil, nil
+		return kn
+	}
+
+	if kn.coordinate.axis(root.axis) < root.coordinate.axis(root.axis) {
+		root.left = insertKdNode(root.left, kn, depth+1)
+	} else {
+		root.right = insertKdNode(root.right, kn, depth+1)
+	}
+
+	return root
+}
+
+func searchNearest(kn *kdNode, c *Coordinate, k int, exclude *regenerateNode, best *neighbourHeap) {
+	if kn == nil {
+		return
+	}
+
+	if !kn.removed && kn.node != exclude {
+		d := distance(c, &kn.coordinate)
+		if best.Len() < k {
+			heap.Push(best, neighbour{node: kn.node, distance: d})
+		} else if d < (*best)[0].distance {
+			(*best)[0] = neighbour{node: kn.node, distance: d}
+			heap.Fix(best, 0)
+		}
+	}
+
+	diff := c.axis(kn.axis) - kn.coordinate.axis(kn.axis)
+	near, far := kn.left, kn.right
+	if diff >= 0 {
+		near, far = kn.right, kn.left
+	}
+
+	searchNearest(near, c, k, exclude, best)
+	if best.Len() < k || math.Abs(diff) <= (*best)[0].distance {
+		searchNearest(far, c, k, exclude, best)
+	}
+}
+
+func searchRadius(kn *kdNode, c *Coordinate, radius float64, exclude *regenerateNode, nodes *[]*regenerateNode) {
+	if kn == nil {
+		return
+	}
+
+	if !kn.removed && kn.node != exclude && distance(c, &kn.coordinate) <= radius {
+		*nodes = append(*nodes, kn.node)
+	}
+
+	diff := c.axis(kn.axis) - kn.coordinate.axis(kn.axis)
+	if diff < 0 || math.Abs(diff) <= radius {
+		searchRadius(kn.left, c, radius, exclude, nodes)
+	}
+	if diff >= 0 || math.Abs(diff) <= radius {
+		searchRadius(kn.right, c, radius, exclude, nodes)
+	}
+}
+
+type neighbour struct {
+	node     *regenerateNode
+	distance float64
+}
+
+// neighbourHeap is a max-heap by distance, so the farthest of the found neighbours is on top.
+type neighbourHeap []neighbour
+
+func (h neighbourHeap) Len() int {
+	return len(h)
+}
+
+func (h neighbourHeap) Less(i, j int) bool {
+	return h[i].distance > h[j].distance
+}
+
+func (h neighbourHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *neighbourHeap) Push(x any) {
+	*h = append(*h, x.(neighbour))
+}
+
+func (h *neighbourHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+
+	return x
+}
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestSensorIndex(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	newNode := func(id uint) *regenerateNode {
+		return &regenerateNode{sensor: &storage.Sensor{
+			Model: gorm.Model{ID: id},
+			X:     rnd.Float64() * 100,
+			Y:     rnd.Float64() * 100,
+			Z:     rnd.Float64() * 100,
+		}}
+	}
+
+	nodes := make(map[uint]*regenerateNode)
+	initial := make([]*regenerateNode, 0, 200)
+	for id := uint(1); id <= 200; id++ {
+		nodes[id] = newNode(id)
+		initial = append(initial, nodes[id])
+	}
+
+	index := newSensorIndex(defaultNeighboursCount)
+	index.Build(initial)
+	assertNeighbours(t, index, nodes)
+
+	t.Run("Insert", func(t *testing.T) {
+		for id := uint(201); id <= 300; id++ {
+			nodes[id] = newNode(id)
+			index.Insert(nodes[id])
+		}
+		assertNeighbours(t, index, nodes)
+	})
+
+	t.Run("Move", func(t *testing.T) {
+		for id := uint(1); id <= 50; id++ {
+			n := nodes[id]
+			n.sensor.X, n.sensor.Y, n.sensor.Z = rnd.Float64()*100, rnd.Float64()*100, rnd.Float64()*100
+			index.Move(n)
+		}
+		assertNeighbours(t, index, nodes)
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		for id := uint(100); id <= 280; id++ {
+			_, ok := index.Remove(id)
+			require.True(t, ok)
+			delete(nodes, id)
+		}
+		assertNeighbours(t, index, nodes)
+	})
+}
+
+func assertNeighbours(t *testing.T, index *sensorIndex, nodes map[uint]*regenerateNode) {
+	require.Equal(t, len(nodes), index.Len())
+
+	for _, n := range nodes {
+		c := sensorCoordinate(n.sensor)
+		expected := make([]*regenerateNode, 0, len(nodes))
+		for _, other := range nodes {
+			if other != n {
+				expected = append(expected, other)
+			}
+		}
+		sort.Slice(expected, func(i, j int) bool {
+			ci, cj := sensorCoordinate(expected[i].sensor), sensorCoordinate(expected[j].sensor)
+			return distance(&c, &ci) < distance(&c, &cj)
+		})
+
+		if len(expected) > index.neighboursCount {
+			expected = expected[:index.neighboursCount]
+		}
+		require.Equal(t, expected, n.neighbours, "sensor %d", n.sensor.ID)
+	}
+}
@@ -0,0 +1,196 @@
+package generator
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+const (
+	// WorkerTTL is how long a worker is considered alive after its last heartbeat.
+	WorkerTTL = 15 * time.Second
+
+	workerHeartbeatInterval = WorkerTTL / 3
+	ringReplicas            = 64
+)
+
+// hashRing places every worker at many points of a ring, a key belongs to the worker at the
+// first point after its hash. Adding or removing a worker moves only the keys next to its points.
+type hashRing struct {
+	points []uint64
+	owners map[uint64]string
+}
+
+func newHashRing(workers []string) *hashRing {
+	r := &hashRing{
+		points: make([]uint64, 0, len(workers)*ringReplicas),
+		owners: make(map[uint64]string, len(workers)*ringReplicas),
+	}
+
+	for _, worker := range workers {
+		for i := 0; i < ringReplicas; i++ {
+			point := ringHash(worker + "#" + strconv.Itoa(i))
+			if _, ok := r.owners[point]; ok {
+				continue
+			}
+
+			r.points = append(r.points, point)
+			r.owners[point] = worker
+		}
+	}
+
+	sort.Slice(r.points, func(i, j int) bool {
+		return r.points[i] < r.points[j]
+	})
+
+	return r
+}
+
+func (r *hashRing) owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+
+	h := ringHash(key)
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i] >= h
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+
+	return r.owners[r.points[i]]
+}
+
+func ringHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+
+	return splitMix(h.Sum64())
+}
+
+// GroupOwners assigns every group to one of the workers by its name, groups are left out when
+// there are no workers.
+func GroupOwners(workers []*storage.Worker, groups []*storage.Group) map[uint]string {
+	ids := make([]string, 0, len(workers))
+	for _, worker := range workers {
+		ids = append(ids, worker.Id)
+	}
+
+	ring := newHashRing(ids)
+	owners := make(map[uint]string, len(groups))
+	for _, group := range groups {
+		if owner := ring.owner(group.Name); owner != "" {
+			owners[group.ID] = owner
+		}
+	}
+
+	return owners
+}
+
+// RunWorker runs the generator as one of the workers sharing the sensors until ctx is done.
+// The worker announces itself in the registry and generates data for the groups assigned to
+// it, the assignment follows the workers joining and leaving.
+func (g *Generator) RunWorker(ctx context.Context, id string) error {
+	g.setOwnedGroups(map[uint64]struct{}{})
+
+	if err := g.storage.RegisterWorker(ctx, id); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := g.storage.RemoveWorker(context.Background(), id); err != nil {
+			log.Printf("cannot unregister worker %s: %s\n", id, err)
+		}
+	}()
+
+	if err := g.Start(ctx); err != nil {
+		g.Stop()
+		return err
+	}
+	defer g.Stop()
+
+	ticker := time.NewTicker(workerHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := g.rebalance(ctx, id); err != nil && ctx.Err() == nil {
+			log.Printf("cannot rebalance worker %s: %s\n", id, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// rebalance renews the worker heartbeat and takes the groups assigned to it now.
+func (g *Generator) rebalance(ctx context.Context, id string) error {
+	if err := g.storage.RegisterWorker(ctx, id); err != nil {
+		return err
+	}
+
+	workers, err := g.storage.GetWorkers(ctx, WorkerTTL)
+	if err != nil {
+		return err
+	}
+
+	groups, err := g.storage.GetAllGroups()
+	if err != nil {
+		return err
+	}
+
+	owned := make(map[uint64]struct{})
+	for group, owner := range GroupOwners(workers, groups) {
+		if owner == id {
+			owned[uint64(group)] = struct{}{}
+		}
+	}
+
+	if !g.setOwnedGroups(owned) {
+		return nil
+	}
+
+	log.Printf("worker %s owns %d of %d groups among %d workers\n", id, len(owned), len(groups), len(workers))
+	return g.syncSensors()
+}
+
+// setOwnedGroups limits the generation to the sensors of the groups and reports whether they changed.
+func (g *Generator) setOwnedGroups(groups map[uint64]struct{}) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.owned != nil && len(g.owned) == len(groups) {
+		changed := false
+		for group := range groups {
+			if _, ok := g.owned[group]; !ok {
+				changed = true
+				break
+			}
+		}
+
+		if !changed {
+			return false
+		}
+	}
+
+	g.owned = groups
+	return true
+}
+
+// owns reports whether the generator is responsible for the sensor, the lock has to be held.
+func (g *Generator) owns(sensor *storage.Sensor) bool {
+	if g.owned == nil {
+		return true
+	}
+
+	_, ok := g.owned[sensor.GroupId]
+	return ok
+}
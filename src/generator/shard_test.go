@@ -0,0 +1,43 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupOwners(t *testing.T) {
+	groups := make([]*storage.Group, 0, len(greekLetters))
+	for i, name := range greekLetters {
+		group := &storage.Group{Name: name}
+		group.ID = uint(i + 1)
+		groups = append(groups, group)
+	}
+
+	workers := []*storage.Worker{{Id: "a"}, {Id: "b"}, {Id: "c"}}
+	owners := GroupOwners(workers, groups)
+	require.Equal(t, len(groups), len(owners))
+
+	counts := make(map[string]int)
+	for _, owner := range owners {
+		counts[owner]++
+	}
+	assert.Equal(t, len(workers), len(counts), "every worker owns groups")
+
+	t.Run("WorkerLeaves", func(t *testing.T) {
+		left := GroupOwners(workers[:2], groups)
+		for id, owner := range owners {
+			if owner != "c" {
+				assert.Equal(t, owner, left[id], "only groups of the left worker move")
+			}
+			assert.NotEqual(t, "c", left[id])
+		}
+	})
+
+	t.Run("NoWorkers", func(t *testing.T) {
+		assert.Empty(t, GroupOwners(nil, groups))
+	})
+}
@@ -0,0 +1,70 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+type jsonFish struct {
+	Name  string `json:"name"`
+	Count uint64 `json:"count"`
+}
+
+type jsonReading struct {
+	SensorId     uint64     `json:"sensor_id"`
+	Sensor       string     `json:"sensor"`
+	Timestamp    time.Time  `json:"timestamp"`
+	Temperature  *float64   `json:"temperature"`
+	Transparency *float64   `json:"transparency"`
+	Fishes       []jsonFish `json:"fishes"`
+}
+
+type jsonBatch struct {
+	Readings []*jsonReading `json:"readings"`
+}
+
+// ParseJSON reads readings sent either as {"readings": [...]} or as a plain array.
+func ParseJSON(r io.Reader) ([]*storage.Reading, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var readings []*jsonReading
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &readings)
+	} else {
+		batch := &jsonBatch{}
+		err = json.Unmarshal(trimmed, batch)
+		readings = batch.Readings
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*storage.Reading, 0, len(readings))
+	for _, r := range readings {
+		reading := &storage.Reading{
+			SensorId:     r.SensorId,
+			CodeName:     r.Sensor,
+			Timestamp:    r.Timestamp,
+			Temperature:  r.Temperature,
+			Transparency: r.Transparency,
+		}
+
+		if r.Fishes != nil {
+			reading.Fishes = make(map[string]uint64, len(r.Fishes))
+			for _, fish := range r.Fishes {
+				reading.Fishes[fish.Name] += fish.Count
+			}
+		}
+
+		result = append(result, reading)
+	}
+
+	return result, nil
+}
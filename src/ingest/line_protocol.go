@@ -0,0 +1,207 @@
+package ingest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenyasd209/fake-sensors/src/storage"
+)
+
+const (
+	sensorMeasurement = "sensor"
+	fishMeasurement   = "fish"
+
+	idTag      = "id"
+	codeTag    = "code"
+	speciesTag = "species"
+
+	temperatureField  = "temperature"
+	transparencyField = "transparency"
+	countField        = "count"
+)
+
+var ErrBadLine = errors.New("bad line")
+
+// ParseLineProtocol reads readings in InfluxDB line protocol, e.g.
+//
+//	sensor,code=alpha3 temperature=12.5,transparency=40i 1700000000000000000
+//	fish,code=alpha3,species=Atlantic\ cod count=4i 1700000000000000000
+//
+// Lines of one sensor with the same timestamp are joined into one reading, a missing timestamp means now.
+func ParseLineProtocol(r io.Reader) ([]*storage.Reading, error) {
+	readings := make([]*storage.Reading, 0)
+	byKey := make(map[string]*storage.Reading)
+	now := time.Now()
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p, err := parseLine(line, now)
+		if err != nil {
+			return nil, fmt.Errorf("%w %d: %s", ErrBadLine, lineNumber, err)
+		}
+
+		key := p.tags[idTag] + "/" + p.tags[codeTag] + "/" + strconv.FormatInt(p.timestamp.UnixNano(), 10)
+		reading, ok := byKey[key]
+		if !ok {
+			reading = &storage.Reading{CodeName: p.tags[codeTag], Timestamp: p.timestamp}
+			if id, ok := p.tags[idTag]; ok {
+				reading.SensorId, err = strconv.ParseUint(id, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%w %d: bad sensor id %q", ErrBadLine, lineNumber, id)
+				}
+			}
+
+			byKey[key] = reading
+			readings = append(readings, reading)
+		}
+
+		if err = p.apply(reading); err != nil {
+			return nil, fmt.Errorf("%w %d: %s", ErrBadLine, lineNumber, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return readings, nil
+}
+
+type point struct {
+	measurement string
+	tags        map[string]string
+	fields      map[string]string
+	timestamp   time.Time
+}
+
+func (p *point) apply(reading *storage.Reading) error {
+	switch p.measurement {
+	case sensorMeasurement:
+		for name, value := range p.fields {
+			v, err := parseNumber(value)
+			if err != nil {
+				return fmt.Errorf("field %s: %s", name, err)
+			}
+
+			switch name {
+			case temperatureField:
+				reading.Temperature = &v
+			case transparencyField:
+				reading.Transparency = &v
+			default:
+				return fmt.Errorf("unknown field %s", name)
+			}
+		}
+	case fishMeasurement:
+		species := p.tags[speciesTag]
+		if species == "" {
+			return errors.New("species tag is required")
+		}
+
+		count, err := parseNumber(p.fields[countField])
+		if err != nil || count < 0 {
+			return fmt.Errorf("bad count %q", p.fields[countField])
+		}
+
+		if reading.Fishes == nil {
+			reading.Fishes = make(map[string]uint64)
+		}
+		reading.Fishes[species] += uint64(count)
+	default:
+		return fmt.Errorf("unknown measurement %s", p.measurement)
+	}
+
+	return nil
+}
+
+func parseLine(line string, now time.Time) (*point, error) {
+	sections := splitEscaped(line, ' ')
+	if len(sections) < 2 || len(sections) > 3 {
+		return nil, errors.New("expected measurement, fields and optional timestamp")
+	}
+
+	series := splitEscaped(sections[0], ',')
+	p := &point{
+		measurement: unescape(series[0]),
+		tags:        make(map[string]string, len(series)-1),
+		fields:      make(map[string]string),
+		timestamp:   now,
+	}
+
+	for _, tag := range series[1:] {
+		kv := splitEscaped(tag, '=')
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("bad tag %q", tag)
+		}
+		p.tags[unescape(kv[0])] = unescape(kv[1])
+	}
+
+	for _, field := range splitEscaped(sections[1], ',') {
+		kv := splitEscaped(field, '=')
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("bad field %q", field)
+		}
+		p.fields[unescape(kv[0])] = kv[1]
+	}
+
+	if len(sections) == 3 {
+		ns, err := strconv.ParseInt(sections[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad timestamp %q", sections[2])
+		}
+		p.timestamp = time.Unix(0, ns)
+	}
+
+	return p, nil
+}
+
+// parseNumber parses float and integer (with the "i" or "u" suffix) field values.
+func parseNumber(v string) (float64, error) {
+	v = strings.TrimSuffix(strings.TrimSuffix(v, "i"), "u")
+	return strconv.ParseFloat(v, 64)
+}
+
+// splitEscaped splits s by sep ignoring separators escaped with a backslash or quoted.
+func splitEscaped(s string, sep byte) []string {
+	parts := make([]string, 0, 4)
+	start, quoted := 0, false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,36 @@
+package ingest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLineProtocol(t *testing.T) {
+	data := `# comment
+sensor,code=alpha3 temperature=12.5,transparency=40i 1700000000000000000
+fish,code=alpha3,species=Atlantic\ cod count=4i 1700000000000000000
+sensor,id=17 temperature=-1.5 1700000001000000000
+`
+
+	readings, err := ParseLineProtocol(strings.NewReader(data))
+	require.NoError(t, err, err)
+	require.Equal(t, 2, len(readings))
+
+	assert.Equal(t, "alpha3", readings[0].CodeName)
+	assert.Equal(t, time.Unix(0, 1700000000000000000), readings[0].Timestamp)
+	assert.Equal(t, 12.5, *readings[0].Temperature)
+	assert.Equal(t, 40.0, *readings[0].Transparency)
+	assert.Equal(t, map[string]uint64{"Atlantic cod": 4}, readings[0].Fishes)
+
+	assert.Equal(t, uint64(17), readings[1].SensorId)
+	assert.Equal(t, -1.5, *readings[1].Temperature)
+	assert.Nil(t, readings[1].Transparency)
+	assert.Nil(t, readings[1].Fishes)
+
+	_, err = ParseLineProtocol(strings.NewReader("weather,code=alpha3 temperature=1"))
+	assert.ErrorIs(t, err, ErrBadLine)
+}
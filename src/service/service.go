@@ -2,49 +2,206 @@ package service
 
 import (
 	"context"
+	"errors"
+	"log"
 	"os"
+	"strconv"
+	"time"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/jenyasd209/fake-sensors/src/api"
+	"github.com/jenyasd209/fake-sensors/src/api/routes"
+	"github.com/jenyasd209/fake-sensors/src/api/rpc"
+	"github.com/jenyasd209/fake-sensors/src/config"
 	"github.com/jenyasd209/fake-sensors/src/generator"
 	"github.com/jenyasd209/fake-sensors/src/storage"
 )
 
+const (
+	// generatorLeadership names the lock held by the only instance running the generator.
+	generatorLeadership = "fake-sensors/generator"
+	// healthLeadership names the lock held by the only instance recording sensor status changes.
+	healthLeadership = "fake-sensors/health"
+)
+
 type Service struct {
+	cfg *config.Config
+
+	storage   *storage.Storage
 	generator *generator.Generator
 	apiServer *api.Server
+	rpcServer *rpc.Server
 }
 
-func NewService() (*Service, error) {
-	s, err := storage.NewStorage(
-		storage.WithDbUser(os.Getenv("POSTGRES_USER")),
-		storage.WithDbPassword(os.Getenv("POSTGRES_PASSWORD")),
-		storage.WithDbPort(os.Getenv("POSTGRES_PORT")),
-		storage.WithDbHost(os.Getenv("POSTGRES_HOST")),
-		storage.WithDbName(os.Getenv("POSTGRES_DB")),
-		storage.WithRedisAddress(os.Getenv("REDIS_ADDRESS")),
-	)
+// OpenStorage connects to the configured storage.
+func OpenStorage(cfg *config.Config) (*storage.Storage, error) {
+	return storage.NewStorage(cfg.StorageOptions()...)
+}
+
+func NewService(cfg *config.Config) (*Service, error) {
+	s, err := OpenStorage(cfg)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	g, err := generator.NewGenerator(s)
+	g, err := generator.NewGenerator(s, cfg.GeneratorOptions()...)
 	if err != nil {
-		panic(err)
+		s.Close()
+		return nil, err
 	}
 
 	return &Service{
+		cfg:       cfg,
+		storage:   s,
 		generator: g,
-		apiServer: api.DefaultApiServer(s),
+		apiServer: api.DefaultApiServer(s, routes.WithGenerator(g)),
+		rpcServer: rpc.NewServer(s, rpc.WithPollInterval(cfg.Server.ReadingPollInterval.Duration())),
 	}, nil
 }
 
+// Start serves the REST and gRPC APIs and runs the generator until ctx is done, so any number of
+// instances may share the database. In the sharded generator mode every instance generates data
+// for its share of the groups, otherwise the generator runs only while this instance is the
+// leader. Status changes of the sensors are recorded by a single instance in any mode.
+//
+// On return the requests in progress are drained, the generator is stopped with its buffered
+// updates saved and the storage is closed. The storage is left open when the generator or the
+// health tracking do not stop within the shutdown timeout, which is reported in the error.
 func (s *Service) Start(ctx context.Context) error {
-	err := s.generator.Start(ctx)
-	if err != nil {
-		panic(err)
+	generatorCtx, stopGenerator := context.WithCancel(ctx)
+	defer stopGenerator()
+
+	generatorDone := make(chan struct{})
+	go func() {
+		defer close(generatorDone)
+
+		if s.cfg.Generator.Mode == config.ShardedMode {
+			s.runWorker(generatorCtx)
+		} else {
+			s.storage.NewLeadership(generatorLeadership).Run(generatorCtx, s.runGenerator)
+		}
+	}()
+
+	healthDone := make(chan struct{})
+	go func() {
+		defer close(healthDone)
+		s.storage.NewLeadership(healthLeadership).Run(generatorCtx, s.trackHealth)
+	}()
+
+	serverErr := make(chan error, 2)
+	go func() {
+		serverErr <- s.apiServer.Run(s.cfg.Server.Address)
+	}()
+	go func() {
+		serverErr <- s.rpcServer.Run(s.cfg.Server.GRPCAddress)
+	}()
+
+	var resultError error
+	select {
+	case <-ctx.Done():
+		log.Println("shutting down")
+	case err := <-serverErr:
+		if err != nil {
+			resultError = multierror.Append(resultError, err)
+		}
+	}
+
+	shutdownTimeout := s.cfg.Server.ShutdownTimeout.Duration()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := s.apiServer.Shutdown(shutdownCtx); err != nil {
+		resultError = multierror.Append(resultError, err)
+	}
+
+	if err := s.rpcServer.Shutdown(shutdownCtx); err != nil {
+		resultError = multierror.Append(resultError, err)
+	}
+
+	stopGenerator()
+	stopped := true
+	select {
+	case <-generatorDone:
+	case <-shutdownCtx.Done():
+		stopped = false
+		resultError = multierror.Append(resultError, errors.New("generator is not stopped in "+shutdownTimeout.String()))
+	}
+
+	select {
+	case <-healthDone:
+	case <-shutdownCtx.Done():
+		stopped = false
+		resultError = multierror.Append(resultError, errors.New("health tracking is not stopped in "+shutdownTimeout.String()))
+	}
+
+	// Closing the storage under a running generator would fail its last writes, the process exits
+	// with the connections open instead.
+	if !stopped {
+		return multierror.Append(resultError, errors.New("storage is left open"))
+	}
+
+	if err := s.storage.Close(); err != nil {
+		resultError = multierror.Append(resultError, err)
 	}
 
+	return resultError
+}
+
+// runGenerator runs the generator for all groups until ctx is done, it is run by the leader.
+func (s *Service) runGenerator(ctx context.Context) {
+	if err := s.generator.Start(ctx); err != nil {
+		log.Printf("cannot start the generator: %s\n", err)
+		s.generator.Stop()
+		return
+	}
 	defer s.generator.Stop()
 
-	return s.apiServer.Run(":8080")
+	<-ctx.Done()
+}
+
+// runWorker runs the generator for the groups assigned to this worker until ctx is done.
+func (s *Service) runWorker(ctx context.Context) {
+	if err := s.generator.RunWorker(ctx, s.workerId()); err != nil {
+		log.Printf("cannot run the generator: %s\n", err)
+	}
+}
+
+// trackHealth records the status changes of the sensors until ctx is done, whatever stopped the
+// sensors from reporting.
+func (s *Service) trackHealth(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Health.CheckInterval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			events, err := s.storage.RecordHealthTransitions(ctx, now)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("cannot record sensor status changes: %s\n", err)
+				}
+				continue
+			}
+
+			for _, event := range events {
+				log.Printf("sensor %d is %s, was %s\n", event.SensorId, event.ToStatus, event.FromStatus)
+			}
+		}
+	}
+}
+
+// workerId identifies this instance among the generator workers, the configured id overrides it.
+func (s *Service) workerId() string {
+	if s.cfg.Generator.WorkerId != "" {
+		return s.cfg.Generator.WorkerId
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "worker"
+	}
+
+	return host + "-" + strconv.Itoa(os.Getpid())
 }
@@ -0,0 +1,424 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultFlushInterval = time.Second
+	defaultFlushSize     = 1000
+
+	// A failed flush keeps the updates and is retried with a backoff growing up to
+	// maxRetryBackoff. Meanwhile at most maxBufferedFlushes flushes are buffered and writes block.
+	minRetryBackoff    = 100 * time.Millisecond
+	maxRetryBackoff    = 30 * time.Second
+	maxBufferedFlushes = 10
+	// closeAttempts is how many times Close tries to save the buffered updates before dropping them.
+	closeAttempts = 3
+
+	insertBatchSize = 1000
+)
+
+var (
+	ErrWriterClosed   = errors.New("batch writer is closed")
+	ErrUpdatesDropped = errors.New("sensor updates dropped")
+)
+
+// SensorUpdate is a set of readings reported by a sensor at once.
+type SensorUpdate struct {
+	Sensor       *Sensor
+	Fishes       []*Fish
+	Temperature  *Temperature
+	Transparency *Transparency
+	// Hardware is the changed hardware state of the sensor, nil if it did not change.
+	Hardware *SensorHardware
+	// Labels are the anomalies which started with the update, they may belong to other sensors
+	// affected by the same event.
+	Labels []*AnomalyLabel
+}
+
+type batchOptions struct {
+	flushInterval time.Duration
+	flushSize     int
+	fence         *leaderFence
+}
+
+type BatchOption func(opt *batchOptions)
+
+func WithFlushInterval(interval time.Duration) BatchOption {
+	return func(opt *batchOptions) {
+		if interval > 0 {
+			opt.flushInterval = interval
+		}
+	}
+}
+
+func WithFlushSize(size int) BatchOption {
+	return func(opt *batchOptions) {
+		if size > 0 {
+			opt.flushSize = size
+		}
+	}
+}
+
+// WithLeaderContext makes the writer save updates only while the leadership ctx was given for is
+// held, updates written after it was lost are dropped. Outside of a leadership ctx changes nothing.
+func WithLeaderContext(ctx context.Context) BatchOption {
+	return func(opt *batchOptions) {
+		opt.fence = leaderFenceOf(ctx)
+	}
+}
+
+// BatchWriter buffers sensor updates and saves them with multi-row inserts in a single
+// transaction once the buffer is full or the flush interval passes. Updates which cannot be
+// saved are retried, only the ones the database rejects as invalid are dropped.
+type BatchWriter struct {
+	storage *Storage
+	options *batchOptions
+
+	updates chan *SensorUpdate
+	flushCh chan chan error
+	closing chan struct{}
+	done    chan struct{}
+
+	// lock guards closed, writes hold it for reading so the updates channel is never closed
+	// under them.
+	lock      sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func (s *Storage) NewBatchWriter(opts ...BatchOption) *BatchWriter {
+	options := &batchOptions{
+		flushInterval: defaultFlushInterval,
+		flushSize:     defaultFlushSize,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	w := &BatchWriter{
+		storage: s,
+		options: options,
+		updates: make(chan *SensorUpdate, options.flushSize),
+		flushCh: make(chan chan error),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go w.run()
+	return w
+}
+
+// Write queues the update, it blocks while the buffer is full and fails once the writer is closed.
+func (w *BatchWriter) Write(update *SensorUpdate) error {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	select {
+	case w.updates <- update:
+		return nil
+	case <-w.closing:
+		return ErrWriterClosed
+	}
+}
+
+// Flush saves all queued updates immediately. It reports why the updates could not be saved and
+// the updates dropped by the background flushes since the previous Flush.
+func (w *BatchWriter) Flush(ctx context.Context) error {
+	res := make(chan error, 1)
+	select {
+	case w.flushCh <- res:
+	case <-w.done:
+		return ErrWriterClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-res:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close saves the queued updates and stops the writer, writes fail after it. It reports the
+// updates which were dropped since the last Flush, including the ones it could not save.
+func (w *BatchWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.closing)
+
+		w.lock.Lock()
+		w.closed = true
+		close(w.updates)
+		w.lock.Unlock()
+	})
+
+	<-w.done
+	return w.closeErr
+}
+
+func (w *BatchWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.options.flushInterval)
+	defer ticker.Stop()
+
+	buffer := make([]*SensorUpdate, 0, w.options.flushSize)
+	var (
+		backoff time.Duration
+		retryAt time.Time
+		// dropped is the error which lost updates since it was last reported.
+		dropped error
+	)
+
+	flush := func() error {
+		if len(buffer) == 0 {
+			return nil
+		}
+
+		left, count, err := w.write(buffer)
+		if count > 0 {
+			log.Printf("dropped %d sensor updates: %s\n", count, err)
+			dropped = fmt.Errorf("%w: %d rejected: %w", ErrUpdatesDropped, count, err)
+		}
+
+		if len(left) == 0 {
+			buffer = buffer[:0]
+			backoff, retryAt = 0, time.Time{}
+			return nil
+		}
+
+		buffer = append(buffer[:0], left...)
+		backoff = min(max(2*backoff, minRetryBackoff), maxRetryBackoff)
+		retryAt = time.Now().Add(backoff)
+		log.Printf("cannot save %d sensor updates, retrying in %s: %s\n", len(buffer), backoff, err)
+		return err
+	}
+
+	// report returns the error of the flush or else the one which dropped updates before.
+	report := func(err error) error {
+		if err == nil {
+			err = dropped
+		}
+		dropped = nil
+		return err
+	}
+
+	retry := func() {
+		if !time.Now().Before(retryAt) {
+			flush()
+		}
+	}
+
+	// finish saves the buffer when the writer is closed, it is retried a few times before it is
+	// dropped.
+	finish := func() error {
+		for attempt := 1; ; attempt++ {
+			err := flush()
+			if err == nil {
+				return nil
+			}
+
+			if attempt == closeAttempts {
+				log.Printf("dropped %d unsaved sensor updates\n", len(buffer))
+				return fmt.Errorf("%w: %d unsaved: %w", ErrUpdatesDropped, len(buffer), err)
+			}
+			time.Sleep(backoff)
+		}
+	}
+
+	closing := w.closing
+	for {
+		// While the failed updates are retried the writes block instead of growing the buffer,
+		// unless the writer is closing and they have to be drained.
+		updates := w.updates
+		if len(buffer) >= maxBufferedFlushes*w.options.flushSize && closing != nil {
+			updates = nil
+		}
+
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				w.closeErr = report(finish())
+				return
+			}
+
+			buffer = append(buffer, update)
+			if len(buffer) >= w.options.flushSize {
+				retry()
+			}
+		case res := <-w.flushCh:
+			for drained := false; !drained; {
+				select {
+				case update, ok := <-w.updates:
+					if !ok {
+						drained = true
+						break
+					}
+					buffer = append(buffer, update)
+				default:
+					drained = true
+				}
+			}
+			res <- report(flush())
+		case <-ticker.C:
+			retry()
+		case <-closing:
+			closing = nil
+		}
+	}
+}
+
+// write saves the updates and returns the ones left to retry. When the database rejects the
+// batch as invalid, it is halved until the rejected updates are found, only they are dropped
+// and counted. Every update is dropped once the leadership of the writer is lost.
+func (w *BatchWriter) write(updates []*SensorUpdate) ([]*SensorUpdate, int, error) {
+	err := w.storage.writeSensorUpdates(w.options.fence, updates)
+	if err == nil {
+		return nil, 0, nil
+	}
+
+	if errors.Is(err, ErrNotLeader) {
+		return nil, len(updates), err
+	}
+
+	var constraintErr *ConstraintError
+	if !errors.As(err, &constraintErr) {
+		return updates, 0, err
+	}
+
+	if len(updates) == 1 {
+		return nil, 1, err
+	}
+
+	half := len(updates) / 2
+	left, dropped, err := w.write(updates[:half])
+	rightLeft, rightDropped, rightErr := w.write(updates[half:])
+	if rightErr != nil && (err == nil || len(rightLeft) > 0) {
+		err = rightErr
+	}
+
+	return append(append([]*SensorUpdate(nil), left...), rightLeft...), dropped + rightDropped, err
+}
+
+// WriteSensorUpdates saves the updates in one transaction. Readings are inserted in batches and
+// the current state of every sensor is taken from its newest values.
+func (s *Storage) WriteSensorUpdates(updates []*SensorUpdate) error {
+	return s.writeSensorUpdates(nil, updates)
+}
+
+func (s *Storage) writeSensorUpdates(fence *leaderFence, updates []*SensorUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	changes := s.newCacheChanges()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if fence != nil {
+			if err := fence.check(tx); err != nil {
+				return err
+			}
+		}
+
+		fishes := make([]*Fish, 0, len(updates)*10)
+		temperatures := make([]*Temperature, 0, len(updates))
+		transparencies := make([]*Transparency, 0, len(updates))
+		observations := make(map[uint]*fishObservation, len(updates))
+		hardware := make(map[uint]*SensorHardware)
+		var labels []*AnomalyLabel
+
+		for _, u := range updates {
+			if u.Hardware != nil {
+				hardware[u.Hardware.SensorId] = u.Hardware
+			}
+			labels = append(labels, u.Labels...)
+			fishes = append(fishes, u.Fishes...)
+			if u.Temperature != nil {
+				temperatures = append(temperatures, u.Temperature)
+			}
+			if u.Transparency != nil {
+				transparencies = append(transparencies, u.Transparency)
+			}
+		}
+
+		if err := createInBatches(tx, fishes); err != nil {
+			return err
+		}
+		if err := createInBatches(tx, temperatures); err != nil {
+			return err
+		}
+		if err := createInBatches(tx, transparencies); err != nil {
+			return err
+		}
+		if err := updateHardware(tx, hardware); err != nil {
+			return err
+		}
+		if err := createInBatches(tx, labels); err != nil {
+			return err
+		}
+
+		readings := make(map[uint]*LatestReading, len(updates))
+		for _, u := range updates {
+			if u.Fishes != nil {
+				at := observedAt(u.Fishes, u.Temperature, u.Transparency)
+				if o, ok := observations[u.Sensor.ID]; !ok || !at.Before(o.at) {
+					observations[u.Sensor.ID] = &fishObservation{sensor: u.Sensor, fishes: u.Fishes, at: at}
+				}
+				changes.speciesChanged(uint(u.Sensor.GroupId))
+			}
+
+			if u.Temperature == nil && u.Transparency == nil {
+				continue
+			}
+
+			if reading, ok := readings[u.Sensor.ID]; ok {
+				reading.merge(u.Temperature, u.Transparency)
+			} else {
+				readings[u.Sensor.ID] = latestReading(u.Sensor, u.Temperature, u.Transparency)
+			}
+		}
+
+		return writeCurrentState(tx, observations, readings, changes)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.applyCacheChanges(context.Background(), changes)
+	return nil
+}
+
+func writeCurrentState(tx *gorm.DB, observations map[uint]*fishObservation, readings map[uint]*LatestReading, changes *cacheChanges) error {
+	if err := replaceCurrentFishes(tx, observations); err != nil {
+		return err
+	}
+
+	latest := make([]*LatestReading, 0, len(readings))
+	for _, reading := range readings {
+		latest = append(latest, reading)
+	}
+
+	return upsertLatestReadings(tx, latest, changes)
+}
+
+func createInBatches[T any](tx *gorm.DB, records []*T) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	return tx.CreateInBatches(records, insertBatchSize).Error
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const benchmarkSensorsCount = 50000
+
+func BenchmarkWriteSensorUpdates(b *testing.B) {
+	storage, err := connectToTestDb()
+	if err != nil {
+		b.Skip("test database is not available: ", err)
+	}
+	defer storage.Close()
+
+	group := &Group{Name: "benchmark" + strconv.FormatInt(time.Now().UnixNano(), 10)}
+	sensors := make([]*Sensor, 0, benchmarkSensorsCount)
+	for i := 0; i < benchmarkSensorsCount; i++ {
+		sensors = append(sensors, &Sensor{IndexInGroup: uint64(i), DataOutputRate: time.Second})
+	}
+
+	if err = storage.CreateGroup(group); err != nil {
+		b.Fatal(err)
+	}
+	for _, sensor := range sensors {
+		sensor.GroupId = uint64(group.ID)
+	}
+	// Removing the group removes its sensors with their readings.
+	defer storage.db.Exec("DELETE FROM "+GroupTable+" WHERE id = ?", group.ID)
+
+	if err = storage.db.CreateInBatches(sensors, insertBatchSize).Error; err != nil {
+		b.Fatal(err)
+	}
+
+	writer := storage.NewBatchWriter(WithFlushSize(defaultFlushSize), WithFlushInterval(time.Hour))
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		for _, sensor := range sensors {
+			err = writer.Write(&SensorUpdate{
+				Sensor: sensor,
+				Fishes: []*Fish{
+					{SensorId: uint64(sensor.ID), Name: "FishA", Count: uint64(i + 1)},
+					{SensorId: uint64(sensor.ID), Name: "FishB", Count: uint64(i + 2)},
+				},
+				Temperature:  &Temperature{SensorId: uint64(sensor.ID), Temperature: float64(i)},
+				Transparency: &Transparency{SensorId: uint64(sensor.ID), Transparency: uint8(i % 100)},
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		if err = writer.Flush(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportMetric(float64(b.N*benchmarkSensorsCount)/time.Since(start).Seconds(), "updates/s")
+
+	if err = writer.Close(); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func TestBatchWriterClosed(t *testing.T) {
+	writer := (&Storage{}).NewBatchWriter()
+	require.NoError(t, writer.Close())
+	require.NoError(t, writer.Close(), "closing twice is allowed")
+
+	require.ErrorIs(t, writer.Write(&SensorUpdate{}), ErrWriterClosed)
+	require.ErrorIs(t, writer.Flush(context.Background()), ErrWriterClosed)
+}
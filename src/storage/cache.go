@@ -0,0 +1,335 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultCacheTTL = 10 * time.Second
+
+	regionKey            = "region"
+	speciesKey           = "species"
+	regionGenerationKey  = "regionGeneration"
+	speciesGenerationKey = "speciesGeneration"
+	averageStateKey      = "State"
+
+	sumField   = "sum"
+	countField = "count"
+)
+
+// applyAverageDelta changes the kept sum and count only when they are cached, a missing state
+// is loaded from the database on the next read.
+var applyAverageDelta = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('HINCRBYFLOAT', KEYS[1], 'sum', ARGV[1])
+	redis.call('HINCRBY', KEYS[1], 'count', ARGV[2])
+end
+return 0
+`)
+
+// initAverageState saves the loaded sum and count unless a state was cached meanwhile.
+var initAverageState = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	redis.call('HSET', KEYS[1], 'sum', ARGV[1], 'count', ARGV[2])
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return 0
+`)
+
+type cacheOptions struct {
+	averageTTL   time.Duration
+	regionTTL    time.Duration
+	speciesTTL   time.Duration
+	writeThrough bool
+}
+
+// cacheChanges collects what a write changed, it is applied to the cache once the write is committed.
+type cacheChanges struct {
+	trackAverages bool
+
+	averages map[uint]*averageChange
+	species  map[uint]struct{}
+	regions  bool
+}
+
+// averageChange is the change of the sums and counts of the latest readings in a group.
+type averageChange struct {
+	temperatureSum, transparencySum     float64
+	temperatureCount, transparencyCount int64
+}
+
+func (s *Storage) newCacheChanges() *cacheChanges {
+	return &cacheChanges{
+		trackAverages: s.cache.writeThrough,
+		averages:      make(map[uint]*averageChange),
+		species:       make(map[uint]struct{}),
+	}
+}
+
+func (c *cacheChanges) average(groupId uint) *averageChange {
+	change, ok := c.averages[groupId]
+	if !ok {
+		change = &averageChange{}
+		c.averages[groupId] = change
+	}
+
+	return change
+}
+
+func (c *cacheChanges) speciesChanged(groupId uint) {
+	c.species[groupId] = struct{}{}
+}
+
+// readingsChanged records the groups of the readings. When averages are kept incrementally the
+// stored readings are locked and compared with the new ones, so the change is exactly what the
+// upsert does.
+func (c *cacheChanges) readingsChanged(tx *gorm.DB, readings []*LatestReading) error {
+	for _, reading := range readings {
+		c.average(reading.GroupId)
+		if reading.Temperature != nil {
+			c.regions = true
+		}
+	}
+
+	if !c.trackAverages {
+		return nil
+	}
+
+	stored := make(map[uint]*LatestReading, len(readings))
+	for i := 0; i < len(readings); i += insertBatchSize {
+		batch := readings[i:min(i+insertBatchSize, len(readings))]
+		ids := make([]uint, 0, len(batch))
+		for _, reading := range batch {
+			ids = append(ids, reading.SensorId)
+		}
+
+		var found []*LatestReading
+		res := tx.Raw("SELECT * FROM "+LatestReadingTable+" WHERE sensor_id IN ? FOR UPDATE", ids).Scan(&found)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		for _, reading := range found {
+			stored[reading.SensorId] = reading
+		}
+	}
+
+	for _, reading := range readings {
+		old := stored[reading.SensorId]
+		if old == nil {
+			old = &LatestReading{}
+		}
+
+		change := c.average(reading.GroupId)
+		if newer(reading.TemperatureAt, old.TemperatureAt) {
+			change.temperatureSum += *reading.Temperature
+			change.temperatureCount++
+			if old.Temperature != nil {
+				change.temperatureSum -= *old.Temperature
+				change.temperatureCount--
+			}
+		}
+
+		if newer(reading.TransparencyAt, old.TransparencyAt) {
+			change.transparencySum += float64(*reading.Transparency)
+			change.transparencyCount++
+			if old.Transparency != nil {
+				change.transparencySum -= float64(*old.Transparency)
+				change.transparencyCount--
+			}
+		}
+	}
+
+	return nil
+}
+
+func newer(at, stored *time.Time) bool {
+	return at != nil && (stored == nil || !at.Before(*stored))
+}
+
+// applyCacheChanges updates the incremental averages or drops the cached ones of the changed
+// groups and invalidates the region and species results depending on them.
+func (s *Storage) applyCacheChanges(ctx context.Context, changes *cacheChanges) {
+	if changes == nil {
+		return
+	}
+
+	pipe := s.redis.Pipeline()
+	for groupId, change := range changes.averages {
+		group, err := s.groupName(groupId)
+		if err != nil {
+			log.Printf("cannot invalidate averages of group %d: %s", groupId, err)
+			continue
+		}
+
+		if !s.cache.writeThrough {
+			pipe.Del(ctx, temperatureKey+group, transparencyKey+group)
+			continue
+		}
+
+		if change.temperatureSum != 0 || change.temperatureCount != 0 {
+			applyAverageDelta.Eval(ctx, pipe, []string{temperatureKey + averageStateKey + group}, change.temperatureSum, change.temperatureCount)
+		}
+		if change.transparencySum != 0 || change.transparencyCount != 0 {
+			applyAverageDelta.Eval(ctx, pipe, []string{transparencyKey + averageStateKey + group}, change.transparencySum, change.transparencyCount)
+		}
+	}
+
+	for groupId := range changes.species {
+		group, err := s.groupName(groupId)
+		if err != nil {
+			log.Printf("cannot invalidate species of group %d: %s", groupId, err)
+			continue
+		}
+
+		pipe.Incr(ctx, speciesGenerationKey+group)
+	}
+
+	if changes.regions {
+		pipe.Incr(ctx, regionGenerationKey)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		log.Printf("Error updating cache: %s", err)
+	}
+}
+
+// getIncrementalAvg returns the average from the sum and count kept up to date by the writes.
+func (s *Storage) getIncrementalAvg(ctx context.Context, group, key, field string) (float64, error) {
+	redisKey := key + averageStateKey + group
+
+	state, err := s.redis.HMGet(ctx, redisKey, sumField, countField).Result()
+	if err != nil {
+		log.Printf("Error getting value by key %s: %s", redisKey, err)
+	} else if avg, ok := averageFromState(state); ok {
+		return avg, nil
+	}
+
+	sum, count, err := s.getSumFromDb(group, field)
+	if err != nil {
+		return 0, err
+	}
+
+	if count == 0 {
+		return 0, fmt.Errorf("average %s for %s not found", field, group)
+	}
+
+	err = initAverageState.Run(ctx, s.redis, []string{redisKey}, sum, count, s.cache.averageTTL.Milliseconds()).Err()
+	if err != nil && err != redis.Nil {
+		log.Printf("Error setting value by key %s: %s", redisKey, err)
+	}
+
+	return sum / float64(count), nil
+}
+
+func averageFromState(state []interface{}) (float64, bool) {
+	if len(state) != 2 {
+		return 0, false
+	}
+
+	sumValue, ok := state[0].(string)
+	if !ok {
+		return 0, false
+	}
+	countValue, ok := state[1].(string)
+	if !ok {
+		return 0, false
+	}
+
+	sum, err := strconv.ParseFloat(sumValue, 64)
+	if err != nil {
+		return 0, false
+	}
+	count, err := strconv.ParseInt(countValue, 10, 64)
+	if err != nil || count <= 0 {
+		return 0, false
+	}
+
+	return sum / float64(count), true
+}
+
+// cached returns the result cached for the query into dest or loads and caches it. The key
+// includes the generation, so bumping the generation invalidates every result cached under it.
+func (s *Storage) cached(ctx context.Context, prefix, generationKey string, ttl time.Duration, query *gorm.DB, dest any, load func() error) error {
+	key, err := s.queryKey(ctx, prefix, generationKey, query)
+	if err != nil {
+		log.Printf("Error building cache key %s: %s", prefix, err)
+		return load()
+	}
+
+	res := s.redis.Get(ctx, key)
+	if res.Err() != nil && res.Err() != redis.Nil {
+		log.Printf("Error getting value by key %s: %s", key, res.Err())
+	} else if res.Err() == nil {
+		if err = json.Unmarshal([]byte(res.Val()), dest); err == nil {
+			return nil
+		}
+	}
+
+	if err = load(); err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(dest)
+	if err != nil {
+		return err
+	}
+
+	if err = s.redis.Set(ctx, key, value, ttl).Err(); err != nil {
+		log.Printf("Error setting value by key %s: %s", key, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) queryKey(ctx context.Context, prefix, generationKey string, query *gorm.DB) (string, error) {
+	generation, err := s.redis.Get(ctx, generationKey).Result()
+	if err == redis.Nil {
+		generation = "0"
+	} else if err != nil {
+		return "", err
+	}
+
+	stmt := query.Session(&gorm.Session{DryRun: true}).Find(&[]map[string]interface{}{}).Statement
+	if stmt.Error != nil {
+		return "", stmt.Error
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(stmt.SQL.String()))
+	h.Write([]byte(fmt.Sprint(stmt.Vars...)))
+
+	return prefix + ":" + generation + ":" + strconv.FormatUint(h.Sum64(), 16), nil
+}
+
+// groupName returns the name of the group, names never change so they are kept in memory.
+func (s *Storage) groupName(id uint) (string, error) {
+	if name, ok := s.groupNames.Load(id); ok {
+		return name.(string), nil
+	}
+
+	groups, err := s.GetAllGroups()
+	if err != nil {
+		return "", err
+	}
+
+	for _, group := range groups {
+		s.groupNames.Store(group.ID, group.Name)
+	}
+
+	name, ok := s.groupNames.Load(id)
+	if !ok {
+		return "", fmt.Errorf("group %d not found", id)
+	}
+
+	return name.(string), nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+type TemperatureUnit string
+
+const (
+	Celsius    TemperatureUnit = "celsius"
+	Fahrenheit TemperatureUnit = "fahrenheit"
+	Kelvin     TemperatureUnit = "kelvin"
+
+	defaultNoiseStd = 0.2
+)
+
+var ErrUnknownTemperatureUnit = errors.New("unknown temperature unit")
+
+func ParseTemperatureUnit(unit string) (TemperatureUnit, error) {
+	switch u := TemperatureUnit(unit); u {
+	case Celsius, Fahrenheit, Kelvin:
+		return u, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownTemperatureUnit, unit)
+}
+
+// FromCelsius converts the Celsius temperature to the unit.
+func (u TemperatureUnit) FromCelsius(t float64) float64 {
+	switch u {
+	case Fahrenheit:
+		return t*1.8 + 32
+	case Kelvin:
+		return t + 273.15
+	default:
+		return t
+	}
+}
+
+// ToCelsius converts the temperature in the unit to Celsius.
+func (u TemperatureUnit) ToCelsius(t float64) float64 {
+	switch u {
+	case Fahrenheit:
+		return (t - 32) / 1.8
+	case Kelvin:
+		return t - 273.15
+	default:
+		return t
+	}
+}
+
+// Degree is the size of a degree of the unit in Celsius degrees.
+func (u TemperatureUnit) Degree() float64 {
+	if u == Fahrenheit {
+		return 1 / 1.8
+	}
+
+	return 1
+}
+
+// Calibration describes how a sensor measures temperature: the reading is Gain times the true
+// temperature plus Offset and a normal noise with NoiseStd, rounded to Resolution. Offset,
+// NoiseStd and Resolution are in Unit, a zero Resolution does not round.
+type Calibration struct {
+	Offset     float64
+	Gain       float64 `gorm:"default:1"`
+	NoiseStd   float64
+	Resolution float64
+	Unit       TemperatureUnit `gorm:"default:celsius"`
+}
+
+// DefaultCalibration is the calibration of an ideal sensor with the usual measurement noise.
+func DefaultCalibration() Calibration {
+	return Calibration{Gain: 1, NoiseStd: defaultNoiseStd, Unit: Celsius}
+}
+
+// Measure returns the reading of the true Celsius temperature in the unit of the sensor, noise
+// is a standard normal sample scaled by NoiseStd.
+func (c *Calibration) Measure(t, noise float64) float64 {
+	gain := c.Gain
+	if gain == 0 {
+		gain = 1
+	}
+
+	v := gain*c.Unit.FromCelsius(t) + c.Offset + noise*c.NoiseStd
+	if c.Resolution > 0 {
+		v = math.Round(v/c.Resolution) * c.Resolution
+	}
+
+	return v
+}
+
+// MeasureCelsius returns the reading of the true Celsius temperature converted back to Celsius,
+// which is how the readings are stored.
+func (c *Calibration) MeasureCelsius(t, noise float64) float64 {
+	return c.Unit.ToCelsius(c.Measure(t, noise))
+}
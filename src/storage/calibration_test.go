@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemperatureUnits(t *testing.T) {
+	for _, unit := range []TemperatureUnit{Celsius, Fahrenheit, Kelvin} {
+		parsed, err := ParseTemperatureUnit(string(unit))
+		require.NoError(t, err)
+		assert.Equal(t, unit, parsed)
+
+		assert.InDelta(t, 21.5, unit.ToCelsius(unit.FromCelsius(21.5)), 1e-9, unit)
+	}
+
+	assert.InDelta(t, 212.0, Fahrenheit.FromCelsius(100), 1e-9)
+	assert.InDelta(t, 273.15, Kelvin.FromCelsius(0), 1e-9)
+
+	_, err := ParseTemperatureUnit("rankine")
+	assert.ErrorIs(t, err, ErrUnknownTemperatureUnit)
+}
+
+func TestCalibrationMeasure(t *testing.T) {
+	ideal := DefaultCalibration()
+	assert.Equal(t, 10.0, ideal.Measure(10, 0))
+	assert.InDelta(t, 10.4, ideal.Measure(10, 2), 1e-9, "the noise is scaled by the std")
+
+	c := Calibration{Offset: 0.3, Gain: 1.1, Resolution: 0.5, Unit: Celsius}
+	assert.InDelta(t, 11.5, c.Measure(10, 0), 1e-9, "1.1 * 10 + 0.3 is rounded to 0.5")
+
+	c = Calibration{Gain: 1, Resolution: 1, Unit: Fahrenheit}
+	assert.Equal(t, 70.0, c.Measure(21, 0), "69.8F is rounded to a whole degree")
+	assert.InDelta(t, 21.11, c.MeasureCelsius(21, 0), 0.01)
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Postgres error codes of the integrity constraint violations.
+const (
+	notNullViolation    = "23502"
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+	checkViolation      = "23514"
+)
+
+var (
+	ErrDuplicate        = errors.New("record already exists")
+	ErrMissingReference = errors.New("referenced record does not exist")
+	ErrInvalidData      = errors.New("invalid data")
+)
+
+// ConstraintError is a violation of a database constraint, Kind is one of ErrDuplicate,
+// ErrMissingReference and ErrInvalidData, so callers can match it with errors.Is.
+type ConstraintError struct {
+	Kind       error
+	Constraint string
+	Detail     string
+}
+
+func (e *ConstraintError) Error() string {
+	msg := e.Kind.Error()
+	if e.Constraint != "" {
+		msg += " (" + e.Constraint + ")"
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+
+	return msg
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Kind
+}
+
+// translateError turns constraint violations reported by postgres into a ConstraintError and
+// returns other errors as they are.
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	var kind error
+	switch pgErr.Code {
+	case uniqueViolation:
+		kind = ErrDuplicate
+	case foreignKeyViolation:
+		kind = ErrMissingReference
+	case notNullViolation, checkViolation:
+		kind = ErrInvalidData
+	default:
+		return err
+	}
+
+	detail := pgErr.Detail
+	if detail == "" {
+		detail = pgErr.Message
+	}
+
+	return &ConstraintError{Kind: kind, Constraint: pgErr.ConstraintName, Detail: detail}
+}
+
+// registerErrorTranslation translates the errors of every statement the storage runs, so the
+// constraint violations are reported the same way wherever they happen.
+func registerErrorTranslation(db *gorm.DB) error {
+	translate := func(tx *gorm.DB) {
+		if tx.Error != nil {
+			tx.Error = translateError(tx.Error)
+		}
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("storage:translate_error", translate); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("storage:translate_error", translate); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("storage:translate_error", translate); err != nil {
+		return err
+	}
+
+	return callbacks.Raw().After("gorm:raw").Register("storage:translate_error", translate)
+}
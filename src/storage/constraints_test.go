@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		code string
+		kind error
+	}{
+		{code: uniqueViolation, kind: ErrDuplicate},
+		{code: foreignKeyViolation, kind: ErrMissingReference},
+		{code: checkViolation, kind: ErrInvalidData},
+		{code: notNullViolation, kind: ErrInvalidData},
+	}
+
+	for _, test := range tests {
+		err := translateError(&pgconn.PgError{Code: test.code, ConstraintName: "c", Detail: "d"})
+		require.ErrorIs(t, err, test.kind)
+		assert.Equal(t, test.kind.Error()+" (c): d", err.Error())
+	}
+
+	other := &pgconn.PgError{Code: "42P01"}
+	assert.Same(t, other, translateError(other))
+
+	plain := errors.New("plain")
+	assert.Same(t, plain, translateError(plain))
+}
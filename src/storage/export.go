@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	TemperatureMetric  = "temperature"
+	TransparencyMetric = "transparency"
+	FishMetric         = "fish"
+
+	exportCursor    = "export_cursor"
+	exportFetchSize = 1000
+)
+
+// ExportFilter narrows down the exported records, zero fields are not applied.
+type ExportFilter struct {
+	SensorId     uint
+	Group        string
+	IndexInGroup *uint64
+
+	Region     []CoordinateOption
+	Conditions []ConditionOption
+}
+
+type ReadingRecord struct {
+	SensorId     uint64
+	GroupName    string
+	IndexInGroup uint64
+	Metric       string
+	Value        float64
+	// TrueValue is the value before the calibration errors, nil unless the reading was generated.
+	TrueValue *float64
+	// Labels are the comma separated kinds of the anomalies the reading is affected by.
+	Labels    string
+	CreatedAt time.Time
+}
+
+type LabelRecord struct {
+	SensorId     uint64
+	GroupName    string
+	IndexInGroup uint64
+	Kind         string
+	Metric       string
+	Magnitude    float64
+	Detail       string
+	CreatedAt    time.Time
+	EndedAt      time.Time
+}
+
+type FishRecord struct {
+	SensorId     uint64
+	GroupName    string
+	IndexInGroup uint64
+	Name         string
+	Count        uint64
+	CreatedAt    time.Time
+}
+
+type SensorRecord struct {
+	Id             uint64
+	GroupName      string
+	IndexInGroup   uint64
+	X, Y, Z        float64
+	DataOutputRate time.Duration
+	CreatedAt      time.Time
+
+	CalibrationOffset     float64
+	CalibrationGain       float64
+	CalibrationNoiseStd   float64
+	CalibrationResolution float64
+	CalibrationUnit       string
+}
+
+// StreamReadings passes temperature and transparency readings ordered by time to fn without loading them into memory.
+func (s *Storage) StreamReadings(ctx context.Context, filter *ExportFilter, fn func(*ReadingRecord) error) error {
+	temperatures := s.readingsQuery(TemperatureTable, TemperatureMetric, TemperatureTable+".true_temperature", filter)
+	transparencies := s.readingsQuery(TransparencyTable, TransparencyMetric, "NULL", filter)
+
+	query := s.db.Raw("? UNION ALL ? ORDER BY created_at", temperatures, transparencies)
+	return streamCursor(ctx, s.db, query, fn)
+}
+
+// StreamFishes passes fish observations ordered by time to fn without loading them into memory.
+func (s *Storage) StreamFishes(ctx context.Context, filter *ExportFilter, fn func(*FishRecord) error) error {
+	query := s.db.Table(FishTable).
+		Select(FishTable + ".sensor_id, " + GroupTable + ".name AS group_name, " + SensorTable + ".index_in_group, " +
+			FishTable + ".name, " + FishTable + ".count, " + FishTable + ".created_at").
+		Joins("JOIN " + SensorTable + " ON " + FishTable + ".sensor_id = " + SensorTable + ".id").
+		Joins("JOIN " + GroupTable + " ON " + SensorTable + ".group_id = " + GroupTable + ".id").
+		Order(FishTable + ".created_at")
+
+	filter.apply(FishTable, query)
+	return streamCursor(ctx, s.db, query, fn)
+}
+
+// StreamLabels passes anomaly labels ordered by the start of the anomaly to fn without loading
+// them into memory, the time range applies to the start.
+func (s *Storage) StreamLabels(ctx context.Context, filter *ExportFilter, fn func(*LabelRecord) error) error {
+	query := s.db.Table(AnomalyLabelTable).
+		Select(AnomalyLabelTable + ".sensor_id, " + GroupTable + ".name AS group_name, " + SensorTable + ".index_in_group, " +
+			AnomalyLabelTable + ".kind, " + AnomalyLabelTable + ".metric, " + AnomalyLabelTable + ".magnitude, " +
+			AnomalyLabelTable + ".detail, " + AnomalyLabelTable + ".created_at, " + AnomalyLabelTable + ".ended_at").
+		Joins("JOIN " + SensorTable + " ON " + AnomalyLabelTable + ".sensor_id = " + SensorTable + ".id").
+		Joins("JOIN " + GroupTable + " ON " + SensorTable + ".group_id = " + GroupTable + ".id").
+		Order(AnomalyLabelTable + ".created_at, " + AnomalyLabelTable + ".id")
+
+	filter.apply(AnomalyLabelTable, query)
+	return streamCursor(ctx, s.db, query, fn)
+}
+
+// StreamSensors passes sensors metadata to fn without loading it into memory.
+func (s *Storage) StreamSensors(ctx context.Context, filter *ExportFilter, fn func(*SensorRecord) error) error {
+	query := s.db.Table(SensorTable).
+		Select(SensorTable + ".id, " + GroupTable + ".name AS group_name, " + SensorTable + ".index_in_group, " +
+			SensorTable + ".x, " + SensorTable + ".y, " + SensorTable + ".z, " + SensorTable + ".data_output_rate, " + SensorTable + ".created_at, " +
+			SensorTable + ".calibration_offset, " + SensorTable + ".calibration_gain, " + SensorTable + ".calibration_noise_std, " +
+			SensorTable + ".calibration_resolution, " + SensorTable + ".calibration_unit").
+		Joins("JOIN " + GroupTable + " ON " + SensorTable + ".group_id = " + GroupTable + ".id").
+		Where(SensorTable + ".deleted_at IS NULL").
+		Order(SensorTable + ".id")
+
+	filter.apply(SensorTable, query)
+	return streamCursor(ctx, s.db, query, fn)
+}
+
+// readingsQuery selects the readings of the metric, trueValue is the column of the true values.
+// The labels of the anomalies which cover the time of a reading and its metric are aggregated.
+func (s *Storage) readingsQuery(table, metric, trueValue string, filter *ExportFilter) *gorm.DB {
+	labels := "(SELECT string_agg(DISTINCT l.kind, ',') FROM " + AnomalyLabelTable + " l WHERE l.sensor_id = " + table + ".sensor_id" +
+		" AND " + table + ".created_at BETWEEN l.created_at AND l.ended_at AND l.metric IN ('', ?))"
+
+	tx := s.db.Table(table).
+		Select(table+".id, "+table+".sensor_id, "+GroupTable+".name AS group_name, "+SensorTable+".index_in_group, "+
+			"? AS metric, "+table+"."+metric+"::double precision AS value, "+trueValue+"::double precision AS true_value, "+
+			"COALESCE("+labels+", '') AS labels, "+table+".created_at", metric, metric).
+		Joins("JOIN " + SensorTable + " ON " + table + ".sensor_id = " + SensorTable + ".id").
+		Joins("JOIN " + GroupTable + " ON " + SensorTable + ".group_id = " + GroupTable + ".id")
+
+	filter.apply(table, tx)
+	return tx
+}
+
+func (f *ExportFilter) apply(table string, tx *gorm.DB) {
+	if f == nil {
+		return
+	}
+
+	if f.SensorId != 0 {
+		tx.Where(SensorTable+".id = ?", f.SensorId)
+	}
+
+	if f.Group != "" {
+		tx.Where(GroupTable+".name = ?", f.Group)
+	}
+
+	if f.IndexInGroup != nil {
+		tx.Where(SensorTable+".index_in_group = ?", *f.IndexInGroup)
+	}
+
+	for _, opt := range f.Region {
+		opt(tx)
+	}
+
+	for _, opt := range f.Conditions {
+		opt(table, tx)
+	}
+}
+
+// streamCursor runs the query through a server side cursor and fetches it in batches,
+// so the result of any size is never held in memory at once.
+func streamCursor[T any](ctx context.Context, db *gorm.DB, query *gorm.DB, fn func(*T) error) error {
+	stmt := query.Session(&gorm.Session{DryRun: true}).Find(&[]*T{}).Statement
+	if stmt.Error != nil {
+		return stmt.Error
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		_, err := tx.Statement.ConnPool.ExecContext(ctx, "DECLARE "+exportCursor+" NO SCROLL CURSOR FOR "+stmt.SQL.String(), stmt.Vars...)
+		if err != nil {
+			return err
+		}
+
+		fetch := fmt.Sprintf("FETCH %d FROM %s", exportFetchSize, exportCursor)
+		for {
+			fetched, err := fetchCursor(ctx, tx, fetch, fn)
+			if err != nil {
+				return err
+			}
+
+			if fetched < exportFetchSize {
+				break
+			}
+		}
+
+		_, err = tx.Statement.ConnPool.ExecContext(ctx, "CLOSE "+exportCursor)
+		return err
+	})
+}
+
+func fetchCursor[T any](ctx context.Context, tx *gorm.DB, fetch string, fn func(*T) error) (int, error) {
+	rows, err := tx.Statement.ConnPool.QueryContext(ctx, fetch)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	fetched := 0
+	for rows.Next() {
+		record := new(T)
+		if err = tx.ScanRows(rows, record); err != nil {
+			return fetched, err
+		}
+
+		if err = fn(record); err != nil {
+			return fetched, err
+		}
+		fetched++
+	}
+
+	return fetched, rows.Err()
+}
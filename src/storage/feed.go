@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+const (
+	// readingGapTimeout is how long the feed looks up the skipped reading ids again. The ids are
+	// taken before the transactions commit, so a reading may become visible after the readings
+	// with higher ids, a gap which is not filled in time was rolled back.
+	readingGapTimeout = time.Minute
+	// maxReadingGaps limits the skipped ids a cursor keeps per metric, the ids over it are given up.
+	maxReadingGaps = 10000
+	// readingGapChunk is the number of skipped ids looked up by a query.
+	readingGapChunk = 1000
+)
+
+// ReadingCursor is the position in the feed of the readings of every metric.
+type ReadingCursor struct {
+	Temperature  MetricCursor
+	Transparency MetricCursor
+}
+
+// MetricCursor is the position in the readings of a metric: the id of the last reading read and
+// the lower ids which were not visible yet with the time they were skipped at.
+type MetricCursor struct {
+	Last uint
+	Gaps map[uint]time.Time
+}
+
+// FeedReading is a reading with its id in the table of its metric.
+type FeedReading struct {
+	ID uint
+	ReadingRecord
+}
+
+// GetReadingCursor returns the position after the readings saved so far.
+func (s *Storage) GetReadingCursor(ctx context.Context) (ReadingCursor, error) {
+	var last struct {
+		Temperature  uint
+		Transparency uint
+	}
+	res := s.db.WithContext(ctx).
+		Raw("SELECT (SELECT COALESCE(MAX(id), 0) FROM " + TemperatureTable + ") AS temperature, " +
+			"(SELECT COALESCE(MAX(id), 0) FROM " + TransparencyTable + ") AS transparency").
+		Scan(&last)
+
+	return ReadingCursor{Temperature: MetricCursor{Last: last.Temperature}, Transparency: MetricCursor{Last: last.Transparency}}, res.Error
+}
+
+// GetReadingsAfter returns the readings saved after the cursor and moves the cursor after them.
+// It returns the readings which filled the gaps of the cursor and at most limit newer readings
+// of every metric, the readings of a metric are in the order of their ids and are not ordered
+// by time. The cursor is moved after the returned readings also when an error is returned.
+func (s *Storage) GetReadingsAfter(ctx context.Context, cursor *ReadingCursor, limit int) ([]*FeedReading, error) {
+	temperatures, err := s.readingsAfter(ctx, TemperatureTable, TemperatureMetric, TemperatureTable+".true_temperature", &cursor.Temperature, limit)
+	if err != nil {
+		return temperatures, err
+	}
+
+	transparencies, err := s.readingsAfter(ctx, TransparencyTable, TransparencyMetric, "NULL", &cursor.Transparency, limit)
+	return append(temperatures, transparencies...), err
+}
+
+func (s *Storage) readingsAfter(ctx context.Context, table, metric, trueValue string, cursor *MetricCursor, limit int) ([]*FeedReading, error) {
+	readings, err := s.readingGaps(ctx, table, metric, trueValue, cursor)
+	if err != nil {
+		return readings, err
+	}
+
+	var after []*FeedReading
+	res := s.readingsQuery(table, metric, trueValue, nil).
+		WithContext(ctx).
+		Where(table+".id > ?", cursor.Last).
+		Order(table + ".id").
+		Limit(limit).
+		Scan(&after)
+	if res.Error != nil {
+		return readings, res.Error
+	}
+
+	now := time.Now()
+	for _, reading := range after {
+		for id := cursor.Last + 1; id < reading.ID && len(cursor.Gaps) < maxReadingGaps; id++ {
+			if cursor.Gaps == nil {
+				cursor.Gaps = make(map[uint]time.Time)
+			}
+			cursor.Gaps[id] = now
+		}
+		cursor.Last = reading.ID
+	}
+
+	for id, skipped := range cursor.Gaps {
+		if now.Sub(skipped) >= readingGapTimeout {
+			delete(cursor.Gaps, id)
+		}
+	}
+
+	return append(readings, after...), nil
+}
+
+// readingGaps returns the readings which became visible in the gaps of the cursor and removes
+// them from the gaps.
+func (s *Storage) readingGaps(ctx context.Context, table, metric, trueValue string, cursor *MetricCursor) ([]*FeedReading, error) {
+	ids := make([]uint, 0, len(cursor.Gaps))
+	for id := range cursor.Gaps {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var readings []*FeedReading
+	for len(ids) > 0 {
+		chunk := ids[:min(len(ids), readingGapChunk)]
+		ids = ids[len(chunk):]
+
+		var found []*FeedReading
+		res := s.readingsQuery(table, metric, trueValue, nil).
+			WithContext(ctx).
+			Where(table+".id IN ?", chunk).
+			Order(table + ".id").
+			Scan(&found)
+		if res.Error != nil {
+			return readings, res.Error
+		}
+
+		for _, reading := range found {
+			delete(cursor.Gaps, reading.ID)
+		}
+		readings = append(readings, found...)
+	}
+
+	return readings, nil
+}
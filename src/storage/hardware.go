@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	FullBattery = 100.0
+	// LowBattery is the battery level below which the measurements of a sensor degrade.
+	LowBattery = 20.0
+)
+
+type HardwareStatus string
+
+const (
+	HardwareOk         HardwareStatus = "ok"
+	HardwareLowBattery HardwareStatus = "low_battery"
+	HardwareDepleted   HardwareStatus = "depleted"
+	HardwareFailed     HardwareStatus = "failed"
+)
+
+// SensorHardware is the simulated hardware state of a sensor saved with its update.
+type SensorHardware struct {
+	SensorId     uint
+	Battery      float64
+	FailedAt     *time.Time
+	MaintainedAt *time.Time
+}
+
+// Hardware returns the hardware state of the sensor.
+func (s *Sensor) Hardware() *SensorHardware {
+	return &SensorHardware{SensorId: s.ID, Battery: s.Battery, FailedAt: s.FailedAt, MaintainedAt: s.MaintainedAt}
+}
+
+// HardwareStatus tells whether the sensor is able to report and how accurate it is.
+func (s *Sensor) HardwareStatus() HardwareStatus {
+	switch {
+	case s.FailedAt != nil:
+		return HardwareFailed
+	case s.Battery <= 0:
+		return HardwareDepleted
+	case s.Battery < LowBattery:
+		return HardwareLowBattery
+	default:
+		return HardwareOk
+	}
+}
+
+// updateHardware saves the hardware states, the last state of a sensor wins.
+func updateHardware(tx *gorm.DB, states map[uint]*SensorHardware) error {
+	if len(states) == 0 {
+		return nil
+	}
+
+	rows := make([]string, 0, insertBatchSize)
+	args := make([]any, 0, insertBatchSize*4)
+	flush := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+
+		err := tx.Exec("UPDATE "+SensorTable+" AS s SET battery = v.battery, failed_at = v.failed_at, maintained_at = v.maintained_at "+
+			"FROM (VALUES "+strings.Join(rows, ", ")+") AS v (id, battery, failed_at, maintained_at) WHERE s.id = v.id", args...).Error
+		rows, args = rows[:0], args[:0]
+
+		return err
+	}
+
+	for _, state := range states {
+		rows = append(rows, "(?::bigint, ?::double precision, ?::timestamptz, ?::timestamptz)")
+		args = append(args, state.SensorId, state.Battery, state.FailedAt, state.MaintainedAt)
+
+		if len(rows) == insertBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return flush()
+}
@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	SensorEventTable = "sensor_events"
+
+	defaultLateTolerance = 0.5
+	defaultOfflineAfter  = 3
+)
+
+type SensorStatus string
+
+const (
+	// StatusOnline sensors report within their data output rate.
+	StatusOnline SensorStatus = "online"
+	// StatusLate sensors missed a report but fewer than the offline threshold.
+	StatusLate SensorStatus = "late"
+	// StatusOffline sensors missed as many reports as the offline threshold or more.
+	StatusOffline SensorStatus = "offline"
+)
+
+// healthOptions decide when a quiet sensor is late or offline.
+type healthOptions struct {
+	// lateTolerance is the fraction of the data output rate a report may be delayed by.
+	lateTolerance float64
+	// offlineAfter is the number of missed reports a sensor is offline after.
+	offlineAfter int
+}
+
+// SensorHealth is the liveness of a sensor at a moment.
+type SensorHealth struct {
+	SensorId uint
+	GroupId  uint
+	// LastSeen is the time of the latest reading, nil if the sensor never reported.
+	LastSeen *time.Time
+	// Expected is the interval the sensor is expected to report with.
+	Expected time.Duration
+	// Missed is the number of reports expected since the sensor was last seen, or since it was
+	// created if it never reported.
+	Missed int
+	Status SensorStatus
+}
+
+// SensorEvent records a change of the health status of a sensor.
+type SensorEvent struct {
+	ID         uint `gorm:"primarykey"`
+	CreatedAt  time.Time
+	SensorId   uint
+	FromStatus SensorStatus
+	ToStatus   SensorStatus
+	Missed     int
+	LastSeenAt *time.Time
+}
+
+type GroupHealth struct {
+	Group   string
+	Online  int
+	Late    int
+	Offline int
+}
+
+// SensorHealth returns the health of the sensor at the moment, latest is nil if the sensor
+// never reported.
+func (s *Storage) SensorHealth(sensor *Sensor, latest *LatestReading, now time.Time) *SensorHealth {
+	return s.health.health(sensor, latest, now)
+}
+
+func (o *healthOptions) health(sensor *Sensor, latest *LatestReading, now time.Time) *SensorHealth {
+	h := &SensorHealth{
+		SensorId: sensor.ID,
+		GroupId:  uint(sensor.GroupId),
+		Expected: sensor.DataOutputRate,
+		Status:   StatusOnline,
+	}
+
+	since := sensor.CreatedAt
+	if latest != nil {
+		if at := latest.LastReadingAt(); !at.IsZero() {
+			h.LastSeen = &at
+			since = at
+		}
+	}
+
+	if h.Expected <= 0 {
+		return h
+	}
+
+	elapsed := now.Sub(since)
+	if elapsed <= time.Duration(float64(h.Expected)*(1+o.lateTolerance)) {
+		return h
+	}
+
+	h.Missed = int(elapsed / h.Expected)
+	if h.Missed >= o.offlineAfter {
+		h.Status = StatusOffline
+	} else {
+		h.Status = StatusLate
+	}
+
+	return h
+}
+
+// GetSensorHealth returns the health of the sensors at the moment by sensor id.
+func (s *Storage) GetSensorHealth(sensors []*Sensor, now time.Time) (map[uint]*SensorHealth, error) {
+	ids := make([]uint, len(sensors))
+	for i, sensor := range sensors {
+		ids[i] = sensor.ID
+	}
+
+	latest, err := s.GetLatestReadings(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	health := make(map[uint]*SensorHealth, len(sensors))
+	for _, sensor := range sensors {
+		health[sensor.ID] = s.SensorHealth(sensor, latest[sensor.ID], now)
+	}
+
+	return health, nil
+}
+
+// GetGroupsHealth counts the sensors of every group by status, the groups are ordered by name.
+func (s *Storage) GetGroupsHealth(now time.Time) ([]*GroupHealth, error) {
+	groups, err := s.GetAllGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	sensors, err := s.GetAllSensors()
+	if err != nil {
+		return nil, err
+	}
+
+	health, err := s.GetSensorHealth(sensors, now)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[uint]*GroupHealth, len(groups))
+	result := make([]*GroupHealth, 0, len(groups))
+	for _, group := range groups {
+		g := &GroupHealth{Group: group.Name}
+		byId[group.ID] = g
+		result = append(result, g)
+	}
+
+	for _, h := range health {
+		g, ok := byId[h.GroupId]
+		if !ok {
+			continue
+		}
+
+		switch h.Status {
+		case StatusOnline:
+			g.Online++
+		case StatusLate:
+			g.Late++
+		case StatusOffline:
+			g.Offline++
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Group < result[j].Group
+	})
+
+	return result, nil
+}
+
+// RecordHealthTransitions compares the health of every sensor with its recorded status and
+// records the changes as events, sensors without events are recorded as online. It returns the
+// recorded events and has to run on a single instance at a time, with the context of a
+// leadership nothing is recorded once it is lost.
+func (s *Storage) RecordHealthTransitions(ctx context.Context, now time.Time) ([]*SensorEvent, error) {
+	sensors, err := s.GetAllSensors()
+	if err != nil {
+		return nil, err
+	}
+
+	health, err := s.GetSensorHealth(sensors, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var recorded []*SensorEvent
+	res := s.db.WithContext(ctx).Raw("SELECT DISTINCT ON (sensor_id) * FROM " + SensorEventTable + " ORDER BY sensor_id, created_at DESC, id DESC").
+		Scan(&recorded)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	statuses := make(map[uint]SensorStatus, len(recorded))
+	for _, event := range recorded {
+		statuses[event.SensorId] = event.ToStatus
+	}
+
+	var events []*SensorEvent
+	for _, sensor := range sensors {
+		h := health[sensor.ID]
+
+		from, ok := statuses[sensor.ID]
+		if !ok {
+			from = StatusOnline
+		}
+		if from == h.Status {
+			continue
+		}
+
+		events = append(events, &SensorEvent{
+			CreatedAt:  now,
+			SensorId:   sensor.ID,
+			FromStatus: from,
+			ToStatus:   h.Status,
+			Missed:     h.Missed,
+			LastSeenAt: h.LastSeen,
+		})
+	}
+
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if fence := leaderFenceOf(ctx); fence != nil {
+			if err := fence.check(tx); err != nil {
+				return err
+			}
+		}
+
+		return tx.CreateInBatches(events, insertBatchSize).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// GetSensorEvents returns the status transitions of the sensor ordered by time.
+func (s *Storage) GetSensorEvents(sensorId uint, opts ...ConditionOption) ([]*SensorEvent, error) {
+	tx := s.db.Table(SensorEventTable).Where(SensorEventTable+".sensor_id = ?", sensorId)
+	for _, opt := range opts {
+		opt(SensorEventTable, tx)
+	}
+
+	var events []*SensorEvent
+	if err := tx.Order(SensorEventTable + ".created_at, " + SensorEventTable + ".id").Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestHealth(t *testing.T) {
+	now := time.Now()
+	options := &healthOptions{lateTolerance: 0.5, offlineAfter: 3}
+	sensor := &Sensor{Model: gorm.Model{ID: 1, CreatedAt: now.Add(-time.Hour)}, GroupId: 2, DataOutputRate: time.Minute}
+
+	reported := func(ago time.Duration) *LatestReading {
+		at := now.Add(-ago)
+		return &LatestReading{SensorId: 1, TemperatureAt: &at}
+	}
+
+	tests := []struct {
+		name    string
+		latest  *LatestReading
+		status  SensorStatus
+		missed  int
+		hasSeen bool
+	}{
+		{name: "InTime", latest: reported(30 * time.Second), status: StatusOnline, hasSeen: true},
+		{name: "WithinTolerance", latest: reported(80 * time.Second), status: StatusOnline, hasSeen: true},
+		{name: "Late", latest: reported(150 * time.Second), status: StatusLate, missed: 2, hasSeen: true},
+		{name: "Offline", latest: reported(200 * time.Second), status: StatusOffline, missed: 3, hasSeen: true},
+		{name: "NeverReported", status: StatusOffline, missed: 60},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := options.health(sensor, test.latest, now)
+			assert.Equal(t, test.status, h.Status)
+			assert.Equal(t, test.missed, h.Missed)
+			assert.Equal(t, time.Minute, h.Expected)
+			assert.Equal(t, uint(2), h.GroupId)
+			assert.Equal(t, test.hasSeen, h.LastSeen != nil)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBadCodeName = errors.New("invalid sensor code name")
+
+	// codeNamePattern matches a group name followed by the index of the sensor in the group,
+	// indexes have no leading zeros, so every sensor has a single code name.
+	codeNamePattern = regexp.MustCompile("^([a-zA-Z]+)(0|[1-9][0-9]*)$")
+)
+
+// CodeName is the name of the sensor with the index in the group, like alpha3.
+func CodeName(group string, index uint64) string {
+	return group + strconv.FormatUint(index, 10)
+}
+
+// ParseCodeName splits the code name into the group name and the index of the sensor in the group.
+func ParseCodeName(codeName string) (string, uint64, error) {
+	matches := codeNamePattern.FindStringSubmatch(codeName)
+	if matches == nil {
+		return "", 0, fmt.Errorf("%w: %q", ErrBadCodeName, codeName)
+	}
+
+	index, err := strconv.ParseUint(matches[2], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %q", ErrBadCodeName, codeName)
+	}
+
+	return matches[1], index, nil
+}
+
+// BeforeCreate gives the sensor a UUID and the code name of its group and index unless the
+// sensor has them already.
+func (s *Sensor) BeforeCreate(tx *gorm.DB) error {
+	if s.UUID == uuid.Nil {
+		s.UUID = uuid.New()
+	}
+
+	if s.CodeName != "" {
+		return nil
+	}
+
+	var group Group
+	res := tx.Session(&gorm.Session{NewDB: true}).Where("id = ?", s.GroupId).Limit(1).Find(&group)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: group %d", ErrMissingReference, s.GroupId)
+	}
+
+	s.CodeName = CodeName(group.Name, s.IndexInGroup)
+	return nil
+}
+
+// GetSensor returns the sensor given by the code name or the UUID.
+func (s *Storage) GetSensor(ref string) (*Sensor, error) {
+	if id, err := uuid.Parse(ref); err == nil {
+		return s.GetSensorByUUID(id)
+	}
+
+	return s.GetSensorByCodeName(ref)
+}
+
+func (s *Storage) GetSensorByCodeName(codeName string) (*Sensor, error) {
+	if _, _, err := ParseCodeName(codeName); err != nil {
+		return nil, err
+	}
+
+	return s.findSensor(codeName, "code_name = ?", codeName)
+}
+
+func (s *Storage) GetSensorByUUID(id uuid.UUID) (*Sensor, error) {
+	return s.findSensor(id.String(), "uuid = ?", id)
+}
+
+func (s *Storage) GetSensorByIndex(group string, index uint64) (*Sensor, error) {
+	return s.findSensor(CodeName(group, index),
+		"group_id = (SELECT id FROM "+GroupTable+" WHERE name = ? AND deleted_at IS NULL) AND index_in_group = ?", group, index)
+}
+
+func (s *Storage) findSensor(ref string, query string, args ...any) (*Sensor, error) {
+	var sensor Sensor
+	res := s.db.Where(query, args...).Limit(1).Find(&sensor)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSensor, ref)
+	}
+
+	return &sensor, nil
+}
@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCodeName(t *testing.T) {
+	group, index, err := ParseCodeName("alpha0")
+	require.NoError(t, err)
+	assert.Equal(t, "alpha", group)
+	assert.Equal(t, uint64(0), index)
+
+	group, index, err = ParseCodeName(CodeName("beta", 12))
+	require.NoError(t, err)
+	assert.Equal(t, "beta", group)
+	assert.Equal(t, uint64(12), index)
+
+	for _, codeName := range []string{"", "alpha", "12", "alpha03", "alpha-1", "alpha3x", "alpha99999999999999999999"} {
+		_, _, err = ParseCodeName(codeName)
+		assert.ErrorIs(t, err, ErrBadCodeName, codeName)
+	}
+}
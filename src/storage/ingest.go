@@ -0,0 +1,300 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MinValidTemperature  = -273.15
+	MaxValidTemperature  = 100.0
+	MaxValidTransparency = 100.0
+
+	allowedClockSkew = time.Minute
+)
+
+var (
+	ErrSensorNotFound = errors.New("sensor not found")
+	ErrBadReading     = errors.New("bad reading")
+)
+
+// Reading is a set of values measured by an external sensor at one moment. The sensor is
+// identified by its id or code name, values which were not measured are nil.
+type Reading struct {
+	SensorId  uint64
+	CodeName  string
+	Timestamp time.Time
+
+	Temperature  *float64
+	Transparency *float64
+	Fishes       map[string]uint64
+}
+
+type IngestError struct {
+	Index int
+	Err   error
+}
+
+type IngestResult struct {
+	Accepted   int
+	Duplicates int
+	Rejected   []*IngestError
+}
+
+// IngestReadings validates the readings and saves them as if they were reported by the sensors.
+// Readings repeating an already saved (sensor, timestamp) pair are skipped as duplicates.
+func (s *Storage) IngestReadings(readings []*Reading) (*IngestResult, error) {
+	result := &IngestResult{}
+
+	sensors, err := s.identifySensors(readings)
+	if err != nil {
+		return nil, err
+	}
+
+	valid := make([]*sensorReading, 0, len(readings))
+	seen := make(map[readingKey]struct{}, len(readings))
+	for i, reading := range readings {
+		sensor, err := reading.validate(sensors)
+		if err != nil {
+			result.Rejected = append(result.Rejected, &IngestError{Index: i, Err: err})
+			continue
+		}
+
+		key := readingKey{sensorId: sensor.ID, timestamp: reading.Timestamp.UTC().Truncate(time.Microsecond)}
+		if _, ok := seen[key]; ok {
+			result.Duplicates++
+			continue
+		}
+		seen[key] = struct{}{}
+
+		valid = append(valid, &sensorReading{key: key, sensor: sensor, reading: reading})
+	}
+
+	if len(valid) == 0 {
+		return result, nil
+	}
+
+	changes := s.newCacheChanges()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		saved, err := savedReadings(tx, valid)
+		if err != nil {
+			return err
+		}
+
+		sort.SliceStable(valid, func(i, j int) bool {
+			return valid[i].key.timestamp.Before(valid[j].key.timestamp)
+		})
+
+		for _, r := range valid {
+			if _, ok := saved[r.key]; ok {
+				result.Duplicates++
+				continue
+			}
+
+			if err := writeSensorData(tx, r.sensor, r.fishes(), r.temperature(), r.transparency(), changes); err != nil {
+				return err
+			}
+			result.Accepted++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyCacheChanges(context.Background(), changes)
+	return result, nil
+}
+
+type readingKey struct {
+	sensorId  uint
+	timestamp time.Time
+}
+
+type sensorReading struct {
+	key     readingKey
+	sensor  *Sensor
+	reading *Reading
+}
+
+func (r *sensorReading) model() gorm.Model {
+	return gorm.Model{CreatedAt: r.key.timestamp, UpdatedAt: r.key.timestamp}
+}
+
+func (r *sensorReading) temperature() *Temperature {
+	if r.reading.Temperature == nil {
+		return nil
+	}
+
+	return &Temperature{
+		Model:       r.model(),
+		SensorId:    uint64(r.sensor.ID),
+		Temperature: *r.reading.Temperature,
+	}
+}
+
+func (r *sensorReading) transparency() *Transparency {
+	if r.reading.Transparency == nil {
+		return nil
+	}
+
+	return &Transparency{
+		Model:        r.model(),
+		SensorId:     uint64(r.sensor.ID),
+		Transparency: uint8(*r.reading.Transparency + 0.5),
+	}
+}
+
+func (r *sensorReading) fishes() []*Fish {
+	if r.reading.Fishes == nil {
+		return nil
+	}
+
+	fishes := make([]*Fish, 0, len(r.reading.Fishes))
+	for name, count := range r.reading.Fishes {
+		fishes = append(fishes, &Fish{
+			Model:    r.model(),
+			SensorId: uint64(r.sensor.ID),
+			Name:     name,
+			Count:    count,
+		})
+	}
+
+	return fishes
+}
+
+func (r *Reading) validate(sensors *sensorIdentities) (*Sensor, error) {
+	if r.SensorId == 0 && r.CodeName == "" {
+		return nil, fmt.Errorf("%w: sensor id or code name is required", ErrBadReading)
+	}
+
+	var sensor *Sensor
+	if r.SensorId != 0 {
+		sensor = sensors.byId[uint(r.SensorId)]
+		if sensor == nil {
+			return nil, fmt.Errorf("%w: %d", ErrSensorNotFound, r.SensorId)
+		}
+	}
+
+	if r.CodeName != "" {
+		named := sensors.byCodeName[r.CodeName]
+		if named == nil {
+			return nil, fmt.Errorf("%w: %s", ErrSensorNotFound, r.CodeName)
+		}
+
+		if sensor != nil && sensor != named {
+			return nil, fmt.Errorf("%w: sensor %d is not %s", ErrBadReading, r.SensorId, r.CodeName)
+		}
+		sensor = named
+	}
+
+	if r.Timestamp.IsZero() {
+		return nil, fmt.Errorf("%w: timestamp is required", ErrBadReading)
+	}
+
+	if r.Timestamp.After(time.Now().Add(allowedClockSkew)) {
+		return nil, fmt.Errorf("%w: timestamp %s is in the future", ErrBadReading, r.Timestamp)
+	}
+
+	if r.Temperature == nil && r.Transparency == nil && r.Fishes == nil {
+		return nil, fmt.Errorf("%w: no values", ErrBadReading)
+	}
+
+	if r.Temperature != nil && (*r.Temperature < MinValidTemperature || *r.Temperature > MaxValidTemperature) {
+		return nil, fmt.Errorf("%w: temperature %f is out of range", ErrBadReading, *r.Temperature)
+	}
+
+	if r.Transparency != nil && (*r.Transparency < 0 || *r.Transparency > MaxValidTransparency) {
+		return nil, fmt.Errorf("%w: transparency %f is out of range", ErrBadReading, *r.Transparency)
+	}
+
+	for name := range r.Fishes {
+		if name == "" {
+			return nil, fmt.Errorf("%w: fish name is required", ErrBadReading)
+		}
+	}
+
+	return sensor, nil
+}
+
+type sensorIdentities struct {
+	byId       map[uint]*Sensor
+	byCodeName map[string]*Sensor
+}
+
+// identifySensors loads the sensors the readings refer to by id or by code name.
+func (s *Storage) identifySensors(readings []*Reading) (*sensorIdentities, error) {
+	var sensors []*Sensor
+	for i := 0; i < len(readings); i += insertBatchSize {
+		batch := readings[i:min(i+insertBatchSize, len(readings))]
+		ids := make([]uint64, 0, len(batch))
+		codeNames := make([]string, 0)
+		for _, reading := range batch {
+			if reading.SensorId != 0 {
+				ids = append(ids, reading.SensorId)
+			}
+
+			if reading.CodeName != "" {
+				codeNames = append(codeNames, reading.CodeName)
+			}
+		}
+
+		var found []*Sensor
+		res := s.db.Where("id IN ? OR code_name IN ?", append(ids, 0), append(codeNames, "")).Find(&found)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		sensors = append(sensors, found...)
+	}
+
+	identities := &sensorIdentities{
+		byId:       make(map[uint]*Sensor, len(sensors)),
+		byCodeName: make(map[string]*Sensor, len(sensors)),
+	}
+	for _, sensor := range sensors {
+		identities.byId[sensor.ID] = sensor
+		identities.byCodeName[sensor.CodeName] = sensor
+	}
+
+	return identities, nil
+}
+
+// savedReadings returns which of the readings are already saved in any of the readings tables.
+func savedReadings(tx *gorm.DB, readings []*sensorReading) (map[readingKey]struct{}, error) {
+	type savedReading struct {
+		SensorId  uint
+		CreatedAt time.Time
+	}
+
+	query := "SELECT sensor_id, created_at FROM %s WHERE (sensor_id, created_at) IN ?"
+	query = fmt.Sprintf(query+" UNION "+query+" UNION "+query, TemperatureTable, TransparencyTable, FishTable)
+
+	var saved []savedReading
+	for i := 0; i < len(readings); i += insertBatchSize {
+		batch := readings[i:min(i+insertBatchSize, len(readings))]
+		keys := make([][]interface{}, 0, len(batch))
+		for _, r := range batch {
+			keys = append(keys, []interface{}{r.key.sensorId, r.key.timestamp})
+		}
+
+		var found []savedReading
+		res := tx.Raw(query, keys, keys, keys).Scan(&found)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		saved = append(saved, found...)
+	}
+
+	result := make(map[readingKey]struct{}, len(saved))
+	for _, s := range saved {
+		result[readingKey{sensorId: s.SensorId, timestamp: s.CreatedAt.UTC()}] = struct{}{}
+	}
+
+	return result, nil
+}
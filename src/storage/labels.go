@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const AnomalyLabelTable = "anomaly_labels"
+
+type AnomalyKind string
+
+const (
+	// AnomalySpike is a single reading far off the true value.
+	AnomalySpike AnomalyKind = "spike"
+	// AnomalyDrift is a growing offset of the readings until the sensor is recalibrated.
+	AnomalyDrift AnomalyKind = "drift"
+	// AnomalyDropout is a period the sensor does not report in.
+	AnomalyDropout AnomalyKind = "dropout"
+	// AnomalyScenarioEvent is a change of the environment around the sensor, like a warm and
+	// turbid water intrusion.
+	AnomalyScenarioEvent AnomalyKind = "scenario_event"
+	// AnomalyPopulationShock is a sudden die-off of the fish around the sensor.
+	AnomalyPopulationShock AnomalyKind = "population_shock"
+	// AnomalyHardwareFailure is a failure of the sensor, it does not report until it is repaired.
+	AnomalyHardwareFailure AnomalyKind = "hardware_failure"
+	// AnomalyBatteryDepleted is a flat battery, the sensor does not report until it is replaced.
+	AnomalyBatteryDepleted AnomalyKind = "battery_depleted"
+)
+
+var ErrUnknownAnomalyKind = errors.New("unknown anomaly kind")
+
+func ParseAnomalyKind(kind string) (AnomalyKind, error) {
+	switch k := AnomalyKind(kind); k {
+	case AnomalySpike, AnomalyDrift, AnomalyDropout, AnomalyScenarioEvent, AnomalyPopulationShock,
+		AnomalyHardwareFailure, AnomalyBatteryDepleted:
+		return k, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownAnomalyKind, kind)
+}
+
+// AnomalyLabel is the ground truth of an anomaly the generator produced. CreatedAt is the start
+// of the anomaly, it is equal to EndedAt for anomalies of a single reading. An empty Metric
+// means every metric of the sensor is affected.
+type AnomalyLabel struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	EndedAt   time.Time
+	SensorId  uint
+	Kind      AnomalyKind
+	Metric    string
+	// Magnitude is the size of the anomaly: the offset of a spike or the final offset of a drift
+	// in Celsius degrees, the number of missed reports of a dropout, the temperature change in
+	// the middle of a scenario event and the fraction of the fish lost in a population shock.
+	// The hardware labels have no magnitude.
+	Magnitude float64
+	Detail    string
+
+	// SensorCodeName is read with the labels, it is not saved.
+	SensorCodeName string `gorm:"->"`
+}
+
+// LabelQueryOption narrows down the labels returned by GetAnomalyLabels.
+type LabelQueryOption func(q *labelQuery)
+
+type labelQuery struct {
+	sensorId   uint
+	kind       AnomalyKind
+	from, till time.Time
+}
+
+func WithLabelSensor(sensorId uint) LabelQueryOption {
+	return func(q *labelQuery) {
+		if sensorId != 0 {
+			q.sensorId = sensorId
+		}
+	}
+}
+
+func WithLabelKind(kind AnomalyKind) LabelQueryOption {
+	return func(q *labelQuery) {
+		if kind != "" {
+			q.kind = kind
+		}
+	}
+}
+
+// WithLabelsBetween keeps the labels of anomalies overlapping the period, a zero time leaves
+// the period open.
+func WithLabelsBetween(from, till time.Time) LabelQueryOption {
+	return func(q *labelQuery) {
+		q.from, q.till = from, till
+	}
+}
+
+// GetAnomalyLabels returns the labels ordered by the start of the anomaly, labels of removed
+// sensors are kept.
+func (s *Storage) GetAnomalyLabels(opts ...LabelQueryOption) ([]*AnomalyLabel, error) {
+	q := &labelQuery{}
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	tx := s.db.Table(AnomalyLabelTable).
+		Select(AnomalyLabelTable + ".*, " + SensorTable + ".code_name AS sensor_code_name").
+		Joins("JOIN " + SensorTable + " ON " + AnomalyLabelTable + ".sensor_id = " + SensorTable + ".id")
+	if q.sensorId != 0 {
+		tx = tx.Where(AnomalyLabelTable+".sensor_id = ?", q.sensorId)
+	}
+	if q.kind != "" {
+		tx = tx.Where(AnomalyLabelTable+".kind = ?", q.kind)
+	}
+	if !q.from.IsZero() {
+		tx = tx.Where(AnomalyLabelTable+".ended_at >= ?", q.from)
+	}
+	if !q.till.IsZero() {
+		tx = tx.Where(AnomalyLabelTable+".created_at <= ?", q.till)
+	}
+
+	var labels []*AnomalyLabel
+	if err := tx.Order(AnomalyLabelTable + ".created_at, " + AnomalyLabelTable + ".id").Find(&labels).Error; err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
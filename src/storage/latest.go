@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetLatestReadings returns the latest readings of the sensors by sensor id, sensors which never
+// reported are missing.
+func (s *Storage) GetLatestReadings(sensorIds []uint) (map[uint]*LatestReading, error) {
+	readings := make(map[uint]*LatestReading, len(sensorIds))
+	if len(sensorIds) == 0 {
+		return readings, nil
+	}
+
+	var found []*LatestReading
+	res := s.db.Where("sensor_id IN ?", sensorIds).Find(&found)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	for _, reading := range found {
+		readings[reading.SensorId] = reading
+	}
+
+	return readings, nil
+}
+
+// LastReadingAt is the time of the latest value the sensor reported.
+func (r *LatestReading) LastReadingAt() time.Time {
+	var at time.Time
+	if r.TemperatureAt != nil {
+		at = *r.TemperatureAt
+	}
+	if r.TransparencyAt != nil && r.TransparencyAt.After(at) {
+		at = *r.TransparencyAt
+	}
+
+	return at
+}
+
+// latestReading builds the latest reading of the sensor from the saved values, nil values
+// are left unset so the stored ones are kept.
+func latestReading(sensor *Sensor, temperature *Temperature, transparency *Transparency) *LatestReading {
+	reading := &LatestReading{
+		SensorId: sensor.ID,
+		GroupId:  uint(sensor.GroupId),
+	}
+	reading.merge(temperature, transparency)
+
+	return reading
+}
+
+// merge takes the values which are newer than the ones the reading already has.
+func (r *LatestReading) merge(temperature *Temperature, transparency *Transparency) {
+	if temperature != nil && newer(&temperature.CreatedAt, r.TemperatureAt) {
+		id, value, at := temperature.ID, temperature.Temperature, temperature.CreatedAt
+		r.TemperatureId, r.Temperature, r.TemperatureAt = &id, &value, &at
+	}
+
+	if transparency != nil && newer(&transparency.CreatedAt, r.TransparencyAt) {
+		id, value, at := transparency.ID, transparency.Transparency, transparency.CreatedAt
+		r.TransparencyId, r.Transparency, r.TransparencyAt = &id, &value, &at
+	}
+}
+
+// upsertLatestReadings inserts the readings of new sensors and updates the stored ones. A value
+// replaces the stored one only if it is not older, so late readings never roll the state back.
+func upsertLatestReadings(tx *gorm.DB, readings []*LatestReading, changes *cacheChanges) error {
+	if len(readings) == 0 {
+		return nil
+	}
+
+	if err := changes.readingsChanged(tx, readings); err != nil {
+		return err
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "sensor_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"group_id":        gorm.Expr("EXCLUDED.group_id"),
+			"temperature_id":  newerValue("temperature_id", "temperature_at"),
+			"temperature":     newerValue("temperature", "temperature_at"),
+			"temperature_at":  newerValue("temperature_at", "temperature_at"),
+			"transparency_id": newerValue("transparency_id", "transparency_at"),
+			"transparency":    newerValue("transparency", "transparency_at"),
+			"transparency_at": newerValue("transparency_at", "transparency_at"),
+			"updated_at":      gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	}).CreateInBatches(readings, insertBatchSize).Error
+}
+
+func newerValue(column, at string) clause.Expr {
+	stored := LatestReadingTable + "." + at
+	return gorm.Expr(
+		"CASE WHEN EXCLUDED." + at + " IS NOT NULL AND (" + stored + " IS NULL OR EXCLUDED." + at + " >= " + stored + ")" +
+			" THEN EXCLUDED." + column + " ELSE " + LatestReadingTable + "." + column + " END",
+	)
+}
+
+// fishObservation is the set of fish a sensor saw at one moment.
+type fishObservation struct {
+	sensor *Sensor
+	fishes []*Fish
+	at     time.Time
+}
+
+// observedAt is the time the fish were seen, the time of the saved fish or of the values
+// reported with them.
+func observedAt(fishes []*Fish, temperature *Temperature, transparency *Transparency) time.Time {
+	switch {
+	case len(fishes) > 0 && !fishes[0].CreatedAt.IsZero():
+		return fishes[0].CreatedAt
+	case temperature != nil && !temperature.CreatedAt.IsZero():
+		return temperature.CreatedAt
+	case transparency != nil && !transparency.CreatedAt.IsZero():
+		return transparency.CreatedAt
+	default:
+		return time.Now()
+	}
+}
+
+// replaceCurrentFishes makes the observed fish current. Like the latest values, fish replace the
+// current ones only if they are not older, so late readings never roll the state back.
+func replaceCurrentFishes(tx *gorm.DB, observations map[uint]*fishObservation) error {
+	rows := make([][]interface{}, 0, len(observations))
+	now := time.Now()
+	for _, o := range observations {
+		rows = append(rows, []interface{}{o.sensor.ID, o.sensor.GroupId, o.at, now})
+	}
+	// Rows are locked in the order of the sensors, so concurrent writers cannot deadlock.
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0].(uint) < rows[j][0].(uint) })
+
+	replaced := make([]uint, 0, len(rows))
+	for i := 0; i < len(rows); i += insertBatchSize {
+		batch := make([]interface{}, 0, insertBatchSize)
+		for _, row := range rows[i:min(i+insertBatchSize, len(rows))] {
+			batch = append(batch, row)
+		}
+
+		var ids []uint
+		res := tx.Raw(
+			"INSERT INTO "+LatestReadingTable+" (sensor_id, group_id, fishes_at, updated_at)"+
+				" VALUES "+strings.TrimSuffix(strings.Repeat("?,", len(batch)), ",")+
+				" ON CONFLICT (sensor_id) DO UPDATE SET fishes_at = EXCLUDED.fishes_at, updated_at = EXCLUDED.updated_at"+
+				" WHERE "+LatestReadingTable+".fishes_at IS NULL OR EXCLUDED.fishes_at >= "+LatestReadingTable+".fishes_at"+
+				" RETURNING sensor_id",
+			batch...,
+		).Scan(&ids)
+		if res.Error != nil {
+			return translateError(res.Error)
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		if err := tx.Exec("DELETE FROM "+CurrentSensorFishTable+" WHERE sensor_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		replaced = append(replaced, ids...)
+	}
+
+	csfs := make([]*CurrentSensorFish, 0, len(replaced)*10)
+	for _, id := range replaced {
+		for _, fish := range observations[id].fishes {
+			csfs = append(csfs, &CurrentSensorFish{SensorId: id, FishId: fish.ID})
+		}
+	}
+
+	return createInBatches(tx, csfs)
+}
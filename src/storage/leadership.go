@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultLeadershipCheckInterval = 5 * time.Second
+	defaultLeadershipRetryInterval = 5 * time.Second
+)
+
+var ErrNotLeader = errors.New("leadership is lost")
+
+// Leadership elects a single instance among the ones sharing the database. The leader is the
+// instance whose database session holds the advisory lock, Postgres releases the lock once the
+// session ends, so another instance takes over after the leader dies.
+type Leadership struct {
+	storage *Storage
+	name    string
+	key     int64
+
+	checkInterval time.Duration
+	retryInterval time.Duration
+}
+
+type LeadershipOption func(l *Leadership)
+
+// WithLeadershipCheckInterval sets how often the leader checks it still holds the lock.
+func WithLeadershipCheckInterval(interval time.Duration) LeadershipOption {
+	return func(l *Leadership) {
+		if interval > 0 {
+			l.checkInterval = interval
+		}
+	}
+}
+
+// WithLeadershipRetryInterval sets how often other instances try to take the leadership.
+func WithLeadershipRetryInterval(interval time.Duration) LeadershipOption {
+	return func(l *Leadership) {
+		if interval > 0 {
+			l.retryInterval = interval
+		}
+	}
+}
+
+func (s *Storage) NewLeadership(name string, opts ...LeadershipOption) *Leadership {
+	l := &Leadership{
+		storage:       s,
+		name:          name,
+		key:           lockKey(name),
+		checkInterval: defaultLeadershipCheckInterval,
+		retryInterval: defaultLeadershipRetryInterval,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Run blocks until ctx is done and calls lead every time this instance becomes the leader.
+// The context passed to lead is cancelled when the leadership is lost, Run waits for lead
+// to return before trying to take the leadership again. The loss is noticed within the check
+// interval, writes made with the context passed to lead check the lock themselves, so a leader
+// which lost its session never writes after another instance took over.
+func (l *Leadership) Run(ctx context.Context, lead func(ctx context.Context)) {
+	for {
+		if err := l.term(ctx, lead); err != nil && ctx.Err() == nil {
+			log.Printf("lost %s leadership: %s\n", l.name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(l.retryInterval):
+		}
+	}
+}
+
+// term takes the lock if it is free and leads until the lock session fails, lead returns or ctx is done.
+func (l *Leadership) term(ctx context.Context, lead func(ctx context.Context)) error {
+	db, err := l.storage.db.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var acquired bool
+	fence := &leaderFence{key: l.key}
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1), pg_backend_pid()", l.key).Scan(&acquired, &fence.pid)
+	if err != nil || !acquired {
+		return err
+	}
+	defer unlock(conn, l.key)
+
+	log.Printf("took %s leadership\n", l.name)
+
+	termCtx, cancel := context.WithCancel(context.WithValue(ctx, leaderFenceKey{}, fence))
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lead(termCtx)
+	}()
+
+	ticker := time.NewTicker(l.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			<-done
+			return nil
+		case <-ticker.C:
+			if _, err = conn.ExecContext(ctx, "SELECT 1"); err != nil && ctx.Err() == nil {
+				cancel()
+				<-done
+				return err
+			}
+		}
+	}
+}
+
+type leaderFenceKey struct{}
+
+// leaderFence identifies the database session holding the leadership lock.
+type leaderFence struct {
+	pid int64
+	key int64
+}
+
+// leaderFenceOf returns the fence of the leadership ctx was given for, nil outside of a leadership.
+func leaderFenceOf(ctx context.Context) *leaderFence {
+	fence, _ := ctx.Value(leaderFenceKey{}).(*leaderFence)
+	return fence
+}
+
+// check fails with ErrNotLeader unless the leader session still holds the lock. Postgres keys a
+// bigint advisory lock by its high and low 32 bits, run in the writing transaction it fences
+// the write off once the lock moved to another instance.
+func (f *leaderFence) check(tx *gorm.DB) error {
+	var held bool
+	err := tx.Raw(
+		"SELECT EXISTS (SELECT 1 FROM pg_locks WHERE locktype = 'advisory' AND granted AND pid = ?"+
+			" AND classid::bigint = ? AND objid::bigint = ? AND objsubid = 1)",
+		f.pid, uint32(f.key>>32), uint32(f.key),
+	).Scan(&held).Error
+	if err != nil {
+		return err
+	}
+
+	if !held {
+		return ErrNotLeader
+	}
+
+	return nil
+}
+
+func unlock(conn *sql.Conn, key int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultLeadershipCheckInterval)
+	defer cancel()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key); err != nil {
+		log.Printf("cannot release the advisory lock %d: %s\n", key, err)
+	}
+}
+
+// Exclusive runs fn while holding the advisory lock of the name, so only one instance sharing
+// the database runs it at a time.
+func (s *Storage) Exclusive(ctx context.Context, name string, fn func() error) error {
+	return lockSession(ctx, s.db, name, func(*sql.Conn) error {
+		return fn()
+	})
+}
+
+// lockSession runs fn with a database session holding the advisory lock of the name.
+func lockSession(ctx context.Context, db *gorm.DB, name string, fn func(conn *sql.Conn) error) error {
+	sqlDb, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDb.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	key := lockKey(name)
+	if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+		return err
+	}
+	defer unlock(conn, key)
+
+	return fn(conn)
+}
+
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+
+	return int64(h.Sum64())
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+func (s *StorageTestSuite) TestLeadership() {
+	const name = "test/leadership"
+
+	type term struct {
+		instance int
+		ctx      context.Context
+	}
+	terms := make(chan term, 10)
+
+	run := func(instance int, retryInterval time.Duration) (context.CancelFunc, <-chan struct{}) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		done := make(chan struct{})
+		leadership := s.storage.NewLeadership(name,
+			WithLeadershipCheckInterval(50*time.Millisecond),
+			WithLeadershipRetryInterval(retryInterval),
+		)
+
+		go func() {
+			defer close(done)
+			leadership.Run(ctx, func(ctx context.Context) {
+				terms <- term{instance: instance, ctx: ctx}
+				<-ctx.Done()
+			})
+		}()
+
+		return cancel, done
+	}
+
+	next := func() term {
+		select {
+		case t := <-terms:
+			return t
+		case <-time.After(5 * time.Second):
+			s.FailNow("no instance took the leadership")
+			return term{}
+		}
+	}
+
+	assertNoLeader := func() {
+		select {
+		case t := <-terms:
+			s.Failf("unexpected leader", "instance %d took the held leadership", t.instance)
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+
+	assertEnded := func(t term) {
+		select {
+		case <-t.ctx.Done():
+		case <-time.After(5 * time.Second):
+			s.Failf("term did not end", "instance %d still leads", t.instance)
+		}
+	}
+
+	checkFence := func(t term) error {
+		return s.storage.db.Transaction(leaderFenceOf(t.ctx).check)
+	}
+
+	// The first instance retries slowly, so the leadership is taken over by the second one.
+	cancelFirst, firstDone := run(1, time.Minute)
+	defer func() {
+		cancelFirst()
+		<-firstDone
+	}()
+
+	var first, second term
+	s.Run("Acquire", func() {
+		first = next()
+		s.Equal(1, first.instance)
+		s.NoError(checkFence(first))
+	})
+
+	cancelSecond, secondDone := run(2, 50*time.Millisecond)
+	defer func() {
+		cancelSecond()
+		<-secondDone
+	}()
+	assertNoLeader()
+
+	s.Run("Failover", func() {
+		res := s.storage.db.Exec("SELECT pg_terminate_backend(?)", leaderFenceOf(first.ctx).pid)
+		s.Require().NoError(res.Error, res.Error)
+
+		second = next()
+		s.Equal(2, second.instance)
+		assertEnded(first)
+
+		s.ErrorIs(checkFence(first), ErrNotLeader, "the lost leadership fences the writes off")
+		s.NoError(checkFence(second))
+
+		writer := s.storage.NewBatchWriter(WithLeaderContext(first.ctx), WithFlushInterval(time.Hour))
+		sensor := s.testSensorGroups[0].sensors[0]
+		err := writer.Write(&SensorUpdate{Sensor: sensor, Temperature: &Temperature{SensorId: uint64(sensor.ID), Temperature: 10}})
+		s.Require().NoError(err, err)
+		s.ErrorIs(writer.Flush(context.TODO()), ErrNotLeader)
+		s.NoError(writer.Close())
+	})
+
+	s.Run("Release", func() {
+		cancelSecond()
+		<-secondDone
+		assertEnded(second)
+
+		cancelThird, thirdDone := run(3, time.Minute)
+		defer func() {
+			cancelThird()
+			<-thirdDone
+		}()
+
+		s.Equal(3, next().instance, "the released lock is taken at once")
+	})
+}
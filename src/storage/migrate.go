@@ -0,0 +1,277 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MigrationTable = "schema_migrations"
+
+	// migrationLock serialises migrations of the instances sharing the database.
+	migrationLock = "fake-sensors/migrate"
+)
+
+var (
+	//go:embed migrations/*.sql
+	migrationFiles embed.FS
+
+	migrationFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+	ErrBadMigration           = errors.New("invalid migration")
+	ErrUnknownSchemaVersion   = errors.New("database schema version is unknown to this build")
+	ErrUnknownMigrationTarget = errors.New("unknown migration version")
+)
+
+// Migration is a versioned schema change, the migrations are applied in the order of versions
+// and rolled back in the reverse order.
+type Migration struct {
+	Version uint
+	Name    string
+	// AppliedAt is nil unless the migration is applied to the database.
+	AppliedAt *time.Time
+
+	up, down string
+}
+
+// loadMigrations reads the migrations embedded in the binary, every version has an up and a down file.
+func loadMigrations() ([]*Migration, error) {
+	files, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, file := range files {
+		matches := migrationFilePattern.FindStringSubmatch(file.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("%w: unexpected file %s", ErrBadMigration, file.Name())
+		}
+
+		version, err := strconv.ParseUint(matches[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("%w: bad version of %s", ErrBadMigration, file.Name())
+		}
+
+		content, err := migrationFiles.ReadFile("migrations/" + file.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: matches[2]}
+			byVersion[m.Version] = m
+		} else if m.Name != matches[2] {
+			return nil, fmt.Errorf("%w: version %s has different names", ErrBadMigration, matches[1])
+		}
+
+		if matches[3] == "up" {
+			m.up = string(content)
+		} else {
+			m.down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("%w: version %d misses up or down", ErrBadMigration, m.Version)
+		}
+		migrations = append(migrations, m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Migrations returns the migrations of this build with the applied ones marked.
+func (s *Storage) Migrations(ctx context.Context) ([]*Migration, error) {
+	var migrations []*Migration
+	err := lockSession(ctx, s.db, migrationLock, func(conn *sql.Conn) error {
+		var err error
+		migrations, _, err = migrationState(ctx, conn)
+		return err
+	})
+
+	return migrations, err
+}
+
+// MigrateUp applies the pending migrations up to the target version, 0 means the latest one,
+// and returns the applied migrations.
+func (s *Storage) MigrateUp(ctx context.Context, target uint) ([]*Migration, error) {
+	return migrateUp(ctx, s.db, target)
+}
+
+// MigrateDown rolls back the applied migrations newer than the target version, 0 rolls back
+// all of them, and returns the rolled back migrations.
+func (s *Storage) MigrateDown(ctx context.Context, target uint) ([]*Migration, error) {
+	var done []*Migration
+	err := lockSession(ctx, s.db, migrationLock, func(conn *sql.Conn) error {
+		migrations, _, err := migrationState(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if err = checkTarget(migrations, target); err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0; i-- {
+			m := migrations[i]
+			if m.Version <= target || m.AppliedAt == nil {
+				continue
+			}
+
+			err = runMigration(ctx, conn, m.down, "DELETE FROM "+MigrationTable+" WHERE version = $1", m.Version)
+			if err != nil {
+				return fmt.Errorf("cannot roll back migration %s: %w", m, err)
+			}
+
+			m.AppliedAt = nil
+			done = append(done, m)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+func migrateUp(ctx context.Context, db *gorm.DB, target uint) ([]*Migration, error) {
+	var done []*Migration
+	err := lockSession(ctx, db, migrationLock, func(conn *sql.Conn) error {
+		migrations, unknown, err := migrationState(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if unknown != 0 {
+			return fmt.Errorf("%w: %d", ErrUnknownSchemaVersion, unknown)
+		}
+
+		if err = checkTarget(migrations, target); err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if target != 0 && m.Version > target {
+				break
+			}
+			if m.AppliedAt != nil {
+				continue
+			}
+
+			err = runMigration(ctx, conn, m.up, "INSERT INTO "+MigrationTable+" (version, name) VALUES ($1, $2)", m.Version, m.Name)
+			if err != nil {
+				return fmt.Errorf("cannot apply migration %s: %w", m, err)
+			}
+
+			now := time.Now()
+			m.AppliedAt = &now
+			done = append(done, m)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// migrationState marks the applied migrations and returns the newest applied version this
+// build does not know, 0 if there is none.
+func migrationState(ctx context.Context, conn *sql.Conn) ([]*Migration, uint, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+MigrationTable+` (
+		version    bigint PRIMARY KEY,
+		name       text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT NOW()
+	)`)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM "+MigrationTable)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	byVersion := make(map[uint]*Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	var unknown uint
+	for rows.Next() {
+		var version uint
+		var appliedAt time.Time
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			return nil, 0, err
+		}
+
+		if m, ok := byVersion[version]; ok {
+			m.AppliedAt = &appliedAt
+		} else if version > unknown {
+			unknown = version
+		}
+	}
+
+	return migrations, unknown, rows.Err()
+}
+
+func checkTarget(migrations []*Migration, target uint) error {
+	if target == 0 {
+		return nil
+	}
+
+	for _, m := range migrations {
+		if m.Version == target {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %d", ErrUnknownMigrationTarget, target)
+}
+
+// runMigration runs the migration script and records it in a single transaction, so a failed
+// migration leaves no trace.
+func runMigration(ctx context.Context, conn *sql.Conn, script, record string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, script); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, record, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migration) String() string {
+	return strconv.FormatUint(uint64(m.Version), 10) + "_" + m.Name
+}
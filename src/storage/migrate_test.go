@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	require.NoError(t, err, err)
+	require.NotEmpty(t, migrations)
+
+	for i, m := range migrations {
+		assert.Equal(t, uint(i+1), m.Version, "versions have no gaps")
+		assert.NotEmpty(t, m.up, m.String())
+		assert.NotEmpty(t, m.down, m.String())
+		assert.Nil(t, m.AppliedAt)
+	}
+}
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS current_sensor_fishes;
+DROP TABLE IF EXISTS fish;
+DROP TABLE IF EXISTS transparencies;
+DROP TABLE IF EXISTS temperatures;
+DROP TABLE IF EXISTS sensors;
+DROP TABLE IF EXISTS groups;
@@ -0,0 +1,65 @@
+-- The tables match the ones AutoMigrate created before the migrations existed, so such
+-- databases take the migration without changes.
+CREATE TABLE IF NOT EXISTS groups (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name       text
+);
+CREATE INDEX IF NOT EXISTS idx_groups_deleted_at ON groups (deleted_at);
+
+CREATE TABLE IF NOT EXISTS sensors (
+    id               bigserial PRIMARY KEY,
+    created_at       timestamptz,
+    updated_at       timestamptz,
+    deleted_at       timestamptz,
+    group_id         bigint,
+    index_in_group   bigint,
+    x                decimal,
+    y                decimal,
+    z                decimal,
+    data_output_rate bigint
+);
+CREATE INDEX IF NOT EXISTS idx_sensors_deleted_at ON sensors (deleted_at);
+
+CREATE TABLE IF NOT EXISTS temperatures (
+    id          bigserial PRIMARY KEY,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz,
+    sensor_id   bigint,
+    temperature decimal
+);
+CREATE INDEX IF NOT EXISTS idx_temperatures_deleted_at ON temperatures (deleted_at);
+
+CREATE TABLE IF NOT EXISTS transparencies (
+    id           bigserial PRIMARY KEY,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz,
+    sensor_id    bigint,
+    transparency smallint
+);
+CREATE INDEX IF NOT EXISTS idx_transparencies_deleted_at ON transparencies (deleted_at);
+
+CREATE TABLE IF NOT EXISTS fish (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    sensor_id  bigint,
+    name       text,
+    count      bigint
+);
+CREATE INDEX IF NOT EXISTS idx_fish_deleted_at ON fish (deleted_at);
+
+CREATE TABLE IF NOT EXISTS current_sensor_fishes (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    sensor_id  bigint,
+    fish_id    bigint
+);
+CREATE INDEX IF NOT EXISTS idx_current_sensor_fishes_deleted_at ON current_sensor_fishes (deleted_at);
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS latest_readings (
+    sensor_id       bigint PRIMARY KEY,
+    group_id        bigint,
+    temperature_id  bigint,
+    temperature     decimal,
+    temperature_at  timestamptz,
+    transparency_id bigint,
+    transparency    smallint,
+    transparency_at timestamptz,
+    updated_at      timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_latest_readings_group_id ON latest_readings (group_id);
+
+-- Databases created before the table existed keep their current state.
+INSERT INTO latest_readings (sensor_id, group_id, temperature_id, temperature, temperature_at,
+                             transparency_id, transparency, transparency_at, updated_at)
+SELECT s.id, s.group_id, t.id, t.temperature, t.created_at, tr.id, tr.transparency, tr.created_at, NOW()
+FROM sensors s
+LEFT JOIN LATERAL (
+    SELECT id, temperature, created_at FROM temperatures
+    WHERE sensor_id = s.id AND deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT 1
+) t ON TRUE
+LEFT JOIN LATERAL (
+    SELECT id, transparency, created_at FROM transparencies
+    WHERE sensor_id = s.id AND deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT 1
+) tr ON TRUE
+WHERE s.deleted_at IS NULL AND (t.id IS NOT NULL OR tr.id IS NOT NULL)
+ON CONFLICT (sensor_id) DO NOTHING;
+
+DROP TABLE IF EXISTS current_statistics;
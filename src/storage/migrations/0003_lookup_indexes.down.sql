@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_current_sensor_fishes_sensor_id;
+DROP INDEX IF EXISTS idx_sensors_group_id_index_in_group;
+DROP INDEX IF EXISTS idx_groups_name;
+DROP INDEX IF EXISTS idx_fish_sensor_id_created_at;
+DROP INDEX IF EXISTS idx_transparencies_sensor_id_created_at;
+DROP INDEX IF EXISTS idx_temperatures_sensor_id_created_at;
@@ -0,0 +1,10 @@
+-- Readings are filtered by sensor and time range, the latest ones are found by the same order.
+CREATE INDEX IF NOT EXISTS idx_temperatures_sensor_id_created_at ON temperatures (sensor_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_transparencies_sensor_id_created_at ON transparencies (sensor_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_fish_sensor_id_created_at ON fish (sensor_id, created_at);
+
+-- Sensors are looked up by group name and code name.
+CREATE INDEX IF NOT EXISTS idx_groups_name ON groups (name);
+CREATE INDEX IF NOT EXISTS idx_sensors_group_id_index_in_group ON sensors (group_id, index_in_group);
+
+CREATE INDEX IF NOT EXISTS idx_current_sensor_fishes_sensor_id ON current_sensor_fishes (sensor_id);
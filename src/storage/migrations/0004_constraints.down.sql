@@ -0,0 +1,38 @@
+ALTER TABLE latest_readings
+    DROP CONSTRAINT IF EXISTS fk_latest_readings_transparency,
+    DROP CONSTRAINT IF EXISTS fk_latest_readings_temperature,
+    DROP CONSTRAINT IF EXISTS fk_latest_readings_group,
+    DROP CONSTRAINT IF EXISTS fk_latest_readings_sensor;
+ALTER TABLE current_sensor_fishes
+    DROP CONSTRAINT IF EXISTS fk_current_sensor_fishes_fish,
+    DROP CONSTRAINT IF EXISTS fk_current_sensor_fishes_sensor;
+ALTER TABLE fish DROP CONSTRAINT IF EXISTS fk_fish_sensor;
+ALTER TABLE transparencies DROP CONSTRAINT IF EXISTS fk_transparencies_sensor;
+ALTER TABLE temperatures DROP CONSTRAINT IF EXISTS fk_temperatures_sensor;
+ALTER TABLE sensors DROP CONSTRAINT IF EXISTS fk_sensors_group;
+
+ALTER TABLE fish DROP CONSTRAINT IF EXISTS chk_fish_count;
+ALTER TABLE transparencies DROP CONSTRAINT IF EXISTS chk_transparencies_transparency;
+ALTER TABLE sensors
+    DROP CONSTRAINT IF EXISTS chk_sensors_data_output_rate,
+    DROP CONSTRAINT IF EXISTS chk_sensors_index_in_group;
+
+ALTER TABLE sensors DROP CONSTRAINT IF EXISTS uni_sensors_group_id_index_in_group;
+CREATE INDEX IF NOT EXISTS idx_sensors_group_id_index_in_group ON sensors (group_id, index_in_group);
+DROP INDEX IF EXISTS idx_groups_name;
+CREATE INDEX IF NOT EXISTS idx_groups_name ON groups (name);
+
+ALTER TABLE latest_readings ALTER COLUMN group_id DROP NOT NULL;
+ALTER TABLE current_sensor_fishes
+    ALTER COLUMN fish_id DROP NOT NULL,
+    ALTER COLUMN sensor_id DROP NOT NULL;
+ALTER TABLE fish
+    ALTER COLUMN name DROP NOT NULL,
+    ALTER COLUMN sensor_id DROP NOT NULL;
+ALTER TABLE transparencies ALTER COLUMN sensor_id DROP NOT NULL;
+ALTER TABLE temperatures ALTER COLUMN sensor_id DROP NOT NULL;
+ALTER TABLE sensors
+    ALTER COLUMN data_output_rate DROP NOT NULL,
+    ALTER COLUMN index_in_group DROP NOT NULL,
+    ALTER COLUMN group_id DROP NOT NULL;
+ALTER TABLE groups ALTER COLUMN name DROP NOT NULL;
@@ -0,0 +1,86 @@
+-- Sensors of groups and readings, fish and current state of sensors which do not exist any more
+-- cannot be referenced. They are never deleted silently, the migration fails with their counts
+-- and the upgrade notes in the README show how to review and remove them.
+DO $$
+DECLARE
+    orphans text := '';
+    n       bigint;
+BEGIN
+    SELECT count(*) INTO n FROM sensors s WHERE NOT EXISTS (SELECT 1 FROM groups g WHERE g.id = s.group_id);
+    IF n > 0 THEN orphans := orphans || format(' %s sensors,', n); END IF;
+
+    SELECT count(*) INTO n FROM current_sensor_fishes c
+    WHERE NOT EXISTS (SELECT 1 FROM sensors s WHERE s.id = c.sensor_id)
+       OR NOT EXISTS (SELECT 1 FROM fish f WHERE f.id = c.fish_id);
+    IF n > 0 THEN orphans := orphans || format(' %s current fish,', n); END IF;
+
+    SELECT count(*) INTO n FROM latest_readings l WHERE NOT EXISTS (SELECT 1 FROM sensors s WHERE s.id = l.sensor_id);
+    IF n > 0 THEN orphans := orphans || format(' %s latest readings,', n); END IF;
+
+    SELECT count(*) INTO n FROM temperatures t WHERE NOT EXISTS (SELECT 1 FROM sensors s WHERE s.id = t.sensor_id);
+    IF n > 0 THEN orphans := orphans || format(' %s temperatures,', n); END IF;
+
+    SELECT count(*) INTO n FROM transparencies t WHERE NOT EXISTS (SELECT 1 FROM sensors s WHERE s.id = t.sensor_id);
+    IF n > 0 THEN orphans := orphans || format(' %s transparencies,', n); END IF;
+
+    SELECT count(*) INTO n FROM fish f WHERE NOT EXISTS (SELECT 1 FROM sensors s WHERE s.id = f.sensor_id);
+    IF n > 0 THEN orphans := orphans || format(' %s fish,', n); END IF;
+
+    IF orphans <> '' THEN
+        RAISE EXCEPTION 'orphaned records have to be removed before the constraints are added:% see the upgrade notes in the README',
+            orphans;
+    END IF;
+END $$;
+
+ALTER TABLE groups ALTER COLUMN name SET NOT NULL;
+ALTER TABLE sensors
+    ALTER COLUMN group_id SET NOT NULL,
+    ALTER COLUMN index_in_group SET NOT NULL,
+    ALTER COLUMN data_output_rate SET NOT NULL;
+ALTER TABLE temperatures ALTER COLUMN sensor_id SET NOT NULL;
+ALTER TABLE transparencies ALTER COLUMN sensor_id SET NOT NULL;
+ALTER TABLE fish
+    ALTER COLUMN sensor_id SET NOT NULL,
+    ALTER COLUMN name SET NOT NULL;
+ALTER TABLE current_sensor_fishes
+    ALTER COLUMN sensor_id SET NOT NULL,
+    ALTER COLUMN fish_id SET NOT NULL;
+ALTER TABLE latest_readings ALTER COLUMN group_id SET NOT NULL;
+
+-- A group name and a sensor code name identify a single record. Deleted groups free their
+-- names, while indexes of deleted sensors are never reused, so code names in the history stay
+-- unambiguous.
+DROP INDEX IF EXISTS idx_groups_name;
+CREATE UNIQUE INDEX idx_groups_name ON groups (name) WHERE deleted_at IS NULL;
+DROP INDEX IF EXISTS idx_sensors_group_id_index_in_group;
+ALTER TABLE sensors ADD CONSTRAINT uni_sensors_group_id_index_in_group UNIQUE (group_id, index_in_group);
+
+ALTER TABLE sensors
+    ADD CONSTRAINT chk_sensors_index_in_group CHECK (index_in_group >= 0),
+    ADD CONSTRAINT chk_sensors_data_output_rate CHECK (data_output_rate > 0);
+ALTER TABLE transparencies ADD CONSTRAINT chk_transparencies_transparency CHECK (transparency BETWEEN 0 AND 100);
+ALTER TABLE fish ADD CONSTRAINT chk_fish_count CHECK (count >= 0);
+
+-- Removing a sensor or a group for good removes everything recorded by it.
+ALTER TABLE sensors ADD CONSTRAINT fk_sensors_group
+    FOREIGN KEY (group_id) REFERENCES groups (id) ON DELETE CASCADE;
+ALTER TABLE temperatures ADD CONSTRAINT fk_temperatures_sensor
+    FOREIGN KEY (sensor_id) REFERENCES sensors (id) ON DELETE CASCADE;
+ALTER TABLE transparencies ADD CONSTRAINT fk_transparencies_sensor
+    FOREIGN KEY (sensor_id) REFERENCES sensors (id) ON DELETE CASCADE;
+ALTER TABLE fish ADD CONSTRAINT fk_fish_sensor
+    FOREIGN KEY (sensor_id) REFERENCES sensors (id) ON DELETE CASCADE;
+ALTER TABLE current_sensor_fishes
+    ADD CONSTRAINT fk_current_sensor_fishes_sensor
+        FOREIGN KEY (sensor_id) REFERENCES sensors (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_current_sensor_fishes_fish
+        FOREIGN KEY (fish_id) REFERENCES fish (id) ON DELETE CASCADE;
+ALTER TABLE latest_readings
+    ADD CONSTRAINT fk_latest_readings_sensor
+        FOREIGN KEY (sensor_id) REFERENCES sensors (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_latest_readings_group
+        FOREIGN KEY (group_id) REFERENCES groups (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_latest_readings_temperature
+        FOREIGN KEY (temperature_id) REFERENCES temperatures (id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_latest_readings_transparency
+        FOREIGN KEY (transparency_id) REFERENCES transparencies (id) ON DELETE SET NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_sensors_code_name;
+ALTER TABLE sensors
+    DROP CONSTRAINT IF EXISTS uni_sensors_uuid,
+    DROP COLUMN IF EXISTS code_name,
+    DROP COLUMN IF EXISTS uuid;
@@ -0,0 +1,11 @@
+-- Sensors keep their code names, so they are resolved without parsing, and get UUIDs which
+-- identify them across databases.
+ALTER TABLE sensors
+    ADD COLUMN uuid uuid NOT NULL DEFAULT gen_random_uuid(),
+    ADD COLUMN code_name text;
+
+UPDATE sensors s SET code_name = g.name || s.index_in_group FROM groups g WHERE g.id = s.group_id;
+
+ALTER TABLE sensors ALTER COLUMN code_name SET NOT NULL;
+ALTER TABLE sensors ADD CONSTRAINT uni_sensors_uuid UNIQUE (uuid);
+CREATE UNIQUE INDEX idx_sensors_code_name ON sensors (code_name) WHERE deleted_at IS NULL;
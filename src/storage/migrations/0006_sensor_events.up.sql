@@ -0,0 +1,11 @@
+-- Health status transitions of the sensors, the latest event of a sensor holds its recorded status.
+CREATE TABLE sensor_events (
+    id           bigserial PRIMARY KEY,
+    created_at   timestamptz NOT NULL,
+    sensor_id    bigint NOT NULL REFERENCES sensors (id) ON DELETE CASCADE,
+    from_status  text NOT NULL,
+    to_status    text NOT NULL,
+    missed       bigint NOT NULL CHECK (missed >= 0),
+    last_seen_at timestamptz
+);
+CREATE INDEX idx_sensor_events_sensor_id_created_at ON sensor_events (sensor_id, created_at);
@@ -0,0 +1,5 @@
+ALTER TABLE sensors
+    DROP CONSTRAINT IF EXISTS chk_sensors_battery,
+    DROP COLUMN IF EXISTS maintained_at,
+    DROP COLUMN IF EXISTS failed_at,
+    DROP COLUMN IF EXISTS battery;
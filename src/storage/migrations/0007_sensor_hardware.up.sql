@@ -0,0 +1,7 @@
+-- Simulated hardware state of the sensors: battery level in percent, the time of a permanent
+-- failure and the time of the last maintenance visit.
+ALTER TABLE sensors
+    ADD COLUMN battery double precision NOT NULL DEFAULT 100,
+    ADD COLUMN failed_at timestamptz,
+    ADD COLUMN maintained_at timestamptz,
+    ADD CONSTRAINT chk_sensors_battery CHECK (battery BETWEEN 0 AND 100);
@@ -0,0 +1,12 @@
+ALTER TABLE temperatures DROP COLUMN IF EXISTS true_temperature;
+
+ALTER TABLE sensors
+    DROP CONSTRAINT IF EXISTS chk_sensors_calibration_unit,
+    DROP CONSTRAINT IF EXISTS chk_sensors_calibration_resolution,
+    DROP CONSTRAINT IF EXISTS chk_sensors_calibration_noise_std,
+    DROP CONSTRAINT IF EXISTS chk_sensors_calibration_gain,
+    DROP COLUMN IF EXISTS calibration_unit,
+    DROP COLUMN IF EXISTS calibration_resolution,
+    DROP COLUMN IF EXISTS calibration_noise_std,
+    DROP COLUMN IF EXISTS calibration_gain,
+    DROP COLUMN IF EXISTS calibration_offset;
@@ -0,0 +1,17 @@
+-- Calibration of the sensors: the measured temperature is gain * true + offset plus a normal
+-- noise, rounded to the resolution. Offset, noise and resolution are in the temperature unit of
+-- the sensor, the existing sensors keep the error they were generated with so far.
+ALTER TABLE sensors
+    ADD COLUMN calibration_offset double precision NOT NULL DEFAULT 0,
+    ADD COLUMN calibration_gain double precision NOT NULL DEFAULT 1,
+    ADD COLUMN calibration_noise_std double precision NOT NULL DEFAULT 0.2,
+    ADD COLUMN calibration_resolution double precision NOT NULL DEFAULT 0,
+    ADD COLUMN calibration_unit text NOT NULL DEFAULT 'celsius',
+    ADD CONSTRAINT chk_sensors_calibration_gain CHECK (calibration_gain > 0),
+    ADD CONSTRAINT chk_sensors_calibration_noise_std CHECK (calibration_noise_std >= 0),
+    ADD CONSTRAINT chk_sensors_calibration_resolution CHECK (calibration_resolution >= 0),
+    ADD CONSTRAINT chk_sensors_calibration_unit CHECK (calibration_unit IN ('celsius', 'fahrenheit', 'kelvin'));
+
+-- The true temperature the sensor measured, in Celsius like the measured one. It is NULL for
+-- readings which were not generated.
+ALTER TABLE temperatures ADD COLUMN true_temperature double precision;
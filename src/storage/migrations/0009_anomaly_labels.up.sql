@@ -0,0 +1,17 @@
+-- Ground truth of the anomalies the generator produced. created_at is the start of the anomaly
+-- and ended_at its end, they are equal for anomalies of a single reading. An empty metric means
+-- the anomaly affects every metric of the sensor.
+CREATE TABLE anomaly_labels (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL,
+    ended_at   timestamptz NOT NULL,
+    sensor_id  bigint NOT NULL REFERENCES sensors (id) ON DELETE CASCADE,
+    kind       text NOT NULL,
+    metric     text NOT NULL DEFAULT '',
+    magnitude  double precision NOT NULL DEFAULT 0,
+    detail     text NOT NULL DEFAULT '',
+    CONSTRAINT chk_anomaly_labels_kind CHECK (kind IN ('spike', 'drift', 'dropout', 'scenario_event', 'population_shock')),
+    CONSTRAINT chk_anomaly_labels_period CHECK (ended_at >= created_at)
+);
+CREATE INDEX idx_anomaly_labels_sensor_id_created_at ON anomaly_labels (sensor_id, created_at);
+CREATE INDEX idx_anomaly_labels_created_at ON anomaly_labels (created_at);
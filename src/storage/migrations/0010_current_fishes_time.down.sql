@@ -0,0 +1 @@
+ALTER TABLE latest_readings DROP COLUMN IF EXISTS fishes_at;
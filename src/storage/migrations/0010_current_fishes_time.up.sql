@@ -0,0 +1,11 @@
+-- Time the current fish of a sensor were observed, so late readings never replace newer ones.
+ALTER TABLE latest_readings ADD COLUMN IF NOT EXISTS fishes_at timestamptz;
+
+-- The current fish of existing sensors were observed when they were saved.
+INSERT INTO latest_readings (sensor_id, group_id, fishes_at, updated_at)
+SELECT s.id, s.group_id, MAX(f.created_at), NOW()
+FROM current_sensor_fishes c
+JOIN sensors s ON s.id = c.sensor_id
+JOIN fish f ON f.id = c.fish_id
+GROUP BY s.id, s.group_id
+ON CONFLICT (sensor_id) DO UPDATE SET fishes_at = EXCLUDED.fishes_at;
@@ -0,0 +1,4 @@
+DELETE FROM anomaly_labels WHERE kind IN ('hardware_failure', 'battery_depleted');
+ALTER TABLE anomaly_labels DROP CONSTRAINT chk_anomaly_labels_kind;
+ALTER TABLE anomaly_labels ADD CONSTRAINT chk_anomaly_labels_kind
+    CHECK (kind IN ('spike', 'drift', 'dropout', 'scenario_event', 'population_shock'));
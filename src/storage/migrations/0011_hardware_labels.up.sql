@@ -0,0 +1,4 @@
+-- The hardware failures and the depleted batteries are labelled as anomalies too.
+ALTER TABLE anomaly_labels DROP CONSTRAINT chk_anomaly_labels_kind;
+ALTER TABLE anomaly_labels ADD CONSTRAINT chk_anomaly_labels_kind
+    CHECK (kind IN ('spike', 'drift', 'dropout', 'scenario_event', 'population_shock', 'hardware_failure', 'battery_depleted'));
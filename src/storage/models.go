@@ -3,6 +3,7 @@ package storage
 import (
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -13,7 +14,7 @@ const (
 	TemperatureTable  = "temperatures"
 	TransparencyTable = "transparencies"
 
-	CurrentStatisticTable  = "current_statistics"
+	LatestReadingTable     = "latest_readings"
 	CurrentSensorFishTable = "current_sensor_fishes"
 )
 
@@ -34,12 +35,23 @@ type Group struct {
 type Sensor struct {
 	gorm.Model
 
+	UUID     uuid.UUID `gorm:"type:uuid"`
+	CodeName string
+
 	GroupId      uint64
 	IndexInGroup uint64
 
 	X, Y, Z float64
 
 	DataOutputRate time.Duration
+
+	// Battery is the charge level in percent, FailedAt is set once the sensor broke down and
+	// MaintainedAt is the time of the last maintenance visit.
+	Battery      float64 `gorm:"default:100"`
+	FailedAt     *time.Time
+	MaintainedAt *time.Time
+
+	Calibration Calibration `gorm:"embedded;embeddedPrefix:calibration_"`
 }
 
 type Temperature struct {
@@ -47,6 +59,9 @@ type Temperature struct {
 
 	SensorId    uint64
 	Temperature float64
+	// TrueTemperature is the temperature before the calibration errors of the sensor were
+	// applied, nil if the reading was not generated.
+	TrueTemperature *float64
 }
 
 type Transparency struct {
@@ -56,13 +71,24 @@ type Transparency struct {
 	Transparency uint8
 }
 
-type CurrentStatistic struct {
-	gorm.Model
+// LatestReading is the last value of every metric a sensor reported. A metric the sensor
+// never reported is nil.
+type LatestReading struct {
+	SensorId uint `gorm:"primaryKey;autoIncrement:false"`
+	GroupId  uint `gorm:"index"`
+
+	TemperatureId *uint
+	Temperature   *float64
+	TemperatureAt *time.Time
+
+	TransparencyId *uint
+	Transparency   *uint8
+	TransparencyAt *time.Time
+
+	// FishesAt is the time the current fish of the sensor were observed.
+	FishesAt *time.Time
 
-	GroupId        uint
-	SensorId       uint
-	TransparencyId uint
-	TemperatureId  uint
+	UpdatedAt time.Time
 }
 
 type CurrentSensorFish struct {
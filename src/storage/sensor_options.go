@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type SensorSort string
+
+const (
+	SortByCodeName       SensorSort = "code_name"
+	SortByX              SensorSort = "x"
+	SortByY              SensorSort = "y"
+	SortByZ              SensorSort = "z"
+	SortByDataOutputRate SensorSort = "data_output_rate"
+	SortByCreatedAt      SensorSort = "created_at"
+	SortByLastReading    SensorSort = "last_reading"
+	SortByBattery        SensorSort = "battery"
+)
+
+var ErrUnknownSensorSort = errors.New("unknown sensor sort field")
+
+func ParseSensorSort(sort string) (SensorSort, error) {
+	switch s := SensorSort(sort); s {
+	case SortByCodeName, SortByX, SortByY, SortByZ, SortByDataOutputRate, SortByCreatedAt, SortByLastReading, SortByBattery:
+		return s, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownSensorSort, sort)
+}
+
+// lastReadingAt is the time of the latest value the sensor reported, NULL if it never reported.
+const lastReadingAt = "GREATEST(" + LatestReadingTable + ".temperature_at, " + LatestReadingTable + ".transparency_at)"
+
+// columns are what the sensors are ordered by. Code names are ordered by the group name and the
+// index in the group, so alpha2 comes before alpha10.
+func (s SensorSort) columns() []string {
+	switch s {
+	case SortByLastReading:
+		return []string{lastReadingAt}
+	case SortByCodeName:
+		return []string{GroupTable + ".name", SensorTable + ".index_in_group"}
+	}
+
+	return []string{SensorTable + "." + string(s)}
+}
+
+// SensorQueryOption narrows down, orders or pages the sensors returned by GetAllSensors.
+type SensorQueryOption func(q *sensorQuery)
+
+type sensorQuery struct {
+	group  string
+	region []CoordinateOption
+
+	sort SensorSort
+	desc bool
+
+	limit, offset int
+}
+
+func WithSensorGroup(group string) SensorQueryOption {
+	return func(q *sensorQuery) {
+		if group != "" {
+			q.group = group
+		}
+	}
+}
+
+func WithSensorRegion(opts ...CoordinateOption) SensorQueryOption {
+	return func(q *sensorQuery) {
+		q.region = append(q.region, opts...)
+	}
+}
+
+// WithSensorSort orders the sensors by the field, sensors which never reported come last when
+// they are ordered by the last reading.
+func WithSensorSort(sort SensorSort, desc bool) SensorQueryOption {
+	return func(q *sensorQuery) {
+		if sort != "" {
+			q.sort, q.desc = sort, desc
+		}
+	}
+}
+
+func WithSensorPage(limit, offset int) SensorQueryOption {
+	return func(q *sensorQuery) {
+		if limit > 0 {
+			q.limit = limit
+		}
+		if offset > 0 {
+			q.offset = offset
+		}
+	}
+}
+
+func newSensorQuery(opts []SensorQueryOption) *sensorQuery {
+	q := &sensorQuery{}
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	return q
+}
+
+// filter applies the group and region conditions, the total number of matching sensors is
+// counted before the order and page are applied.
+func (q *sensorQuery) filter(tx *gorm.DB) *gorm.DB {
+	if q.group != "" {
+		tx = tx.Where(SensorTable+".group_id IN (SELECT id FROM "+GroupTable+" WHERE name = ? AND deleted_at IS NULL)", q.group)
+	}
+
+	for _, opt := range q.region {
+		opt(tx)
+	}
+
+	return tx
+}
+
+func (q *sensorQuery) page(tx *gorm.DB) *gorm.DB {
+	switch q.sort {
+	case SortByLastReading:
+		tx = tx.Joins("LEFT JOIN " + LatestReadingTable + " ON " + LatestReadingTable + ".sensor_id = " + SensorTable + ".id")
+	case SortByCodeName:
+		tx = tx.Joins("LEFT JOIN " + GroupTable + " ON " + GroupTable + ".id = " + SensorTable + ".group_id")
+	}
+
+	direction := " ASC NULLS LAST"
+	if q.desc {
+		direction = " DESC NULLS LAST"
+	}
+	if q.sort != "" {
+		for _, column := range q.sort.columns() {
+			tx = tx.Order(column + direction)
+		}
+	}
+	tx = tx.Order(SensorTable + ".id")
+
+	if q.limit > 0 {
+		tx = tx.Limit(q.limit)
+	}
+	if q.offset > 0 {
+		tx = tx.Offset(q.offset)
+	}
+
+	return tx
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUnknownGroup  = errors.New("unknown group")
+	ErrUnknownSensor = errors.New("unknown sensor")
+)
+
+// cacheKeyPatterns match every key the storage keeps in redis.
+var cacheKeyPatterns = []string{temperatureKey + "*", transparencyKey + "*", regionKey + "*", speciesKey + "*", workersKey}
+
+// AddSensor saves the sensor in the group, it gets the index following the last one ever used
+// in the group, so code names of removed sensors are not reused.
+func (s *Storage) AddSensor(group string, sensor *Sensor) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var g Group
+		res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", group).Limit(1).Find(&g)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("%w: %s", ErrUnknownGroup, group)
+		}
+
+		var next uint64
+		res = tx.Unscoped().Model(&Sensor{}).
+			Select("COALESCE(MAX(index_in_group) + 1, 0)").
+			Where("group_id = ?", g.ID).
+			Scan(&next)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		sensor.GroupId = uint64(g.ID)
+		sensor.IndexInGroup = next
+		sensor.CodeName = CodeName(g.Name, next)
+		return tx.Create(sensor).Error
+	})
+}
+
+// RemoveSensor deletes the sensor, its readings stay in the history while its latest readings
+// and fish stop counting in the current state of the group. Purge deletes the sensor for good,
+// the database cascades the deletion to all its readings and fish.
+func (s *Storage) RemoveSensor(ctx context.Context, id uint, purge bool) error {
+	changes := s.newCacheChanges()
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var sensor Sensor
+		res := tx.Where("id = ?", id).Limit(1).Find(&sensor)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("%w: %d", ErrUnknownSensor, id)
+		}
+
+		var latest []*LatestReading
+		res = tx.Raw("SELECT * FROM "+LatestReadingTable+" WHERE sensor_id = ? FOR UPDATE", id).Scan(&latest)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		change := changes.average(uint(sensor.GroupId))
+		changes.speciesChanged(uint(sensor.GroupId))
+		changes.regions = true
+		for _, reading := range latest {
+			if reading.Temperature != nil {
+				change.temperatureSum -= *reading.Temperature
+				change.temperatureCount--
+			}
+			if reading.Transparency != nil {
+				change.transparencySum -= float64(*reading.Transparency)
+				change.transparencyCount--
+			}
+		}
+
+		if err := tx.Exec("DELETE FROM "+LatestReadingTable+" WHERE sensor_id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM "+CurrentSensorFishTable+" WHERE sensor_id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if purge {
+			tx = tx.Unscoped()
+		}
+
+		return tx.Delete(&sensor).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.applyCacheChanges(ctx, changes)
+	return nil
+}
+
+// Reset deletes all groups, sensors and readings and drops the cached results, the generator
+// seeds new groups on the next start.
+func (s *Storage) Reset(ctx context.Context) error {
+	tables := []string{
+		SensorEventTable, AnomalyLabelTable, CurrentSensorFishTable, LatestReadingTable, FishTable, TemperatureTable, TransparencyTable, SensorTable, GroupTable,
+	}
+
+	err := s.db.WithContext(ctx).Exec("TRUNCATE " + strings.Join(tables, ", ") + " RESTART IDENTITY").Error
+	if err != nil {
+		return err
+	}
+
+	s.groupNames.Range(func(key, _ any) bool {
+		s.groupNames.Delete(key)
+		return true
+	})
+
+	for _, pattern := range cacheKeyPatterns {
+		iter := s.redis.Scan(ctx, 0, pattern, 0).Iterator()
+		for iter.Next(ctx) {
+			if err = s.redis.Del(ctx, iter.Val()).Err(); err != nil {
+				return err
+			}
+		}
+
+		if err = iter.Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -7,9 +7,10 @@ import (
 	"fmt"
 	"log"
 	"strconv"
-	"time"
+	"sync"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/jackc/pgx/v5"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -26,8 +27,12 @@ const (
 var ErrNoSensorsInArea = errors.New("no sensors in this area")
 
 type Storage struct {
-	db    *gorm.DB
-	redis *redis.Client
+	db     *gorm.DB
+	redis  *redis.Client
+	cache  cacheOptions
+	health healthOptions
+
+	groupNames sync.Map
 }
 
 func NewStorage(opts ...Option) (*Storage, error) {
@@ -48,19 +53,31 @@ func NewStorage(opts ...Option) (*Storage, error) {
 		}
 	}()
 
-	err = db.AutoMigrate(Fish{}, Group{}, Sensor{}, Transparency{}, Temperature{}, CurrentStatistic{}, CurrentSensorFish{})
-	if err != nil {
+	if err = registerErrorTranslation(db); err != nil {
 		return nil, err
 	}
 
+	if options.autoMigrate {
+		var applied []*Migration
+		if applied, err = migrateUp(context.Background(), db, 0); err != nil {
+			return nil, err
+		}
+
+		for _, m := range applied {
+			log.Printf("applied migration %s\n", m)
+		}
+	}
+
 	redisClient, err := connectToRedis(options)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Storage{
-		db:    db,
-		redis: redisClient,
+		db:     db,
+		redis:  redisClient,
+		cache:  options.cache,
+		health: options.health,
 	}, nil
 }
 
@@ -94,9 +111,26 @@ func (s *Storage) GetAllGroups() ([]*Group, error) {
 	return groups, nil
 }
 
-func (s *Storage) GetAllSensors() ([]*Sensor, error) {
+func (s *Storage) GetGroupByName(name string) (*Group, error) {
+	var group Group
+	res := s.db.Where("name = ?", name).Limit(1).Find(&group)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownGroup, name)
+	}
+
+	return &group, nil
+}
+
+// GetAllSensors returns the sensors matching the options ordered by id unless other order is
+// given, all sensors without options.
+func (s *Storage) GetAllSensors(opts ...SensorQueryOption) ([]*Sensor, error) {
+	q := newSensorQuery(opts)
+
 	var sensors []*Sensor
-	res := s.db.Find(&sensors)
+	res := q.page(q.filter(s.db.Model(&Sensor{}).Select(SensorTable + ".*"))).Find(&sensors)
 	if res.Error != nil {
 		return nil, res.Error
 	}
@@ -104,7 +138,33 @@ func (s *Storage) GetAllSensors() ([]*Sensor, error) {
 	return sensors, nil
 }
 
-func (s *Storage) GetCurrentSpecies(group string, limit int, opts ...ConditionOption) ([]*Fish, error) {
+// CountSensors returns the number of sensors matching the options regardless of the page.
+func (s *Storage) CountSensors(opts ...SensorQueryOption) (int64, error) {
+	q := newSensorQuery(opts)
+
+	var count int64
+	res := q.filter(s.db.Model(&Sensor{})).Count(&count)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+
+	return count, nil
+}
+
+func (s *Storage) GetCurrentFishes() ([]*Fish, error) {
+	var fishes []*Fish
+	res := s.db.Table(CurrentSensorFishTable).
+		Select(FishTable + ".*").
+		Joins("JOIN " + FishTable + " ON " + CurrentSensorFishTable + ".fish_id = " + FishTable + ".id").
+		Find(&fishes)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return fishes, nil
+}
+
+func (s *Storage) GetCurrentSpecies(ctx context.Context, group string, limit int, opts ...ConditionOption) ([]*Fish, error) {
 	resField := "count"
 	tx := s.db.Table(CurrentSensorFishTable).
 		Select(FishTable+".name, SUM("+FishTable+".count) as "+resField).
@@ -123,29 +183,29 @@ func (s *Storage) GetCurrentSpecies(group string, limit int, opts ...ConditionOp
 	}
 
 	var fishes []*Fish
-	if res := tx.Find(&fishes); res.Error != nil {
-		return nil, res.Error
+	err := s.cached(ctx, speciesKey, speciesGenerationKey+group, s.cache.speciesTTL, tx, &fishes, func() error {
+		return tx.Find(&fishes).Error
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return fishes, nil
 }
 
-func (s *Storage) GetMaxTemperatureByRegion(opts ...CoordinateOption) (float64, error) {
-	return s.getTemperatureByRegion(maxTemperature, opts...)
+func (s *Storage) GetMaxTemperatureByRegion(ctx context.Context, opts ...CoordinateOption) (float64, error) {
+	return s.getTemperatureByRegion(ctx, maxTemperature, opts...)
 }
 
-func (s *Storage) GetMinTemperatureByRegion(opts ...CoordinateOption) (float64, error) {
-	return s.getTemperatureByRegion(minTemperature, opts...)
+func (s *Storage) GetMinTemperatureByRegion(ctx context.Context, opts ...CoordinateOption) (float64, error) {
+	return s.getTemperatureByRegion(ctx, minTemperature, opts...)
 }
 
-func (s *Storage) GetSensorAvgTemperature(groupName string, indexInGroup int, condOpts ...ConditionOption) (float64, error) {
+func (s *Storage) GetSensorAvgTemperature(sensorId uint, condOpts ...ConditionOption) (float64, error) {
 	var avg float64
 	tx := s.db.Table(TemperatureTable).
 		Select("AVG("+TemperatureTable+".temperature) AS average_temp").
-		Joins("LEFT JOIN "+SensorTable+" ON "+TemperatureTable+".sensor_id = "+SensorTable+".id").
-		Joins("LEFT JOIN "+GroupTable+" ON "+SensorTable+".group_id = "+GroupTable+".id").
-		Where(GroupTable+".name = ?", groupName).
-		Where(SensorTable+".index_in_group = ?", indexInGroup)
+		Where(TemperatureTable+".sensor_id = ?", sensorId)
 
 	for _, opt := range condOpts {
 		opt(TemperatureTable, tx)
@@ -160,11 +220,11 @@ func (s *Storage) GetSensorAvgTemperature(groupName string, indexInGroup int, co
 }
 
 func (s *Storage) GetAvgTemperature(ctx context.Context, group string) (float64, error) {
-	return s.getAvg(ctx, group, temperatureKey, TemperatureTable, "temperature")
+	return s.getAvg(ctx, group, temperatureKey, "temperature")
 }
 
 func (s *Storage) GetAvgTransparency(ctx context.Context, group string) (uint8, error) {
-	avg, err := s.getAvg(ctx, group, transparencyKey, TransparencyTable, "transparency")
+	avg, err := s.getAvg(ctx, group, transparencyKey, "transparency")
 	return uint8(avg), err
 }
 
@@ -194,53 +254,59 @@ func (s *Storage) UpdateSensorData(sensor *Sensor, fishes []*Fish, temperature *
 		return tx.Error
 	}
 
-	if err := tx.Create(fishes).Error; err != nil {
+	changes := s.newCacheChanges()
+	if err := writeSensorData(tx, sensor, fishes, temperature, transparency, changes); err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	csfs := make([]*CurrentSensorFish, 0, len(fishes))
-	for _, fish := range fishes {
-		csfs = append(csfs, &CurrentSensorFish{
-			SensorId: sensor.ID,
-			FishId:   fish.ID,
-		})
-	}
-
-	if err := tx.Exec("DELETE FROM "+CurrentSensorFishTable+" WHERE sensor_id = ?", sensor.ID).Error; err != nil {
-		tx.Rollback()
+	if err := tx.Commit().Error; err != nil {
 		return err
 	}
 
-	if err := tx.Create(csfs).Error; err != nil {
-		tx.Rollback()
-		return err
+	s.applyCacheChanges(context.Background(), changes)
+	return nil
+}
+
+// writeSensorData saves the sensor readings and makes them current. Nil fishes, temperature or
+// transparency mean the sensor did not report it and the current value is kept.
+func writeSensorData(tx *gorm.DB, sensor *Sensor, fishes []*Fish, temperature *Temperature, transparency *Transparency, changes *cacheChanges) error {
+	if fishes != nil {
+		if err := writeCurrentFishes(tx, sensor, fishes, temperature, transparency); err != nil {
+			return err
+		}
+		changes.speciesChanged(uint(sensor.GroupId))
 	}
 
-	if err := tx.Create(temperature).Error; err != nil {
-		tx.Rollback()
-		return err
+	if temperature != nil {
+		if err := tx.Create(temperature).Error; err != nil {
+			return err
+		}
 	}
 
-	if err := tx.Create(transparency).Error; err != nil {
-		tx.Rollback()
-		return err
+	if transparency != nil {
+		if err := tx.Create(transparency).Error; err != nil {
+			return err
+		}
 	}
 
-	statistic := &CurrentStatistic{
-		GroupId:        uint(sensor.GroupId),
-		SensorId:       sensor.ID,
-		TransparencyId: transparency.ID,
-		TemperatureId:  temperature.ID,
+	if temperature == nil && transparency == nil {
+		return nil
 	}
 
-	if err := tx.Where("sensor_id = ?", sensor.ID).FirstOrCreate(statistic).Error; err != nil {
-		tx.Rollback()
-		return err
+	return upsertLatestReadings(tx, []*LatestReading{latestReading(sensor, temperature, transparency)}, changes)
+}
+
+func writeCurrentFishes(tx *gorm.DB, sensor *Sensor, fishes []*Fish, temperature *Temperature, transparency *Transparency) error {
+	if len(fishes) > 0 {
+		if err := tx.Create(fishes).Error; err != nil {
+			return err
+		}
 	}
 
-	tx.Commit()
-	return tx.Error
+	return replaceCurrentFishes(tx, map[uint]*fishObservation{
+		sensor.ID: {sensor: sensor, fishes: fishes, at: observedAt(fishes, temperature, transparency)},
+	})
 }
 
 func (s *Storage) InitSensorGroups(group *Group, sensors []*Sensor) error {
@@ -256,6 +322,7 @@ func (s *Storage) InitSensorGroups(group *Group, sensors []*Sensor) error {
 
 	for _, sensor := range sensors {
 		sensor.GroupId = uint64(group.ID)
+		sensor.CodeName = CodeName(group.Name, sensor.IndexInGroup)
 		if err := tx.Create(sensor).Error; err != nil {
 			tx.Rollback()
 			return err
@@ -266,7 +333,11 @@ func (s *Storage) InitSensorGroups(group *Group, sensors []*Sensor) error {
 	return tx.Error
 }
 
-func (s *Storage) getAvg(ctx context.Context, group, key, table, field string) (float64, error) {
+func (s *Storage) getAvg(ctx context.Context, group, key, field string) (float64, error) {
+	if s.cache.writeThrough {
+		return s.getIncrementalAvg(ctx, group, key, field)
+	}
+
 	redisKey := key + group
 
 	res := s.redis.Get(ctx, redisKey)
@@ -276,12 +347,12 @@ func (s *Storage) getAvg(ctx context.Context, group, key, table, field string) (
 		return strconv.ParseFloat(res.Val(), 64)
 	}
 
-	value, err := s.getAvgFromDb(group, table, field)
+	value, err := s.getAvgFromDb(group, field)
 	if err != nil {
 		return 0, err
 	}
 
-	err = s.redis.Set(ctx, redisKey, value, 10*time.Second).Err()
+	err = s.redis.Set(ctx, redisKey, value, s.cache.averageTTL).Err()
 	if err != nil {
 		log.Printf("Error setting value by key %s: %s", key, err)
 	}
@@ -289,17 +360,16 @@ func (s *Storage) getAvg(ctx context.Context, group, key, table, field string) (
 	return value, nil
 }
 
-func (s *Storage) getAvgFromDb(group, table, field string) (float64, error) {
+func (s *Storage) getAvgFromDb(group, field string) (float64, error) {
 	var avg sql.NullFloat64
-	res := s.db.Table(CurrentStatisticTable).
-		Select("AVG("+table+"."+field+")").
-		Joins("LEFT JOIN "+table+" ON "+CurrentStatisticTable+"."+field+"_id = "+table+".id").
-		Joins("LEFT JOIN "+GroupTable+" ON "+CurrentStatisticTable+".group_id = "+GroupTable+".id").
+	err := s.db.Table(LatestReadingTable).
+		Select("AVG("+LatestReadingTable+"."+field+")").
+		Joins("JOIN "+GroupTable+" ON "+LatestReadingTable+".group_id = "+GroupTable+".id").
 		Where(GroupTable+".name = ?", group).
-		Pluck("AVG(value)", &avg)
+		Row().Scan(&avg)
 
-	if res.Error != nil {
-		return 0, res.Error
+	if err != nil {
+		return 0, err
 	}
 
 	if !avg.Valid {
@@ -309,7 +379,19 @@ func (s *Storage) getAvgFromDb(group, table, field string) (float64, error) {
 	return avg.Float64, nil
 }
 
-func (s *Storage) getTemperatureByRegion(v uint8, opts ...CoordinateOption) (float64, error) {
+func (s *Storage) getSumFromDb(group, field string) (float64, int64, error) {
+	var sum sql.NullFloat64
+	var count int64
+	err := s.db.Table(LatestReadingTable).
+		Select("SUM("+LatestReadingTable+"."+field+"), COUNT("+LatestReadingTable+"."+field+")").
+		Joins("JOIN "+GroupTable+" ON "+LatestReadingTable+".group_id = "+GroupTable+".id").
+		Where(GroupTable+".name = ?", group).
+		Row().Scan(&sum, &count)
+
+	return sum.Float64, count, err
+}
+
+func (s *Storage) getTemperatureByRegion(ctx context.Context, v uint8, opts ...CoordinateOption) (float64, error) {
 	exp := ""
 	if v == minTemperature {
 		exp = "MIN"
@@ -319,26 +401,36 @@ func (s *Storage) getTemperatureByRegion(v uint8, opts ...CoordinateOption) (flo
 		return 0, errors.New("bad request")
 	}
 
-	var t sql.NullFloat64
-	tx := s.db.Table(CurrentStatisticTable).
-		Select(exp + "(" + TemperatureTable + ".temperature) as res").
-		Joins("LEFT JOIN " + TemperatureTable + " ON " + CurrentStatisticTable + ".temperature_id = " + TemperatureTable + ".id").
-		Joins("LEFT JOIN " + SensorTable + " ON " + CurrentStatisticTable + ".sensor_id = " + SensorTable + ".id")
+	tx := s.db.Table(LatestReadingTable).
+		Select(exp + "(" + LatestReadingTable + ".temperature) as res").
+		Joins("JOIN " + SensorTable + " ON " + LatestReadingTable + ".sensor_id = " + SensorTable + ".id")
 
 	for _, opt := range opts {
 		opt(tx)
 	}
 
-	err := tx.Row().Scan(&t)
+	var res *float64
+	err := s.cached(ctx, regionKey, regionGenerationKey, s.cache.regionTTL, tx, &res, func() error {
+		var t sql.NullFloat64
+		if err := tx.Row().Scan(&t); err != nil {
+			return err
+		}
+
+		if t.Valid {
+			res = &t.Float64
+		}
+		return nil
+	})
 	if err != nil {
 		return 0, err
-	} else if !t.Valid {
+	} else if res == nil {
 		return 0, ErrNoSensorsInArea
 	}
 
-	return t.Float64, nil
+	return *res, nil
 }
 
+// connectToDb connects to the database and creates it when it does not exist.
 func connectToDb(options *Options) (*gorm.DB, error) {
 	dsn := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s sslmode=disable",
@@ -347,28 +439,33 @@ func connectToDb(options *Options) (*gorm.DB, error) {
 		options.dbUser,
 		options.dbPassword,
 	)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
+
+	if err := createDb(dsn, options.dbName); err != nil {
 		return nil, err
 	}
-	defer func() {
-		db, _ := db.DB()
-		db.Close()
-	}()
 
-	var dbExists bool
-	db.Raw("SELECT EXISTS (SELECT datname FROM pg_database WHERE datname = ?)", options.dbName).Scan(&dbExists)
+	return gorm.Open(postgres.Open(dsn+" dbname="+options.dbName), &gorm.Config{})
+}
 
-	if !dbExists {
-		db.Exec("CREATE DATABASE " + options.dbName)
+func createDb(dsn, name string) error {
+	db, err := gorm.Open(postgres.Open(dsn+" dbname=postgres"), &gorm.Config{})
+	if err != nil {
+		return err
 	}
 
-	db, err = gorm.Open(postgres.Open(dsn+" dbname="+options.dbName), &gorm.Config{})
-	if err != nil {
-		panic("Failed to connect to the database")
+	defer func() {
+		sqlDb, err := db.DB()
+		if err == nil {
+			sqlDb.Close()
+		}
+	}()
+
+	var exists bool
+	if err = db.Raw("SELECT EXISTS (SELECT datname FROM pg_database WHERE datname = ?)", name).Scan(&exists).Error; err != nil || exists {
+		return err
 	}
 
-	return gorm.Open(postgres.Open(dsn+" dbname="+options.dbName), &gorm.Config{})
+	return db.Exec("CREATE DATABASE " + pgx.Identifier{name}.Sanitize()).Error
 }
 
 func connectToRedis(options *Options) (*redis.Client, error) {
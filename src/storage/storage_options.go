@@ -1,8 +1,15 @@
 package storage
 
+import "time"
+
 type Options struct {
 	redisAddress                               string
 	dbHost, dbUser, dbPassword, dbName, dbPort string
+
+	cache  cacheOptions
+	health healthOptions
+
+	autoMigrate bool
 }
 
 func DefaultOptions() *Options {
@@ -13,6 +20,16 @@ func DefaultOptions() *Options {
 		dbPassword:   "pswd",
 		dbName:       "sensor",
 		dbPort:       "5432",
+		cache: cacheOptions{
+			averageTTL: defaultCacheTTL,
+			regionTTL:  defaultCacheTTL,
+			speciesTTL: defaultCacheTTL,
+		},
+		health: healthOptions{
+			lateTolerance: defaultLateTolerance,
+			offlineAfter:  defaultOfflineAfter,
+		},
+		autoMigrate: true,
 	}
 }
 
@@ -65,3 +82,64 @@ func WithDbName(name string) Option {
 		}
 	}
 }
+
+// WithAverageCacheTTL sets how long group averages are cached.
+func WithAverageCacheTTL(ttl time.Duration) Option {
+	return func(opt *Options) {
+		if ttl > 0 {
+			opt.cache.averageTTL = ttl
+		}
+	}
+}
+
+// WithRegionCacheTTL sets how long regional min and max temperatures are cached.
+func WithRegionCacheTTL(ttl time.Duration) Option {
+	return func(opt *Options) {
+		if ttl > 0 {
+			opt.cache.regionTTL = ttl
+		}
+	}
+}
+
+// WithSpeciesCacheTTL sets how long species lists of groups are cached.
+func WithSpeciesCacheTTL(ttl time.Duration) Option {
+	return func(opt *Options) {
+		if ttl > 0 {
+			opt.cache.speciesTTL = ttl
+		}
+	}
+}
+
+// WithWriteThroughAverages keeps sums and counts of the latest readings of every group in the
+// cache and updates them on every write instead of recalculating the averages after expiry.
+func WithWriteThroughAverages(enabled bool) Option {
+	return func(opt *Options) {
+		opt.cache.writeThrough = enabled
+	}
+}
+
+// WithAutoMigrate sets whether the pending schema migrations are applied on connection.
+func WithAutoMigrate(enabled bool) Option {
+	return func(opt *Options) {
+		opt.autoMigrate = enabled
+	}
+}
+
+// WithLateTolerance sets the fraction of the data output rate a report may be delayed by before
+// the sensor is late.
+func WithLateTolerance(tolerance float64) Option {
+	return func(opt *Options) {
+		if tolerance > 0 {
+			opt.health.lateTolerance = tolerance
+		}
+	}
+}
+
+// WithOfflineAfter sets the number of missed reports a sensor is offline after.
+func WithOfflineAfter(missed int) Option {
+	return func(opt *Options) {
+		if missed > 0 {
+			opt.health.offlineAfter = missed
+		}
+	}
+}
@@ -2,12 +2,14 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
 )
 
 // TODO: tests are broken now
@@ -79,9 +81,11 @@ func (s *StorageTestSuite) SetupSuite() {
 }
 
 func (s *StorageTestSuite) TearDownSuite() {
-	s.storage.db.Migrator().DropTable(&Fish{}, &Group{}, &Sensor{}, &Temperature{}, &Transparency{})
+	_, err := s.storage.MigrateDown(context.TODO(), 0)
+	s.NoError(err, err)
+	s.storage.db.Migrator().DropTable(MigrationTable)
 
-	err := s.storage.Close()
+	err = s.storage.Close()
 	s.NoError(err, err)
 }
 
@@ -98,6 +102,10 @@ func (s *StorageTestSuite) TearDownTest() {
 	s.storage.db.Delete(&Sensor{})
 	s.storage.db.Delete(&Temperature{})
 	s.storage.db.Delete(&Transparency{})
+	s.storage.db.Exec("DELETE FROM " + CurrentSensorFishTable)
+	s.storage.db.Exec("DELETE FROM " + LatestReadingTable)
+	s.storage.db.Exec("DELETE FROM " + SensorEventTable)
+	s.storage.db.Exec("DELETE FROM " + AnomalyLabelTable)
 }
 
 func (s *StorageTestSuite) TestInitSensorGroups(t *testing.T) {
@@ -153,13 +161,13 @@ func (s *StorageTestSuite) TestGetSpecies() {
 	}
 
 	s.T().Run("GetAllSpecies", func(t *testing.T) {
-		species, err := s.storage.GetCurrentSpecies(group.Name, 0)
+		species, err := s.storage.GetCurrentSpecies(context.TODO(), group.Name, 0)
 		s.Require().NoError(err, err)
 		s.Equal(len(fishes), len(species))
 	})
 
 	s.T().Run("GetNSpecies", func(t *testing.T) {
-		species, err := s.storage.GetCurrentSpecies(group.Name, 1)
+		species, err := s.storage.GetCurrentSpecies(context.TODO(), group.Name, 1)
 		s.Require().NoError(err, err)
 		s.Equal(1, len(species))
 	})
@@ -185,7 +193,7 @@ func (s *StorageTestSuite) TestGetSpecies() {
 		err = s.storage.CreateFish(fish)
 		s.Require().NoError(err, err)
 
-		species, err := s.storage.GetCurrentSpecies(group.Name, 0, WithCreatedFrom(from), WithCreatedTill(till))
+		species, err := s.storage.GetCurrentSpecies(context.TODO(), group.Name, 0, WithCreatedFrom(from), WithCreatedTill(till))
 		s.Require().NoError(err, err)
 		s.Require().Equal(1, len(species))
 		assertFish(t, expFish, species[0])
@@ -193,7 +201,6 @@ func (s *StorageTestSuite) TestGetSpecies() {
 }
 
 func (s *StorageTestSuite) TestGetSensorAvgTemperature() {
-	group := s.testSensorGroups[0].group
 	sensor := s.testSensorGroups[0].sensors[0]
 
 	temperatures := []*Temperature{
@@ -226,7 +233,7 @@ func (s *StorageTestSuite) TestGetSensorAvgTemperature() {
 	}
 	expT /= float64(len(temperatures) - 1)
 
-	gotT, err := s.storage.GetSensorAvgTemperature(group.Name, int(sensor.IndexInGroup))
+	gotT, err := s.storage.GetSensorAvgTemperature(sensor.ID)
 	s.NoError(err, err)
 	s.Equal(expT, gotT)
 }
@@ -270,6 +277,664 @@ func (s *StorageTestSuite) TestGetAvgTransparency() {
 	s.Equal(expT, gotT)
 }
 
+func (s *StorageTestSuite) TestLatestReadings() {
+	group := s.testSensorGroups[1].group
+	sensors := s.testSensorGroups[1].sensors
+	now := time.Now()
+
+	update := func(sensor *Sensor, temperature float64, transparency uint8, at time.Time) {
+		err := s.storage.UpdateSensorData(
+			sensor,
+			nil,
+			&Temperature{Model: gorm.Model{CreatedAt: at}, SensorId: uint64(sensor.ID), Temperature: temperature},
+			&Transparency{Model: gorm.Model{CreatedAt: at}, SensorId: uint64(sensor.ID), Transparency: transparency},
+		)
+		s.Require().NoError(err, err)
+	}
+
+	assertAvg := func(expTemperature, expTransparency float64) {
+		temperature, err := s.storage.getAvgFromDb(group.Name, "temperature")
+		s.Require().NoError(err, err)
+		s.Equal(expTemperature, temperature)
+
+		transparency, err := s.storage.getAvgFromDb(group.Name, "transparency")
+		s.Require().NoError(err, err)
+		s.Equal(expTransparency, transparency)
+	}
+
+	update(sensors[0], 10, 20, now.Add(-time.Minute))
+	update(sensors[1], 20, 40, now.Add(-time.Minute))
+	assertAvg(15, 30)
+
+	s.Run("NewReadingMovesAverage", func() {
+		update(sensors[0], 30, 60, now)
+		assertAvg(25, 50)
+	})
+
+	s.Run("OlderReadingIsIgnored", func() {
+		update(sensors[1], 0, 0, now.Add(-time.Hour))
+		assertAvg(25, 50)
+	})
+
+	s.Run("NewestBatchedReadingWins", func() {
+		updates := make([]*SensorUpdate, 0, 2)
+		for i, t := range []float64{50, 40} {
+			at := now.Add(time.Duration(-i) * time.Second)
+			updates = append(updates, &SensorUpdate{
+				Sensor:      sensors[1],
+				Temperature: &Temperature{Model: gorm.Model{CreatedAt: at}, SensorId: uint64(sensors[1].ID), Temperature: t},
+			})
+		}
+
+		err := s.storage.WriteSensorUpdates(updates)
+		s.Require().NoError(err, err)
+		assertAvg(40, 50)
+	})
+
+	s.Run("RegionUsesLatestReadings", func() {
+		maxT, err := s.storage.GetMaxTemperatureByRegion(context.TODO(), WithXMin(sensors[1].X), WithXMax(sensors[1].X))
+		s.Require().NoError(err, err)
+		s.Equal(float64(50), maxT)
+
+		minT, err := s.storage.GetMinTemperatureByRegion(context.TODO(), WithXMin(sensors[0].X), WithXMax(sensors[0].X))
+		s.Require().NoError(err, err)
+		s.Equal(float64(30), minT)
+	})
+}
+
+func (s *StorageTestSuite) TestIngestReadings() {
+	sensor := s.testSensorGroups[0].sensors[0]
+	now := time.Now().Truncate(time.Second)
+	temperature := func(t float64) *float64 { return &t }
+
+	currentFishes := func() map[string]uint64 {
+		fishes, err := s.storage.GetCurrentFishes()
+		s.Require().NoError(err, err)
+
+		current := make(map[string]uint64)
+		for _, fish := range fishes {
+			if fish.SensorId == uint64(sensor.ID) {
+				current[fish.Name] = fish.Count
+			}
+		}
+		return current
+	}
+
+	s.Run("Validation", func() {
+		res, err := s.storage.IngestReadings([]*Reading{
+			{Timestamp: now, Temperature: temperature(1)},
+			{SensorId: uint64(sensor.ID) + 1000, Timestamp: now, Temperature: temperature(1)},
+			{CodeName: "unknown", Timestamp: now, Temperature: temperature(1)},
+			{SensorId: uint64(sensor.ID), Temperature: temperature(1)},
+			{SensorId: uint64(sensor.ID), Timestamp: now.Add(time.Hour), Temperature: temperature(1)},
+			{SensorId: uint64(sensor.ID), Timestamp: now},
+			{SensorId: uint64(sensor.ID), Timestamp: now, Temperature: temperature(MaxValidTemperature + 1)},
+			{SensorId: uint64(sensor.ID), Timestamp: now, Fishes: map[string]uint64{"": 1}},
+		})
+		s.Require().NoError(err, err)
+		s.Zero(res.Accepted)
+		s.Require().Len(res.Rejected, 8)
+		for i, rejected := range res.Rejected {
+			s.Equal(i, rejected.Index)
+		}
+		s.ErrorIs(res.Rejected[1].Err, ErrSensorNotFound)
+		s.ErrorIs(res.Rejected[4].Err, ErrBadReading)
+	})
+
+	s.Run("Duplicates", func() {
+		reading := &Reading{CodeName: sensor.CodeName, Timestamp: now.Add(-time.Hour), Temperature: temperature(10)}
+
+		res, err := s.storage.IngestReadings([]*Reading{reading, reading})
+		s.Require().NoError(err, err)
+		s.Equal(1, res.Accepted)
+		s.Equal(1, res.Duplicates, "repeated in the batch")
+
+		res, err = s.storage.IngestReadings([]*Reading{reading})
+		s.Require().NoError(err, err)
+		s.Zero(res.Accepted)
+		s.Equal(1, res.Duplicates, "already saved")
+	})
+
+	s.Run("LargeBatch", func() {
+		// More readings than the bind parameters of a single statement allow.
+		readings := make([]*Reading, 12*insertBatchSize)
+		for i := range readings {
+			readings[i] = &Reading{SensorId: uint64(sensor.ID), Timestamp: now.Add(-24*time.Hour - time.Duration(i)*time.Second), Temperature: temperature(10)}
+		}
+
+		res, err := s.storage.IngestReadings(readings)
+		s.Require().NoError(err, err)
+		s.Equal(len(readings), res.Accepted)
+
+		res, err = s.storage.IngestReadings(readings)
+		s.Require().NoError(err, err)
+		s.Equal(len(readings), res.Duplicates)
+	})
+
+	s.Run("NewestFishesAreCurrent", func() {
+		res, err := s.storage.IngestReadings([]*Reading{
+			{SensorId: uint64(sensor.ID), Timestamp: now.Add(-time.Minute), Fishes: map[string]uint64{"perch": 3}},
+		})
+		s.Require().NoError(err, err)
+		s.Equal(1, res.Accepted)
+		s.Equal(map[string]uint64{"perch": 3}, currentFishes())
+
+		res, err = s.storage.IngestReadings([]*Reading{
+			{SensorId: uint64(sensor.ID), Timestamp: now.Add(-time.Hour), Fishes: map[string]uint64{"pike": 1}},
+		})
+		s.Require().NoError(err, err)
+		s.Equal(1, res.Accepted, "late readings are saved")
+		s.Equal(map[string]uint64{"perch": 3}, currentFishes(), "late readings do not replace newer fishes")
+
+		err = s.storage.WriteSensorUpdates([]*SensorUpdate{{
+			Sensor: sensor,
+			Fishes: []*Fish{{Model: gorm.Model{CreatedAt: now.Add(-2 * time.Hour)}, SensorId: uint64(sensor.ID), Name: "carp", Count: 2}},
+		}})
+		s.Require().NoError(err, err)
+		s.Equal(map[string]uint64{"perch": 3}, currentFishes(), "late batched readings do not replace newer fishes")
+
+		res, err = s.storage.IngestReadings([]*Reading{
+			{SensorId: uint64(sensor.ID), Timestamp: now, Fishes: map[string]uint64{"pike": 2}},
+		})
+		s.Require().NoError(err, err)
+		s.Equal(map[string]uint64{"pike": 2}, currentFishes())
+	})
+}
+
+func (s *StorageTestSuite) TestAverageCache() {
+	group := s.testSensorGroups[0].group
+	sensor := s.testSensorGroups[0].sensors[0]
+
+	update := func(temperature float64) {
+		err := s.storage.UpdateSensorData(sensor, nil, &Temperature{SensorId: uint64(sensor.ID), Temperature: temperature}, nil)
+		s.Require().NoError(err, err)
+	}
+
+	for _, writeThrough := range []bool{false, true} {
+		s.storage.cache.writeThrough = writeThrough
+		s.storage.redis.Del(context.TODO(), temperatureKey+group.Name, temperatureKey+averageStateKey+group.Name)
+
+		update(10)
+		avg, err := s.storage.GetAvgTemperature(context.TODO(), group.Name)
+		s.Require().NoError(err, err)
+		s.Equal(float64(10), avg)
+
+		update(20)
+		avg, err = s.storage.GetAvgTemperature(context.TODO(), group.Name)
+		s.Require().NoError(err, err)
+		s.Equal(float64(20), avg)
+	}
+
+	s.storage.cache.writeThrough = false
+}
+
+func (s *StorageTestSuite) TestAddRemoveSensor() {
+	group := s.testSensorGroups[0].group
+
+	sensor := &Sensor{X: 7, Y: 8, Z: 9, DataOutputRate: time.Minute}
+	s.Require().NoError(s.storage.AddSensor(group.Name, sensor))
+	s.Equal(uint64(group.ID), sensor.GroupId)
+	s.Equal(uint64(3), sensor.IndexInGroup, "the index follows the last one in the group")
+
+	err := s.storage.AddSensor("unknown", &Sensor{})
+	s.ErrorIs(err, ErrUnknownGroup)
+
+	err = s.storage.UpdateSensorData(sensor, nil, &Temperature{SensorId: uint64(sensor.ID), Temperature: 100}, nil)
+	s.Require().NoError(err, err)
+
+	avg, err := s.storage.getAvgFromDb(group.Name, "temperature")
+	s.Require().NoError(err, err)
+	s.Equal(float64(100), avg)
+
+	s.Require().NoError(s.storage.RemoveSensor(context.TODO(), sensor.ID, false))
+	_, err = s.storage.getAvgFromDb(group.Name, "temperature")
+	s.Error(err, "removed sensor does not count in the group averages")
+
+	err = s.storage.RemoveSensor(context.TODO(), sensor.ID, false)
+	s.ErrorIs(err, ErrUnknownSensor)
+
+	next := &Sensor{DataOutputRate: time.Minute}
+	s.Require().NoError(s.storage.AddSensor(group.Name, next))
+	s.Equal(uint64(4), next.IndexInGroup, "indexes of removed sensors are not reused")
+}
+
+func (s *StorageTestSuite) TestConstraints() {
+	group := s.testSensorGroups[0].group
+	sensor := s.testSensorGroups[0].sensors[0]
+
+	err := s.storage.CreateGroup(&Group{Name: group.Name})
+	s.ErrorIs(err, ErrDuplicate)
+
+	err = s.storage.CreateSensor(&Sensor{GroupId: uint64(group.ID), IndexInGroup: sensor.IndexInGroup, DataOutputRate: time.Second})
+	s.ErrorIs(err, ErrDuplicate)
+
+	err = s.storage.CreateSensor(&Sensor{GroupId: uint64(group.ID) + 1000, DataOutputRate: time.Second})
+	s.ErrorIs(err, ErrMissingReference)
+
+	err = s.storage.CreateSensor(&Sensor{GroupId: uint64(group.ID), IndexInGroup: 100, CodeName: sensor.CodeName, DataOutputRate: time.Second})
+	s.ErrorIs(err, ErrDuplicate, "code names are unique")
+
+	err = s.storage.CreateTemperature(&Temperature{SensorId: uint64(sensor.ID) + 1000, Temperature: 10})
+	s.ErrorIs(err, ErrMissingReference)
+
+	err = s.storage.CreateTransparency(&Transparency{SensorId: uint64(sensor.ID), Transparency: 101})
+	s.ErrorIs(err, ErrInvalidData)
+
+	added := &Sensor{DataOutputRate: time.Minute}
+	s.Require().NoError(s.storage.AddSensor(group.Name, added))
+	s.Require().NoError(s.storage.CreateTemperature(&Temperature{SensorId: uint64(added.ID), Temperature: 10}))
+	s.Require().NoError(s.storage.RemoveSensor(context.TODO(), added.ID, true))
+
+	var count int64
+	s.Require().NoError(s.storage.db.Unscoped().Model(&Temperature{}).Where("sensor_id = ?", added.ID).Count(&count).Error)
+	s.Zero(count, "purging a sensor deletes its readings")
+}
+
+func (s *StorageTestSuite) TestGetSensor() {
+	group := s.testSensorGroups[0].group
+	sensor := s.testSensorGroups[0].sensors[1]
+	s.Require().Equal(CodeName(group.Name, sensor.IndexInGroup), sensor.CodeName)
+	s.Require().NotZero(sensor.UUID)
+
+	byCodeName, err := s.storage.GetSensor(sensor.CodeName)
+	s.Require().NoError(err, err)
+	s.Equal(sensor.ID, byCodeName.ID)
+
+	byUUID, err := s.storage.GetSensor(sensor.UUID.String())
+	s.Require().NoError(err, err)
+	s.Equal(sensor.ID, byUUID.ID)
+
+	byIndex, err := s.storage.GetSensorByIndex(group.Name, sensor.IndexInGroup)
+	s.Require().NoError(err, err)
+	s.Equal(sensor.ID, byIndex.ID)
+
+	_, err = s.storage.GetSensor(group.Name + "100")
+	s.ErrorIs(err, ErrUnknownSensor)
+
+	_, err = s.storage.GetSensor("alpha01")
+	s.ErrorIs(err, ErrBadCodeName)
+}
+
+func (s *StorageTestSuite) TestGetAllSensorsOptions() {
+	group := s.testSensorGroups[0].group
+	first, second := s.testSensorGroups[0].sensors[0], s.testSensorGroups[0].sensors[1]
+
+	sensors, err := s.storage.GetAllSensors(WithSensorGroup(group.Name), WithSensorSort(SortByX, true))
+	s.Require().NoError(err, err)
+	s.Require().Len(sensors, 2)
+	s.Equal(second.ID, sensors[0].ID)
+	s.Equal(first.ID, sensors[1].ID)
+
+	sensors, err = s.storage.GetAllSensors(WithSensorRegion(WithXMin(3)), WithSensorSort(SortByCodeName, false), WithSensorPage(1, 1))
+	s.Require().NoError(err, err)
+	s.Require().Len(sensors, 1)
+	s.Equal(s.testSensorGroups[1].sensors[1].ID, sensors[0].ID)
+
+	count, err := s.storage.CountSensors(WithSensorRegion(WithXMin(3)), WithSensorPage(1, 1))
+	s.Require().NoError(err, err)
+	s.Equal(int64(2), count, "the page does not limit the count")
+
+	err = s.storage.UpdateSensorData(first, nil, &Temperature{SensorId: uint64(first.ID), Temperature: 5}, nil)
+	s.Require().NoError(err, err)
+
+	sensors, err = s.storage.GetAllSensors(WithSensorGroup(group.Name), WithSensorSort(SortByLastReading, false))
+	s.Require().NoError(err, err)
+	s.Require().Len(sensors, 2)
+	s.Equal(first.ID, sensors[0].ID, "sensors which never reported come last")
+
+	latest, err := s.storage.GetLatestReadings([]uint{first.ID, second.ID})
+	s.Require().NoError(err, err)
+	s.Require().Contains(latest, first.ID)
+	s.NotContains(latest, second.ID)
+	s.Equal(float64(5), *latest[first.ID].Temperature)
+
+	tenth := &Sensor{GroupId: uint64(group.ID), IndexInGroup: 10, DataOutputRate: time.Second}
+	s.Require().NoError(s.storage.CreateSensor(tenth))
+	sensors, err = s.storage.GetAllSensors(WithSensorGroup(group.Name), WithSensorSort(SortByCodeName, false))
+	s.Require().NoError(err, err)
+	s.Require().Len(sensors, 3)
+	s.Equal([]uint{first.ID, second.ID, tenth.ID}, []uint{sensors[0].ID, sensors[1].ID, sensors[2].ID}, "code names are ordered by the index")
+
+	_, err = s.storage.GetGroupByName("unknown")
+	s.ErrorIs(err, ErrUnknownGroup)
+}
+
+func (s *StorageTestSuite) TestHealthTransitions() {
+	group := s.testSensorGroups[0].group
+	sensor := s.testSensorGroups[0].sensors[0]
+	later := time.Now().Add(time.Hour)
+
+	err := s.storage.UpdateSensorData(sensor, nil, &Temperature{SensorId: uint64(sensor.ID), Temperature: 5}, nil)
+	s.Require().NoError(err, err)
+
+	events, err := s.storage.RecordHealthTransitions(context.TODO(), time.Now())
+	s.Require().NoError(err, err)
+	s.Empty(events, "new sensors are online")
+
+	events, err = s.storage.RecordHealthTransitions(context.TODO(), later)
+	s.Require().NoError(err, err)
+	s.NotEmpty(events)
+	for _, event := range events {
+		s.Equal(StatusOnline, event.FromStatus)
+		s.Equal(StatusOffline, event.ToStatus)
+	}
+
+	events, err = s.storage.RecordHealthTransitions(context.TODO(), later)
+	s.Require().NoError(err, err)
+	s.Empty(events, "unchanged statuses are not recorded again")
+
+	recorded, err := s.storage.GetSensorEvents(sensor.ID)
+	s.Require().NoError(err, err)
+	s.Require().Len(recorded, 1)
+	s.Equal(StatusOffline, recorded[0].ToStatus)
+	s.Require().NotNil(recorded[0].LastSeenAt, "the sensor reported before it went quiet")
+
+	groups, err := s.storage.GetGroupsHealth(later)
+	s.Require().NoError(err, err)
+	for _, g := range groups {
+		if g.Group == group.Name {
+			s.Equal(0, g.Online)
+			s.Equal(len(s.testSensorGroups[0].sensors), g.Offline)
+		}
+	}
+}
+
+func (s *StorageTestSuite) TestHardwareUpdates() {
+	sensor, err := s.storage.GetSensorByCodeName(s.testSensorGroups[0].sensors[0].CodeName)
+	s.Require().NoError(err, err)
+	s.Equal(FullBattery, sensor.Battery, "new sensors have a full battery")
+
+	failed := time.Now().Truncate(time.Second)
+	err = s.storage.WriteSensorUpdates([]*SensorUpdate{
+		{
+			Sensor:      sensor,
+			Temperature: &Temperature{SensorId: uint64(sensor.ID), Temperature: 5},
+			Hardware:    &SensorHardware{SensorId: sensor.ID, Battery: 10},
+		},
+		{
+			Sensor:   sensor,
+			Hardware: &SensorHardware{SensorId: sensor.ID, Battery: 9.5, FailedAt: &failed},
+		},
+	})
+	s.Require().NoError(err, err)
+
+	saved, err := s.storage.GetSensorByCodeName(sensor.CodeName)
+	s.Require().NoError(err, err)
+	s.Equal(9.5, saved.Battery, "the last state wins")
+	s.Require().NotNil(saved.FailedAt)
+	s.True(failed.Equal(*saved.FailedAt))
+	s.Equal(HardwareFailed, saved.HardwareStatus())
+
+	err = s.storage.WriteSensorUpdates([]*SensorUpdate{
+		{Sensor: sensor, Hardware: &SensorHardware{SensorId: sensor.ID, Battery: 120}},
+	})
+	s.ErrorIs(err, ErrInvalidData)
+}
+
+func (s *StorageTestSuite) TestCalibration() {
+	group := s.testSensorGroups[0].group
+	calibration := Calibration{Offset: 0.5, Gain: 1.01, NoiseStd: 0.3, Resolution: 0.1, Unit: Fahrenheit}
+	sensor := &Sensor{IndexInGroup: 100, DataOutputRate: time.Minute, Calibration: calibration}
+	s.Require().NoError(s.storage.AddSensor(group.Name, sensor))
+
+	saved, err := s.storage.GetSensorByCodeName(sensor.CodeName)
+	s.Require().NoError(err, err)
+	s.Equal(calibration, saved.Calibration)
+
+	trueT := 20.0
+	err = s.storage.WriteSensorUpdates([]*SensorUpdate{{
+		Sensor:      saved,
+		Temperature: &Temperature{SensorId: uint64(saved.ID), Temperature: 20.6, TrueTemperature: &trueT},
+	}})
+	s.Require().NoError(err, err)
+
+	var records []*ReadingRecord
+	err = s.storage.StreamReadings(context.TODO(), &ExportFilter{SensorId: saved.ID}, func(r *ReadingRecord) error {
+		records = append(records, r)
+		return nil
+	})
+	s.Require().NoError(err, err)
+	s.Require().Len(records, 1)
+	s.Equal(20.6, records[0].Value)
+	s.Require().NotNil(records[0].TrueValue)
+	s.Equal(trueT, *records[0].TrueValue)
+
+	s.Error(s.storage.AddSensor(group.Name, &Sensor{IndexInGroup: 101, DataOutputRate: time.Minute,
+		Calibration: Calibration{Gain: 1, Unit: "rankine"}}), "the unit is checked")
+}
+
+func (s *StorageTestSuite) TestAnomalyLabels() {
+	sensor := s.testSensorGroups[0].sensors[0]
+	at := time.Now().Truncate(time.Second)
+
+	err := s.storage.WriteSensorUpdates([]*SensorUpdate{{
+		Sensor:      sensor,
+		Temperature: &Temperature{Model: gorm.Model{CreatedAt: at}, SensorId: uint64(sensor.ID), Temperature: 30},
+		Labels: []*AnomalyLabel{
+			{CreatedAt: at, EndedAt: at, SensorId: sensor.ID, Kind: AnomalySpike, Metric: TemperatureMetric, Magnitude: 6},
+			{CreatedAt: at.Add(time.Hour), EndedAt: at.Add(2 * time.Hour), SensorId: sensor.ID, Kind: AnomalyDropout, Magnitude: 5},
+		},
+	}})
+	s.Require().NoError(err, err)
+
+	labels, err := s.storage.GetAnomalyLabels(WithLabelSensor(sensor.ID))
+	s.Require().NoError(err, err)
+	s.Require().Len(labels, 2)
+	s.Equal(AnomalySpike, labels[0].Kind)
+	s.Equal(sensor.CodeName, labels[0].SensorCodeName)
+
+	labels, err = s.storage.GetAnomalyLabels(WithLabelsBetween(at.Add(90*time.Minute), time.Time{}))
+	s.Require().NoError(err, err)
+	s.Require().Len(labels, 1, "labels overlapping the period")
+	s.Equal(AnomalyDropout, labels[0].Kind)
+
+	labels, err = s.storage.GetAnomalyLabels(WithLabelKind(AnomalySpike), WithLabelsBetween(time.Time{}, at.Add(-time.Second)))
+	s.Require().NoError(err, err)
+	s.Empty(labels)
+
+	var records []*ReadingRecord
+	err = s.storage.StreamReadings(context.TODO(), &ExportFilter{SensorId: sensor.ID}, func(r *ReadingRecord) error {
+		records = append(records, r)
+		return nil
+	})
+	s.Require().NoError(err, err)
+	s.Require().Len(records, 1)
+	s.Equal(string(AnomalySpike), records[0].Labels, "the reading is labelled")
+}
+
+func (s *StorageTestSuite) TestReadingsAfter() {
+	sensor := s.testSensorGroups[0].sensors[0]
+
+	cursor, err := s.storage.GetReadingCursor(context.TODO())
+	s.Require().NoError(err, err)
+
+	err = s.storage.WriteSensorUpdates([]*SensorUpdate{{
+		Sensor:       sensor,
+		Temperature:  &Temperature{SensorId: uint64(sensor.ID), Temperature: 12.5},
+		Transparency: &Transparency{SensorId: uint64(sensor.ID), Transparency: 40},
+	}})
+	s.Require().NoError(err, err)
+
+	start := cursor
+	readings, err := s.storage.GetReadingsAfter(context.TODO(), &cursor, 10)
+	s.Require().NoError(err, err)
+	s.Require().Len(readings, 2)
+	metrics := []string{readings[0].Metric, readings[1].Metric}
+	s.ElementsMatch([]string{TemperatureMetric, TransparencyMetric}, metrics)
+	s.Equal(uint64(sensor.ID), readings[0].SensorId)
+	s.Greater(cursor.Temperature.Last, start.Temperature.Last)
+	s.Greater(cursor.Transparency.Last, start.Transparency.Last)
+
+	next := cursor
+	readings, err = s.storage.GetReadingsAfter(context.TODO(), &cursor, 10)
+	s.Require().NoError(err, err)
+	s.Empty(readings, "the readings are read once")
+	s.Equal(next, cursor)
+
+	s.Run("LateCommit", func() {
+		// The temperature was skipped as if its transaction committed after a later reading,
+		// the id 0 is never used, so that gap is given up once it times out.
+		id := cursor.Temperature.Last
+		late := ReadingCursor{
+			Temperature: MetricCursor{Last: id, Gaps: map[uint]time.Time{
+				id: time.Now(),
+				0:  time.Now().Add(-readingGapTimeout),
+			}},
+			Transparency: cursor.Transparency,
+		}
+
+		readings, err := s.storage.GetReadingsAfter(context.TODO(), &late, 10)
+		s.Require().NoError(err, err)
+		s.Require().Len(readings, 1)
+		s.Equal(id, readings[0].ID)
+		s.Equal(TemperatureMetric, readings[0].Metric)
+		s.Empty(late.Temperature.Gaps)
+	})
+}
+
+func (s *StorageTestSuite) TestStreamReadingsBatches() {
+	sensor := s.testSensorGroups[0].sensors[0]
+	at := time.Now().Add(-time.Hour)
+
+	transparencies := make([]*Transparency, exportFetchSize+1)
+	for i := range transparencies {
+		transparencies[i] = &Transparency{SensorId: uint64(sensor.ID), Transparency: uint8(i % 100)}
+		transparencies[i].CreatedAt = at.Add(time.Duration(i) * time.Millisecond)
+	}
+	s.Require().NoError(s.storage.db.Create(&transparencies).Error)
+
+	filter := &ExportFilter{SensorId: sensor.ID, Conditions: []ConditionOption{WithCreatedFrom(at)}}
+	var count int
+	var previous time.Time
+	err := s.storage.StreamReadings(context.TODO(), filter, func(r *ReadingRecord) error {
+		s.False(r.CreatedAt.Before(previous), "the readings are ordered by time")
+		previous = r.CreatedAt
+		count++
+		return nil
+	})
+	s.Require().NoError(err, err)
+	s.Equal(len(transparencies), count, "every batch is fetched")
+
+	errStop := errors.New("stop")
+	err = s.storage.StreamReadings(context.TODO(), filter, func(*ReadingRecord) error {
+		return errStop
+	})
+	s.ErrorIs(err, errStop)
+}
+
+func (s *StorageTestSuite) TestBatchWriterDropsInvalidUpdates() {
+	sensor := s.testSensorGroups[0].sensors[0]
+	missing := &Sensor{Model: gorm.Model{ID: sensor.ID + 1000}, GroupId: sensor.GroupId}
+
+	writer := s.storage.NewBatchWriter(WithFlushInterval(time.Hour))
+	for _, sn := range []*Sensor{sensor, missing, sensor} {
+		err := writer.Write(&SensorUpdate{Sensor: sn, Temperature: &Temperature{SensorId: uint64(sn.ID), Temperature: 10}})
+		s.Require().NoError(err, err)
+	}
+
+	err := writer.Flush(context.TODO())
+	s.ErrorIs(err, ErrUpdatesDropped)
+	s.ErrorContains(err, "1 rejected")
+	s.NoError(writer.Close(), "the dropped updates are reported once")
+
+	var saved int64
+	s.Require().NoError(s.storage.db.Model(&Temperature{}).Where("sensor_id = ?", sensor.ID).Count(&saved).Error)
+	s.Equal(int64(2), saved, "only the invalid update is dropped")
+}
+
+func (s *StorageTestSuite) TestMigrations() {
+	migrations, err := s.storage.Migrations(context.TODO())
+	s.Require().NoError(err, err)
+	s.Require().NotEmpty(migrations)
+	for _, m := range migrations {
+		s.NotNil(m.AppliedAt, "migration %s is applied on connection", m)
+	}
+
+	last := migrations[len(migrations)-1]
+	rolledBack, err := s.storage.MigrateDown(context.TODO(), migrations[len(migrations)-2].Version)
+	s.Require().NoError(err, err)
+	s.Require().Len(rolledBack, 1)
+	s.Equal(last.Version, rolledBack[0].Version)
+
+	applied, err := s.storage.MigrateUp(context.TODO(), 0)
+	s.Require().NoError(err, err)
+	s.Require().Len(applied, 1)
+	s.Equal(last.Version, applied[0].Version)
+
+	_, err = s.storage.MigrateUp(context.TODO(), last.Version+1000)
+	s.ErrorIs(err, ErrUnknownMigrationTarget)
+}
+
+func (s *StorageTestSuite) TestMigrationsRoundTrip() {
+	const currentFishesVersion = 10
+	sensor := s.testSensorGroups[0].sensors[0]
+
+	err := s.storage.WriteSensorUpdates([]*SensorUpdate{{
+		Sensor: sensor,
+		Fishes: []*Fish{{SensorId: uint64(sensor.ID), Name: "perch", Count: 3}},
+	}})
+	s.Require().NoError(err, err)
+
+	migrations, err := s.storage.Migrations(context.TODO())
+	s.Require().NoError(err, err)
+	last := migrations[len(migrations)-1].Version
+
+	s.Run("Backfill", func() {
+		_, err := s.storage.MigrateDown(context.TODO(), currentFishesVersion-1)
+		s.Require().NoError(err, err)
+		_, err = s.storage.MigrateUp(context.TODO(), currentFishesVersion)
+		s.Require().NoError(err, err)
+
+		var latest LatestReading
+		res := s.storage.db.Table(LatestReadingTable).Where("sensor_id = ?", sensor.ID).Take(&latest)
+		s.Require().NoError(res.Error, res.Error)
+		s.NotNil(latest.FishesAt, "the time of the current fish is backfilled")
+
+		_, err = s.storage.MigrateUp(context.TODO(), 0)
+		s.Require().NoError(err, err)
+	})
+
+	s.Run("EveryVersion", func() {
+		for v := last; v > 0; v-- {
+			rolledBack, err := s.storage.MigrateDown(context.TODO(), v-1)
+			s.Require().NoError(err, "migration %d rolls back: %s", v, err)
+			s.Require().Len(rolledBack, 1)
+		}
+
+		for v := uint(1); v <= last; v++ {
+			applied, err := s.storage.MigrateUp(context.TODO(), v)
+			s.Require().NoError(err, "migration %d applies: %s", v, err)
+			s.Require().Len(applied, 1)
+		}
+	})
+}
+
+func (s *StorageTestSuite) TestConstraintsMigrationRefusesOrphans() {
+	const constraintsVersion = 4
+	sensor := s.testSensorGroups[0].sensors[0]
+
+	_, err := s.storage.MigrateDown(context.TODO(), constraintsVersion-1)
+	s.Require().NoError(err, err)
+	defer func() {
+		_, err := s.storage.MigrateUp(context.TODO(), 0)
+		s.Require().NoError(err, err)
+	}()
+
+	orphan := uint64(sensor.ID) + 1000
+	res := s.storage.db.Exec("INSERT INTO "+TemperatureTable+" (created_at, updated_at, sensor_id, temperature) VALUES (NOW(), NOW(), ?, 10)", orphan)
+	s.Require().NoError(res.Error, res.Error)
+
+	_, err = s.storage.MigrateUp(context.TODO(), 0)
+	s.ErrorContains(err, "orphaned records")
+	s.ErrorContains(err, "1 temperatures")
+
+	var count int64
+	s.Require().NoError(s.storage.db.Table(TemperatureTable).Where("sensor_id = ?", orphan).Count(&count).Error)
+	s.Equal(int64(1), count, "orphans are not deleted")
+
+	s.Require().NoError(s.storage.db.Exec("DELETE FROM "+TemperatureTable+" WHERE sensor_id = ?", orphan).Error)
+}
+
 func connectToTestDb() (*Storage, error) {
 	return NewStorage(
 		WithDbUser("postgres"),
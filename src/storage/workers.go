@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const workersKey = "generatorWorkers"
+
+// Worker is a generator process sharing the sensors with the others.
+type Worker struct {
+	Id        string
+	Heartbeat time.Time
+}
+
+// RegisterWorker announces the worker is alive, it has to be repeated more often than the TTL
+// passed to GetWorkers.
+func (s *Storage) RegisterWorker(ctx context.Context, id string) error {
+	return s.redis.ZAdd(ctx, workersKey, redis.Z{Score: float64(time.Now().UnixMilli()), Member: id}).Err()
+}
+
+func (s *Storage) RemoveWorker(ctx context.Context, id string) error {
+	return s.redis.ZRem(ctx, workersKey, id).Err()
+}
+
+// GetWorkers returns the workers ordered by id which announced themselves within the TTL,
+// the others are considered dead and are removed.
+func (s *Storage) GetWorkers(ctx context.Context, ttl time.Duration) ([]*Worker, error) {
+	expired := strconv.FormatInt(time.Now().Add(-ttl).UnixMilli(), 10)
+	if err := s.redis.ZRemRangeByScore(ctx, workersKey, "-inf", "("+expired).Err(); err != nil {
+		return nil, err
+	}
+
+	members, err := s.redis.ZRangeWithScores(ctx, workersKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	workers := make([]*Worker, 0, len(members))
+	for _, member := range members {
+		id, ok := member.Member.(string)
+		if !ok {
+			continue
+		}
+
+		workers = append(workers, &Worker{Id: id, Heartbeat: time.UnixMilli(int64(member.Score))})
+	}
+
+	sort.Slice(workers, func(i, j int) bool {
+		return workers[i].Id < workers[j].Id
+	})
+
+	return workers, nil
+}